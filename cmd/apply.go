@@ -54,7 +54,7 @@ var applyCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		_, err = patcher.CloneAndPublishAll(pkg.Repos, tmplCtx)
+		_, err = patcher.CloneAndPublishAll(pkg, tmplCtx)
 		return err
 	},
 }
@@ -26,7 +26,6 @@ import (
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/util"
-	"github.com/mitchellh/mapstructure"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -113,11 +112,7 @@ func configSetup() error {
 		return err
 	}
 
-	if err := viper.Unmarshal(&cfg, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
-		mapstructure.TextUnmarshallerHookFunc(),
-		mapstructure.StringToTimeDurationHookFunc(), // default hook
-		mapstructure.StringToSliceHookFunc(","),     // default hook
-	))); err != nil {
+	if err := viper.Unmarshal(&cfg, viper.DecodeHook(config.DecodeHook())); err != nil {
 		log.Error().Msgf("Failed decoding config file:\n%s", err)
 		os.Exit(1)
 	}
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/RiskIdent/jelease/pkg/jira"
 	"github.com/RiskIdent/jelease/pkg/patch"
@@ -73,7 +75,10 @@ func run() error {
 		return err
 	}
 
-	s := server.New(&cfg, jiraClient, patcher, htmlStaticFiles)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := server.New(ctx, &cfg, jiraClient, patcher, htmlStaticFiles)
 	return s.Serve()
 }
 
@@ -82,7 +87,7 @@ func newTestedPatcher() (patch.Patcher, error) {
 	if err != nil {
 		return patch.Patcher{}, err
 	}
-	if err := patcher.TestGitHubConnection(context.TODO()); err != nil {
+	if err := patcher.TestConnection(context.TODO()); err != nil {
 		return patch.Patcher{}, err
 	}
 	return patcher, nil
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bitbucket
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi"
+	"github.com/rs/zerolog/log"
+)
+
+// Client is a Bitbucket Server and Data Center REST API client.
+// It implements [forge.Client], so that Bitbucket pull requests can be
+// used in place of GitHub pull requests.
+type Client struct {
+	rest   forgeapi.Client
+	tokens []config.BitbucketToken
+}
+
+// tokenAuth is how Bitbucket expects HTTP access tokens.
+var tokenAuth = forgeapi.TokenAuth{Header: "Authorization", Prefix: "Bearer "}
+
+func _() {
+	// Ensure the type implements the interfaces
+	var _ forge.Client = &Client{}
+}
+
+// New creates a Bitbucket client using the HTTP access tokens from the config.
+func New(cfg *config.Bitbucket) (*Client, error) {
+	if cfg.URL == nil || *cfg.URL == "" {
+		return nil, errors.New("missing Bitbucket URL config")
+	}
+	if _, err := url.Parse(*cfg.URL); err != nil {
+		return nil, fmt.Errorf("parse Bitbucket URL: %w", err)
+	}
+	return &Client{
+		rest: forgeapi.Client{
+			BaseURL: strings.TrimRight(*cfg.URL, "/") + "/rest",
+			Auth:    tokenAuth,
+			HTTP:    http.DefaultClient,
+		},
+		tokens: cfg.Tokens,
+	}, nil
+}
+
+// ParseRepoRef parses the repository from a remote URL. See [ParseRepoRef].
+func (c *Client) ParseRepoRef(remote string) (forge.RepoRef, error) {
+	return ParseRepoRef(remote)
+}
+
+// TestConnection gets the project or repository of each token.
+func (c *Client) TestConnection(ctx context.Context) error {
+	if len(c.tokens) == 0 {
+		return errors.New("no Bitbucket tokens configured")
+	}
+	for _, t := range c.tokens {
+		path := "/api/1.0/projects/" + url.PathEscape(t.Path)
+		if key, slug, ok := strings.Cut(strings.Trim(t.Path, "/"), "/"); ok {
+			path = "/api/1.0" + repoPath(forge.RepoRef{Owner: key, Repo: slug})
+		}
+		if _, err := c.rest.Do(ctx, t.Token, http.MethodGet, path, nil, nil, nil); err != nil {
+			return fmt.Errorf("get Bitbucket %q using its token: %w", t.Path, err)
+		}
+		log.Debug().
+			Str("path", t.Path).
+			Msg("Authenticated with Bitbucket token.")
+	}
+	return nil
+}
+
+// GitCredentialsForRepo returns the repository's HTTP access token,
+// which Bitbucket accepts as the password over HTTPS.
+func (c *Client) GitCredentialsForRepo(_ context.Context, repo forge.RepoRef) (git.Credentials, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return git.Credentials{}, err
+	}
+	return git.Credentials{
+		Username: cmp.Or(token.Username, "x-token-auth"),
+		Password: token.Token,
+	}, nil
+}
+
+// tokenForRepo returns the token with the longest path that contains the
+// repository, so a repository token is preferred over a project token.
+func (c *Client) tokenForRepo(repo forge.RepoRef) (config.BitbucketToken, error) {
+	token, ok := forgeapi.LongestPathMatch(c.tokens, repo.Owner+"/"+repo.Repo, func(t config.BitbucketToken) string {
+		return t.Path
+	})
+	if !ok {
+		return config.BitbucketToken{}, fmt.Errorf("no Bitbucket token configured for repository: %s/%s", repo.Owner, repo.Repo)
+	}
+	return token, nil
+}
+
+// repoPath returns the path of the repository in the REST APIs,
+// without the API name and version prefix.
+func repoPath(repo forge.RepoRef) string {
+	return "/projects/" + url.PathEscape(repo.Owner) + "/repos/" + url.PathEscape(repo.Repo)
+}
+
+// doRepo sends a request to the repository in one of the REST APIs,
+// e.g "api/1.0", using the repository's token.
+func (c *Client) doRepo(ctx context.Context, repo forge.RepoRef, api, method, path string, query url.Values, body, result any) (*http.Response, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	path = "/" + api + repoPath(repo) + path
+	return c.rest.Do(ctx, token.Token, method, path, query, body, result)
+}
+
+// page is a page of results from the paged Bitbucket APIs.
+type page[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// getAllRepo gets all pages from a paged API of the repository.
+func getAllRepo[T any](ctx context.Context, c *Client, repo forge.RepoRef, api, path string, query url.Values) ([]T, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	return getAll[T](ctx, c, token.Token, "/"+api+repoPath(repo)+path, query)
+}
+
+func getAll[T any](ctx context.Context, c *Client, token, path string, query url.Values) ([]T, error) {
+	query.Set("limit", "100")
+	var result []T
+	for {
+		var p page[T]
+		if _, err := c.rest.Do(ctx, token, http.MethodGet, path, query, nil, &p); err != nil {
+			return nil, err
+		}
+		result = append(result, p.Values...)
+		if p.IsLastPage || len(p.Values) == 0 {
+			return result, nil
+		}
+		query.Set("start", strconv.Itoa(p.NextPageStart))
+	}
+}
@@ -0,0 +1,320 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bitbucket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+)
+
+var testRepo = forge.RepoRef{
+	URL:   "https://bitbucket.example.com/projects/PROJ/repos/my-repo",
+	Owner: "PROJ",
+	Repo:  "my-repo",
+}
+
+// newTestClient creates a Bitbucket client towards a fake Bitbucket API,
+// where the handler is registered using the given [http.ServeMux].
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+	server := forgeapitest.NewServer(t, mux)
+	return &Client{
+		rest: forgeapi.Client{
+			BaseURL: server.URL + "/rest",
+			Auth:    tokenAuth,
+			HTTP:    server.Client(),
+		},
+		tokens: []config.BitbucketToken{
+			{Path: "PROJ", Token: "project-token"},
+			{Path: "PROJ/my-repo", Token: "repo-token"},
+		},
+	}
+}
+
+// handleRepo registers a handler for a repository endpoint in one of the
+// REST APIs, which checks that the repository's token is used.
+func handleRepo(t *testing.T, mux *http.ServeMux, method, api, path string, handler http.HandlerFunc) {
+	mux.HandleFunc(method+" /rest/"+api+"/projects/PROJ/repos/my-repo"+path, forgeapitest.RequireHeader(t, "Authorization", "Bearer repo-token", handler))
+}
+
+func TestTokenForRepo(t *testing.T) {
+	c := &Client{tokens: []config.BitbucketToken{
+		{Path: "PROJ", Token: "project-token"},
+		{Path: "PROJ/my-repo", Username: "jelease-bot", Token: "repo-token"},
+	}}
+	tests := []struct {
+		name         string
+		owner        string
+		repo         string
+		wantUsername string
+		wantPassword string
+		wantErr      bool
+	}{
+		{name: "repository token", owner: "PROJ", repo: "my-repo", wantUsername: "jelease-bot", wantPassword: "repo-token"},
+		{name: "project token", owner: "PROJ", repo: "other-repo", wantUsername: "x-token-auth", wantPassword: "project-token"},
+		{name: "project with same prefix", owner: "PROJ2", repo: "my-repo", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := c.GitCredentialsForRepo(context.Background(), forge.RepoRef{Owner: tc.owner, Repo: tc.repo})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error: %t, got: %v", tc.wantErr, err)
+			}
+			if got.Username != tc.wantUsername || got.Password != tc.wantPassword {
+				t.Errorf("want %q / %q, got %q / %q", tc.wantUsername, tc.wantPassword, got.Username, got.Password)
+			}
+		})
+	}
+}
+
+func TestTestConnection(t *testing.T) {
+	var gotPaths []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/api/1.0/projects/", func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path+" "+r.Header.Get("Authorization"))
+		forgeapitest.WriteJSON(t, w, map[string]any{})
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.TestConnection(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/rest/api/1.0/projects/PROJ Bearer project-token",
+		"/rest/api/1.0/projects/PROJ/repos/my-repo Bearer repo-token",
+	}
+	if !slices.Equal(gotPaths, want) {
+		t.Errorf("want requests %q, got %q", want, gotPaths)
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var got pullRequest
+	mux := http.NewServeMux()
+	handleRepo(t, mux, "GET", "api/1.0", "", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, repository{ID: 42, Slug: "my-repo", Project: project{Key: "PROJ"}})
+	})
+	handleRepo(t, mux, "GET", "default-reviewers/1.0", "/reviewers", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("sourceRepoId") != "42" || query.Get("targetRepoId") != "42" ||
+			query.Get("sourceRefId") != "refs/heads/jelease/pkg-v2.0.0" || query.Get("targetRefId") != "refs/heads/main" {
+			t.Errorf("wrong default reviewers query: %q", r.URL.RawQuery)
+		}
+		forgeapitest.WriteJSON(t, w, []user{{Name: "alice"}, {Name: "bob"}})
+	})
+	handleRepo(t, mux, "POST", "api/1.0", "/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		created := got
+		created.ID = 3
+		created.FromRef.DisplayID = "jelease/pkg-v2.0.0"
+		created.FromRef.LatestCommit = "abc123"
+		created.ToRef.DisplayID = "main"
+		created.Links.Self = []link{{Href: "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/3"}}
+		forgeapitest.WriteJSON(t, w, created)
+	})
+	c := newTestClient(t, mux)
+
+	pr, err := c.CreatePullRequest(context.Background(), forge.NewPullRequest{
+		RepoRef:     testRepo,
+		Title:       "Update pkg to v2.0.0",
+		Description: "Description",
+		Head:        "jelease/pkg-v2.0.0",
+		Base:        "main",
+		Reviewers:   []string{"Bob", "carol"},
+		Draft:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.FromRef.ID != "refs/heads/jelease/pkg-v2.0.0" || got.ToRef.ID != "refs/heads/main" {
+		t.Errorf("wrong refs: from %q to %q", got.FromRef.ID, got.ToRef.ID)
+	}
+	if got.ToRef.Repository.Slug != "my-repo" || got.ToRef.Repository.Project.Key != "PROJ" {
+		t.Errorf("wrong target repository: %+v", got.ToRef.Repository)
+	}
+	if !got.Draft || got.Title != "Update pkg to v2.0.0" {
+		t.Errorf("wrong title or draft: %q, %t", got.Title, got.Draft)
+	}
+	wantReviewers := []string{"alice", "bob", "carol"}
+	if !slices.Equal(usernames(got.Reviewers), wantReviewers) {
+		t.Errorf("want reviewers %q, got %q", wantReviewers, usernames(got.Reviewers))
+	}
+	if pr.Number != 3 || pr.HeadSHA != "abc123" || pr.Base != "main" || pr.Head != "PROJ:jelease/pkg-v2.0.0" {
+		t.Errorf("wrong pull request: %+v", pr)
+	}
+	if pr.URL != "https://bitbucket.example.com/projects/PROJ/repos/my-repo/pull-requests/3" {
+		t.Errorf("wrong pull request URL: %q", pr.URL)
+	}
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	var got pullRequest
+	mux := http.NewServeMux()
+	handleRepo(t, mux, "GET", "api/1.0", "/pull-requests/3", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, pullRequest{
+			ID:        3,
+			Version:   5,
+			Title:     "Old title",
+			FromRef:   newRef(testRepo, "jelease/pkg-v2.0.0"),
+			ToRef:     newRef(testRepo, "main"),
+			Reviewers: []participant{{User: user{Name: "alice"}}},
+		})
+	})
+	handleRepo(t, mux, "PUT", "api/1.0", "/pull-requests/3", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		forgeapitest.WriteJSON(t, w, got)
+	})
+	c := newTestClient(t, mux)
+
+	pr, err := c.UpdatePullRequest(context.Background(), 3, forge.NewPullRequest{
+		RepoRef:   testRepo,
+		Title:     "New title",
+		Reviewers: []string{"bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 5 || got.Title != "New title" {
+		t.Errorf("want version 5 and new title, got %d and %q", got.Version, got.Title)
+	}
+	if want := []string{"alice", "bob"}; !slices.Equal(usernames(got.Reviewers), want) {
+		t.Errorf("want reviewers %q, got %q", want, usernames(got.Reviewers))
+	}
+	if !pr.Updated {
+		t.Error("want pull request to be marked as updated")
+	}
+}
+
+func TestFindPullRequest(t *testing.T) {
+	fork := forge.RepoRef{Owner: "~ALICE", Repo: "my-repo"}
+	mux := http.NewServeMux()
+	handleRepo(t, mux, "GET", "api/1.0", "/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != "OPEN" || query.Get("direction") != "OUTGOING" || query.Get("at") != "refs/heads/jelease/pkg-v2.0.0" {
+			t.Errorf("wrong query: %q", r.URL.RawQuery)
+		}
+		if query.Get("start") == "" {
+			forgeapitest.WriteJSON(t, w, page[pullRequest]{
+				Values: []pullRequest{
+					{ID: 1, FromRef: newRef(testRepo, "jelease/pkg-v2.0.0"), ToRef: newRef(testRepo, "release/1.x")},
+					{ID: 2, FromRef: newRef(fork, "jelease/pkg-v2.0.0"), ToRef: newRef(testRepo, "main")},
+				},
+				NextPageStart: 2,
+			})
+			return
+		}
+		forgeapitest.WriteJSON(t, w, page[pullRequest]{
+			Values:     []pullRequest{{ID: 3, FromRef: newRef(testRepo, "jelease/pkg-v2.0.0"), ToRef: newRef(testRepo, "main")}},
+			IsLastPage: true,
+		})
+	})
+	c := newTestClient(t, mux)
+
+	pr, err := c.FindPullRequest(context.Background(), testRepo, "jelease/pkg-v2.0.0", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 3 {
+		t.Errorf("want PR #3, got #%d", pr.Number)
+	}
+
+	_, err = c.FindPullRequest(context.Background(), testRepo, "jelease/pkg-v2.0.0", "develop")
+	if !errors.Is(err, forge.ErrPullRequestNotFound) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestClosePullRequest(t *testing.T) {
+	var gotComment map[string]string
+	var gotVersion string
+	mux := http.NewServeMux()
+	handleRepo(t, mux, "POST", "api/1.0", "/pull-requests/3/comments", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotComment)
+		forgeapitest.WriteJSON(t, w, map[string]any{"id": 1})
+	})
+	handleRepo(t, mux, "GET", "api/1.0", "/pull-requests/3", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, pullRequest{ID: 3, Version: 7})
+	})
+	handleRepo(t, mux, "POST", "api/1.0", "/pull-requests/3/decline", func(w http.ResponseWriter, r *http.Request) {
+		gotVersion = r.URL.Query().Get("version")
+		forgeapitest.WriteJSON(t, w, pullRequest{ID: 3, Version: 8, State: "DECLINED"})
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.ClosePullRequest(context.Background(), testRepo, 3, "Superseded by #4"); err != nil {
+		t.Fatal(err)
+	}
+	if gotComment["text"] != "Superseded by #4" {
+		t.Errorf("want comment %q, got %q", "Superseded by #4", gotComment["text"])
+	}
+	if gotVersion != "7" {
+		t.Errorf("want declined version %q, got %q", "7", gotVersion)
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	var got map[string]any
+	mux := http.NewServeMux()
+	handleRepo(t, mux, "DELETE", "branch-utils/1.0", "/branches", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.DeleteBranch(context.Background(), testRepo, "jelease/pkg-v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got["name"] != "refs/heads/jelease/pkg-v1.0.0" || got["dryRun"] != false {
+		t.Errorf("wrong delete branch body: %v", got)
+	}
+}
+
+func TestGetCommitChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rest/build-status/1.0/commits/abc123", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, page[buildStatus]{
+			Values: []buildStatus{
+				{Key: "build", State: "SUCCESSFUL"},
+				{Key: "test", Name: "Unit tests", State: "FAILED", URL: "https://ci.example.com/test/1"},
+			},
+			IsLastPage: true,
+		})
+	})
+	c := newTestClient(t, mux)
+
+	checks, err := c.GetCommitChecks(context.Background(), testRepo, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checks.State != forge.CheckStateFailure {
+		t.Errorf("want state %q, got %q", forge.CheckStateFailure, checks.State)
+	}
+	wantFailed := []forge.Check{{Name: "Unit tests", URL: "https://ci.example.com/test/1", State: forge.CheckStateFailure}}
+	if !slices.Equal(checks.Failed(), wantFailed) {
+		t.Errorf("want failed checks %v, got %v", wantFailed, checks.Failed())
+	}
+}
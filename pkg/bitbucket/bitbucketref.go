@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bitbucket
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+// ParseRepoRef parses the project key and repository slug from a remote URL.
+// The [forge.RepoRef] Owner is the project key, e.g "PROJ", or "~USER" for
+// personal repositories, and Repo is the repository slug.
+//
+// Supported URLs are the HTTPS clone URL, e.g "https://host/scm/PROJ/repo.git",
+// links to the repository, e.g "https://host/projects/PROJ/repos/repo/browse",
+// and SSH remotes, e.g "ssh://git@host:7999/PROJ/repo.git".
+// Any context path before "/scm" or "/projects" is kept in the URL.
+func ParseRepoRef(remote string) (forge.RepoRef, error) {
+	isSSH := git.IsSSHRemote(remote)
+	if isSSH {
+		httpsRemote, err := git.SSHRemoteToHTTPS(remote)
+		if err != nil {
+			return forge.RepoRef{}, err
+		}
+		remote = httpsRemote
+	}
+	u, err := url.Parse(remote)
+	if err != nil {
+		return forge.RepoRef{}, err
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	contextPath, owner, repo, ok := parsePath(segments)
+	if !ok && isSSH && len(segments) == 2 {
+		// SSH remotes have no "/scm" prefix
+		owner, repo, ok = segments[0], segments[1], true
+	}
+	repo = strings.TrimSuffix(repo, ".git")
+	if !ok || owner == "" || repo == "" {
+		return forge.RepoRef{}, fmt.Errorf("expected https://host/scm/PROJECT/REPO or https://host/projects/PROJECT/repos/REPO in URL, got: %s", remote)
+	}
+	// Project keys are case-insensitive in URLs, but are uppercase in the API
+	if !strings.HasPrefix(owner, "~") {
+		owner = strings.ToUpper(owner)
+	}
+	return forge.RepoRef{
+		URL:   (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: webPath(contextPath, owner, repo)}).String(),
+		Owner: owner,
+		Repo:  repo,
+	}, nil
+}
+
+// parsePath finds the project and repository in the path segments of
+// either the clone URL or of a link to the repository.
+func parsePath(segments []string) (contextPath []string, owner, repo string, ok bool) {
+	for i, seg := range segments {
+		rest := segments[i+1:]
+		switch {
+		case seg == "scm" && len(rest) == 2:
+			return segments[:i], rest[0], rest[1], true
+		case seg == "projects" && len(rest) >= 3 && rest[1] == "repos":
+			return segments[:i], rest[0], rest[2], true
+		case seg == "users" && len(rest) >= 3 && rest[1] == "repos":
+			return segments[:i], "~" + rest[0], rest[2], true
+		}
+	}
+	return nil, "", "", false
+}
+
+// webPath returns the path of the repository's page in the Bitbucket UI.
+func webPath(contextPath []string, owner, repo string) string {
+	var path string
+	if user, ok := strings.CutPrefix(owner, "~"); ok {
+		path = "/users/" + strings.ToLower(user) + "/repos/" + repo
+	} else {
+		path = "/projects/" + owner + "/repos/" + repo
+	}
+	if len(contextPath) > 0 {
+		path = "/" + strings.Join(contextPath, "/") + path
+	}
+	return path
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bitbucket
+
+import (
+	"testing"
+)
+
+func TestParseRepoRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		remote    string
+		wantURL   string
+		wantOwner string
+		wantRepo  string
+	}{
+		{
+			name:      "clone URL",
+			remote:    "https://bitbucket.example.com/scm/proj/my-repo.git",
+			wantURL:   "https://bitbucket.example.com/projects/PROJ/repos/my-repo",
+			wantOwner: "PROJ",
+			wantRepo:  "my-repo",
+		},
+		{
+			name:      "link to repository",
+			remote:    "https://bitbucket.example.com/projects/PROJ/repos/my-repo/browse/README.md",
+			wantURL:   "https://bitbucket.example.com/projects/PROJ/repos/my-repo",
+			wantOwner: "PROJ",
+			wantRepo:  "my-repo",
+		},
+		{
+			name:      "context path",
+			remote:    "https://example.com/bitbucket/scm/PROJ/my-repo.git",
+			wantURL:   "https://example.com/bitbucket/projects/PROJ/repos/my-repo",
+			wantOwner: "PROJ",
+			wantRepo:  "my-repo",
+		},
+		{
+			name:      "personal repository",
+			remote:    "https://bitbucket.example.com/users/alice/repos/my-repo",
+			wantURL:   "https://bitbucket.example.com/users/alice/repos/my-repo",
+			wantOwner: "~alice",
+			wantRepo:  "my-repo",
+		},
+		{
+			name:      "personal repository clone URL",
+			remote:    "https://bitbucket.example.com/scm/~alice/my-repo.git",
+			wantURL:   "https://bitbucket.example.com/users/alice/repos/my-repo",
+			wantOwner: "~alice",
+			wantRepo:  "my-repo",
+		},
+		{
+			name:      "ssh",
+			remote:    "ssh://git@bitbucket.example.com:7999/proj/my-repo.git",
+			wantURL:   "https://bitbucket.example.com/projects/PROJ/repos/my-repo",
+			wantOwner: "PROJ",
+			wantRepo:  "my-repo",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseRepoRef(tc.remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Owner != tc.wantOwner {
+				t.Errorf("want owner %q, got owner %q", tc.wantOwner, got.Owner)
+			}
+			if got.Repo != tc.wantRepo {
+				t.Errorf("want repo %q, got repo %q", tc.wantRepo, got.Repo)
+			}
+			if got.URL != tc.wantURL {
+				t.Errorf("want URL %q, got URL %q", tc.wantURL, got.URL)
+			}
+		})
+	}
+}
+
+func TestParseRepoRef_invalid(t *testing.T) {
+	remotes := []string{
+		"https://bitbucket.example.com/PROJ/my-repo",
+		"https://bitbucket.example.com/projects/PROJ",
+		"https://bitbucket.example.com/scm/PROJ",
+	}
+	for _, remote := range remotes {
+		if _, err := ParseRepoRef(remote); err == nil {
+			t.Errorf("want error for %q, got nil", remote)
+		}
+	}
+}
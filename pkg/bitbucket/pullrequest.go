@@ -0,0 +1,377 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package bitbucket
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/rs/zerolog/log"
+)
+
+type pullRequest struct {
+	ID          int           `json:"id,omitempty"`
+	Version     int           `json:"version"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	State       string        `json:"state,omitempty"`
+	Draft       bool          `json:"draft,omitempty"`
+	FromRef     ref           `json:"fromRef"`
+	ToRef       ref           `json:"toRef"`
+	Reviewers   []participant `json:"reviewers"`
+	Links       links         `json:"links,omitzero"`
+}
+
+type ref struct {
+	ID           string     `json:"id"`
+	DisplayID    string     `json:"displayId,omitempty"`
+	LatestCommit string     `json:"latestCommit,omitempty"`
+	Repository   repository `json:"repository"`
+}
+
+type repository struct {
+	ID      int64   `json:"id,omitempty"`
+	Slug    string  `json:"slug"`
+	Project project `json:"project"`
+}
+
+type project struct {
+	Key string `json:"key"`
+}
+
+type participant struct {
+	User user `json:"user"`
+}
+
+type user struct {
+	Name string `json:"name"`
+}
+
+type links struct {
+	Self []link `json:"self,omitempty"`
+}
+
+type link struct {
+	Href string `json:"href"`
+}
+
+func newRef(repo forge.RepoRef, branch string) ref {
+	return ref{
+		ID: "refs/heads/" + branch,
+		Repository: repository{
+			Slug:    repo.Repo,
+			Project: project{Key: repo.Owner},
+		},
+	}
+}
+
+func (r ref) isRepo(repo forge.RepoRef) bool {
+	return strings.EqualFold(r.Repository.Project.Key, repo.Owner) &&
+		strings.EqualFold(r.Repository.Slug, repo.Repo)
+}
+
+func (r ref) branch() string {
+	return cmp.Or(r.DisplayID, strings.TrimPrefix(r.ID, "refs/heads/"))
+}
+
+func newPullRequest(repo forge.RepoRef, pr pullRequest, commit git.Commit) forge.PullRequest {
+	// Same format as the GitHub head label, "owner:branch", which is used
+	// to tell pull requests from forks apart
+	head := repo.Owner + ":" + pr.FromRef.branch()
+	if !pr.FromRef.isRepo(repo) {
+		head = pr.FromRef.Repository.Project.Key + "/" + pr.FromRef.Repository.Slug + ":" + pr.FromRef.branch()
+	}
+	var prURL string
+	if len(pr.Links.Self) > 0 {
+		prURL = pr.Links.Self[0].Href
+	}
+	return forge.PullRequest{
+		RepoRef:     repo,
+		ID:          int64(pr.ID),
+		Number:      pr.ID,
+		URL:         prURL,
+		Title:       pr.Title,
+		Description: pr.Description,
+		Head:        head,
+		HeadBranch:  pr.FromRef.branch(),
+		HeadSHA:     pr.FromRef.LatestCommit,
+		Base:        pr.ToRef.branch(),
+		Reviewers:   usernames(pr.Reviewers),
+		Draft:       pr.Draft,
+		Commit:      commit,
+	}
+}
+
+func usernames(participants []participant) []string {
+	var names []string
+	for _, p := range participants {
+		names = append(names, p.User.Name)
+	}
+	return names
+}
+
+// CreatePullRequest creates a pull request with the configured reviewers
+// and the repository's default reviewers. Bitbucket has no labels,
+// assignees, or milestones on pull requests, so those are ignored.
+func (c *Client) CreatePullRequest(ctx context.Context, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	warnUnsupportedMetadata(pr)
+	defaultReviewers, err := c.findDefaultReviewers(ctx, pr.RepoRef, pr.Head, pr.Base)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	body := pullRequest{
+		Title:       pr.Title,
+		Description: pr.Description,
+		State:       "OPEN",
+		Draft:       pr.Draft,
+		FromRef:     newRef(pr.RepoRef, pr.Head),
+		ToRef:       newRef(pr.RepoRef, pr.Base),
+		Reviewers:   participants(append(defaultReviewers, pr.Reviewers...)),
+	}
+	var created pullRequest
+	if _, err := c.doRepo(ctx, pr.RepoRef, "api/1.0", http.MethodPost, "/pull-requests", nil, body, &created); err != nil {
+		return forge.PullRequest{}, err
+	}
+	return newPullRequest(pr.RepoRef, created, pr.Commit), nil
+}
+
+// UpdatePullRequest sets the title and description of an existing pull
+// request, and adds the configured reviewers to the existing ones.
+func (c *Client) UpdatePullRequest(ctx context.Context, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	warnUnsupportedMetadata(pr)
+	existing, err := c.getPullRequest(ctx, pr.RepoRef, number)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	body := pullRequest{
+		Version:     existing.Version,
+		Title:       pr.Title,
+		Description: pr.Description,
+		FromRef:     existing.FromRef,
+		ToRef:       existing.ToRef,
+		Reviewers:   participants(append(usernames(existing.Reviewers), pr.Reviewers...)),
+	}
+	var updated pullRequest
+	path := fmt.Sprintf("/pull-requests/%d", number)
+	if _, err := c.doRepo(ctx, pr.RepoRef, "api/1.0", http.MethodPut, path, nil, body, &updated); err != nil {
+		return forge.PullRequest{}, err
+	}
+	result := newPullRequest(pr.RepoRef, updated, pr.Commit)
+	result.Updated = true
+	return result, nil
+}
+
+func warnUnsupportedMetadata(pr forge.NewPullRequest) {
+	if len(pr.Labels) > 0 || len(pr.Assignees) > 0 || len(pr.TeamReviewers) > 0 || pr.Milestone != "" {
+		log.Warn().
+			Strs("labels", pr.Labels).
+			Strs("assignees", pr.Assignees).
+			Strs("teamReviewers", pr.TeamReviewers).
+			Str("milestone", pr.Milestone).
+			Msg("Bitbucket does not support labels, assignees, team reviewers, or milestones on pull requests. Ignoring them.")
+	}
+}
+
+// participants returns the reviewers to send to the API, without duplicates.
+func participants(names []string) []participant {
+	var result []participant
+	seen := map[string]bool{}
+	for _, name := range names {
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		result = append(result, participant{User: user{Name: name}})
+	}
+	return result
+}
+
+// findDefaultReviewers returns the usernames of the default reviewers
+// that the repository's settings add for pull requests from the head
+// branch into the base branch. The REST API doesn't add them by itself.
+func (c *Client) findDefaultReviewers(ctx context.Context, repo forge.RepoRef, head, base string) ([]string, error) {
+	var r repository
+	if _, err := c.doRepo(ctx, repo, "api/1.0", http.MethodGet, "", nil, nil, &r); err != nil {
+		return nil, fmt.Errorf("get repository: %w", err)
+	}
+	repoID := strconv.FormatInt(r.ID, 10)
+	query := url.Values{
+		"sourceRepoId": {repoID},
+		"targetRepoId": {repoID},
+		"sourceRefId":  {"refs/heads/" + head},
+		"targetRefId":  {"refs/heads/" + base},
+	}
+	var users []user
+	if _, err := c.doRepo(ctx, repo, "default-reviewers/1.0", http.MethodGet, "/reviewers", query, nil, &users); err != nil {
+		return nil, fmt.Errorf("find default reviewers: %w", err)
+	}
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	return names, nil
+}
+
+func (c *Client) getPullRequest(ctx context.Context, repo forge.RepoRef, number int) (pullRequest, error) {
+	var pr pullRequest
+	path := fmt.Sprintf("/pull-requests/%d", number)
+	if _, err := c.doRepo(ctx, repo, "api/1.0", http.MethodGet, path, nil, nil, &pr); err != nil {
+		return pullRequest{}, err
+	}
+	return pr, nil
+}
+
+// FindPullRequest returns the open pull request from the head branch into
+// the base branch, or [forge.ErrPullRequestNotFound]. Pull requests
+// from forks are ignored.
+func (c *Client) FindPullRequest(ctx context.Context, repo forge.RepoRef, head, base string) (forge.PullRequest, error) {
+	query := url.Values{
+		"state":     {"OPEN"},
+		"direction": {"OUTGOING"},
+		"at":        {"refs/heads/" + head},
+	}
+	prs, err := getAllRepo[pullRequest](ctx, c, repo, "api/1.0", "/pull-requests", query)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	for _, pr := range prs {
+		if pr.FromRef.isRepo(repo) && pr.ToRef.branch() == base {
+			return newPullRequest(repo, pr, git.Commit{}), nil
+		}
+	}
+	return forge.PullRequest{}, fmt.Errorf("%w: %s:%s", forge.ErrPullRequestNotFound, repo.Owner, head)
+}
+
+func (c *Client) ListPullRequests(ctx context.Context, repo forge.RepoRef, base string) ([]forge.PullRequest, error) {
+	query := url.Values{
+		"state":     {"OPEN"},
+		"direction": {"INCOMING"},
+		"at":        {"refs/heads/" + base},
+	}
+	prs, err := getAllRepo[pullRequest](ctx, c, repo, "api/1.0", "/pull-requests", query)
+	if err != nil {
+		return nil, err
+	}
+	var result []forge.PullRequest
+	for _, pr := range prs {
+		result = append(result, newPullRequest(repo, pr, git.Commit{}))
+	}
+	return result, nil
+}
+
+// ClosePullRequest declines the pull request, which is what Bitbucket
+// calls closing a pull request without merging it.
+func (c *Client) ClosePullRequest(ctx context.Context, repo forge.RepoRef, number int, comment string) error {
+	path := fmt.Sprintf("/pull-requests/%d", number)
+	if comment != "" {
+		body := map[string]string{"text": comment}
+		if _, err := c.doRepo(ctx, repo, "api/1.0", http.MethodPost, path+"/comments", nil, body, nil); err != nil {
+			return fmt.Errorf("comment on pull request: %w", err)
+		}
+	}
+	pr, err := c.getPullRequest(ctx, repo, number)
+	if err != nil {
+		return fmt.Errorf("get pull request version: %w", err)
+	}
+	// Older versions of Bitbucket take the version as a query parameter,
+	// and newer versions in the body
+	version := map[string]int{"version": pr.Version}
+	query := url.Values{"version": {strconv.Itoa(pr.Version)}}
+	if _, err := c.doRepo(ctx, repo, "api/1.0", http.MethodPost, path+"/decline", query, version, nil); err != nil {
+		return fmt.Errorf("decline pull request: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteBranch(ctx context.Context, repo forge.RepoRef, branch string) error {
+	body := map[string]any{
+		"name":   "refs/heads/" + branch,
+		"dryRun": false,
+	}
+	_, err := c.doRepo(ctx, repo, "branch-utils/1.0", http.MethodDelete, "/branches", nil, body, nil)
+	return err
+}
+
+// CreateCommit is not supported, so the "api" publish mode can't be used
+// with Bitbucket.
+func (c *Client) CreateCommit(context.Context, forge.NewCommit) (string, error) {
+	return "", fmt.Errorf("create commit via Bitbucket API: %w", errors.ErrUnsupported)
+}
+
+func (c *Client) CreateBranch(context.Context, forge.RepoRef, string, string) error {
+	return fmt.Errorf("create branch via Bitbucket API: %w", errors.ErrUnsupported)
+}
+
+func (c *Client) UpdateBranch(context.Context, forge.RepoRef, string, string) error {
+	return fmt.Errorf("update branch via Bitbucket API: %w", errors.ErrUnsupported)
+}
+
+// EnableAutoMerge is not supported, so auto-merge is skipped for Bitbucket
+// pull requests.
+func (c *Client) EnableAutoMerge(_ context.Context, pr forge.PullRequest, _ config.MergeMethod) error {
+	return fmt.Errorf("enable auto-merge on PR %s: %w", pr.URL, errors.ErrUnsupported)
+}
+
+type buildStatus struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+	URL   string `json:"url"`
+}
+
+// GetCommitChecks returns the build statuses of a commit.
+func (c *Client) GetCommitChecks(ctx context.Context, repo forge.RepoRef, ref string) (forge.CommitChecks, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return forge.CommitChecks{}, err
+	}
+	path := "/build-status/1.0/commits/" + url.PathEscape(ref)
+	statuses, err := getAll[buildStatus](ctx, c, token.Token, path, url.Values{})
+	if err != nil {
+		return forge.CommitChecks{}, err
+	}
+	var checks []forge.Check
+	for _, status := range statuses {
+		checks = append(checks, forge.Check{
+			Name:  cmp.Or(status.Name, status.Key),
+			URL:   status.URL,
+			State: buildStatusState(status.State),
+		})
+	}
+	return forge.NewCommitChecks(checks), nil
+}
+
+func buildStatusState(state string) forge.CheckState {
+	switch state {
+	case "SUCCESSFUL":
+		return forge.CheckStateSuccess
+	case "FAILED", "CANCELLED":
+		return forge.CheckStateFailure
+	default:
+		return forge.CheckStatePending
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// CommitSigningFormat is the type of key used to sign Git commits.
+type CommitSigningFormat string
+
+const (
+	// CommitSigningFormatNone does not sign commits.
+	CommitSigningFormatNone CommitSigningFormat = "none"
+	// CommitSigningFormatSSH signs commits using an SSH private key.
+	CommitSigningFormatSSH CommitSigningFormat = "ssh"
+	// CommitSigningFormatGPG signs commits using an armored GPG private key.
+	CommitSigningFormatGPG CommitSigningFormat = "gpg"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := CommitSigningFormatNone
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f CommitSigningFormat) String() string {
+	return string(f)
+}
+
+func (f *CommitSigningFormat) Set(value string) error {
+	switch CommitSigningFormat(value) {
+	case CommitSigningFormatNone:
+		*f = CommitSigningFormatNone
+	case CommitSigningFormatSSH:
+		*f = CommitSigningFormatSSH
+	case CommitSigningFormatGPG:
+		*f = CommitSigningFormatGPG
+	default:
+		return fmt.Errorf("unknown commit signing format: %q, must be one of: none, ssh, gpg", value)
+	}
+	return nil
+}
+
+func (f *CommitSigningFormat) Type() string {
+	return "format"
+}
+
+func (f *CommitSigningFormat) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (CommitSigningFormat) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Commit signing format",
+		Default: CommitSigningFormatNone,
+		Enum: []any{
+			CommitSigningFormatNone,
+			CommitSigningFormatSSH,
+			CommitSigningFormatGPG,
+		},
+	}
+}
@@ -18,8 +18,10 @@
 package config
 
 import (
+	"cmp"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/RiskIdent/jelease/pkg/util"
@@ -35,6 +37,8 @@ type Config struct {
 	DryRun      bool `yaml:"dryRun"`
 	Packages    []Package
 	GitHub      GitHub
+	GitLab      GitLab
+	Bitbucket   Bitbucket
 	Jira        Jira
 	NewReleases NewReleases
 	HTTP        HTTP
@@ -53,6 +57,8 @@ func (c Config) TryFindPackage(pkgName string) (Package, bool) {
 
 func (c Config) Censored() Config {
 	c.GitHub = c.GitHub.Censored()
+	c.GitLab = c.GitLab.Censored()
+	c.Bitbucket = c.Bitbucket.Censored()
 	c.Jira = c.Jira.Censored()
 	c.HTTP = c.HTTP.Censored()
 	return c
@@ -62,6 +68,19 @@ type Package struct {
 	Name        string
 	Description *Template
 	Repos       []PackageRepo
+	// AutoMerge enables GitHub auto-merge on the created pull requests,
+	// so they're merged once the required checks pass.
+	AutoMerge PackageAutoMerge `yaml:"autoMerge,omitempty"`
+}
+
+type PackageAutoMerge struct {
+	Enabled bool
+	// Method is the merge method used when merging the pull request.
+	Method MergeMethod `yaml:",omitempty"`
+	// MaxChange is the largest version change that is auto-merged,
+	// compared to the previous version found by the patches.
+	// Pull requests with larger changes are left to be merged manually.
+	MaxChange VersionChange `yaml:"maxChange,omitempty"`
 }
 
 func (p Package) NormalizedName() string {
@@ -73,28 +92,134 @@ func NormalizePackageName(pkgName string) string {
 }
 
 type PackageRepo struct {
-	URL     string `jsonschema_extras:"format=uri-reference"`
-	Patches []PackageRepoPatch
+	// URL is the HTTPS or SSH remote of the repository.
+	// Repositories on the configured GitLab or Bitbucket host use GitLab or
+	// Bitbucket, and all others use GitHub.
+	URL string
+	// SSHKeyPath is the SSH private key, such as a deploy key, used for this
+	// repository if it's an SSH remote. Overrides the global SSH key.
+	SSHKeyPath *string `yaml:"sshKeyPath,omitempty" jsonschema:"oneof_type=string;null"`
+	Patches    []PackageRepoPatch
+	// When is a condition that must render "true" for the repo to be patched.
+	When *Template `yaml:",omitempty"`
+	// Branches are the base branches to create pull requests against,
+	// each getting its own clone, patches, and pull request.
+	// Uses the repository's default branch if empty.
+	Branches []PackageRepoBranch `yaml:",omitempty"`
+	// SparseCheckout only fetches and checks out the directories of the
+	// files that the patches touch, plus the SparsePaths.
+	SparseCheckout bool `yaml:"sparseCheckout,omitempty"`
+	// SparsePaths are additional directories to check out when using sparse
+	// checkout. Setting this also enables SparseCheckout.
+	SparsePaths []string `yaml:"sparsePaths,omitempty"`
+	// PR is added to the global [GitHubPRMetadata] config for the
+	// pull requests of this repository.
+	PR GitHubPRMetadata `yaml:"pr,omitempty"`
+}
+
+type PackageRepoBranch struct {
+	// Name is the name of the base branch, e.g "release/1.x".
+	Name *Template
+	// When is a condition that must render "true" for the branch to be
+	// targeted.
+	When *Template `yaml:",omitempty"`
 }
 
 type PackageRepoPatch struct {
 	Regex         *PatchRegex         `yaml:",omitempty" json:",omitempty" jsonschema:"oneof_required=regex"`
 	YAML          *PatchYAML          `yaml:",omitempty" json:",omitempty" jsonschema:"oneof_required=yaml"`
 	HelmDepUpdate *PatchHelmDepUpdate `yaml:"helmDepUpdate,omitempty" json:",omitempty" jsonschema:"oneof_required=helmDepUpdate"`
+	HCL           *PatchHCL           `yaml:"hcl,omitempty" json:",omitempty" jsonschema:"oneof_required=hcl"`
+	Changelog     *PatchChangelog     `yaml:",omitempty" json:",omitempty" jsonschema:"oneof_required=changelog"`
+	File          *PatchFile          `yaml:",omitempty" json:",omitempty" jsonschema:"oneof_required=file"`
+
+	// When is a condition that must render "true" for the patch to be applied.
+	When *Template `yaml:",omitempty"`
+
+	// Custom holds the config of patch types that are not built-in, keyed
+	// by the patch type name, such as the ones added by Go library users
+	// via the patches package's registry.
+	Custom map[string]any `yaml:",inline" mapstructure:",remain" json:",omitempty" jsonschema:"-"`
 }
 
 type PatchRegex struct {
 	File    string        `jsonschema:"required"`
 	Match   *RegexPattern `jsonschema:"required"`
 	Replace *Template     `jsonschema:"required"`
+	// VersionGroup is the name or number of the regex group that contains
+	// the version currently in the file. It's available as .PreviousVersion
+	// in the Replace template and is checked by VersionPolicy.
+	// Defaults to the group named "version", if the regex has one.
+	VersionGroup string `yaml:"versionGroup,omitempty" jsonschema:"example=version,example=1"`
+	// VersionPolicy decides if the patch is applied when the release
+	// version is lower than the previous version. Defaults to "any".
+	VersionPolicy VersionPolicy `yaml:"versionPolicy,omitempty"`
 }
 
 type PatchYAML struct {
 	File       string           `jsonschema:"required"`
 	YAMLPath   *YAMLPathPattern `yaml:"yamlPath" jsonschema:"required"`
 	Replace    *Template        `jsonschema:"required"`
+	Type       YAMLValueType    `yaml:",omitempty"`
 	MaxMatches int              `yaml:"maxMatches,omitempty" jsonschema:"minimum=0"`
-	Indent     int              `yaml:",omitempty" jsonschema:"minimum=0"`
+	// Indent is the number of spaces per level for nested keys added by
+	// CreateMissing. Defaults to 2.
+	Indent int `yaml:",omitempty" jsonschema:"minimum=0"`
+
+	// CreateMissing adds the mapping keys of the YAML-Path if it doesn't
+	// match anything. Only supported for simple paths, such as ".foo.bar".
+	CreateMissing bool `yaml:"createMissing,omitempty"`
+
+	// VersionPolicy decides if the patch is applied when the release
+	// version is lower than the matched value, which is available as
+	// .PreviousVersion in the Replace template. Defaults to "any".
+	VersionPolicy VersionPolicy `yaml:"versionPolicy,omitempty"`
+}
+
+// PatchHCL sets an attribute inside a block in a HCL file, such as
+// Terraform's module and provider versions.
+type PatchHCL struct {
+	File string `jsonschema:"required"`
+	// Block is the block type to target. Nested blocks are separated by dots.
+	Block string `jsonschema:"required,example=module,example=terraform.required_providers"`
+	// Labels must all match the block's labels, if set.
+	Labels []string `yaml:",omitempty"`
+	// Attribute is the attribute name to set. Object attributes,
+	// such as in Terraform's required_providers block, are separated by dots.
+	Attribute string    `jsonschema:"required,example=version,example=aws.version"`
+	Replace   *Template `jsonschema:"required"`
+}
+
+// PatchFile renders a whole file from a template, such as a migration note
+// or a generated values file, or deletes a file.
+type PatchFile struct {
+	File string `jsonschema:"required,example=versions.lock"`
+	// Content is the full content of the file. Required unless Delete is set.
+	Content *Template `yaml:",omitempty"`
+	// Mode decides what to do if the file already exists, or is missing.
+	Mode FileWriteMode `yaml:",omitempty"`
+	// Permissions of the file as a quoted octal string, such as "0644".
+	// Defaults to the permissions of the existing file, or 0644 for new files.
+	Permissions FilePermissions `yaml:",omitempty"`
+	// Delete removes the file instead of writing to it, if it exists.
+	Delete bool `yaml:",omitempty"`
+}
+
+// PatchChangelog adds an entry to a changelog file in the style of
+// https://keepachangelog.com, such as a line under the "Unreleased" section.
+type PatchChangelog struct {
+	File string `jsonschema:"required,example=CHANGELOG.md"`
+	// Section matches the heading of the section to add the entry to.
+	// Defaults to matching "## [Unreleased]".
+	Section *RegexPattern `yaml:",omitempty"`
+	// SectionTitle is the heading to add if no heading matches Section.
+	SectionTitle string `yaml:"sectionTitle,omitempty" jsonschema:"default=## [Unreleased]"`
+	// Subsection is the "###" heading inside the section to add the entry
+	// to, such as "Changed". It is created if missing.
+	Subsection string `yaml:",omitempty" jsonschema:"example=Changed,example=Added,example=Fixed"`
+	// Entry is the text to add. Nothing is added if the section
+	// already contains the same entry.
+	Entry *Template `jsonschema:"required,example=- Updated {{ .Package }} to {{ .Version }}"`
 }
 
 type PatchHelmDepUpdate struct {
@@ -104,15 +229,106 @@ type PatchHelmDepUpdate struct {
 type GitHub struct {
 	URL     *string `jsonschema:"oneof_type=string;null" jsonschema_extras:"format=uri"`
 	TempDir *string `yaml:"tempDir" jsonschema:"oneof_type=string;null" jsonschema_extras:"format=uri"`
-	Auth    GitHubAuth
-	PR      GitHubPR
+	// GitBackend is the implementation used to clone, commit, and push.
+	GitBackend GitBackend `yaml:"gitBackend"`
+	// MirrorCache keeps mirrors of the cloned repositories between runs.
+	MirrorCache GitMirrorCache `yaml:"mirrorCache"`
+	// SSH is used for repositories with SSH remotes.
+	SSH  GitSSH `yaml:"ssh"`
+	Auth GitHubAuth
+	PR   GitHubPR
+}
+
+type GitSSH struct {
+	// PrivateKeyPath is the SSH private key used for SSH remotes,
+	// unless overridden per repository.
+	PrivateKeyPath *string `yaml:"privateKeyPath" jsonschema:"oneof_type=string;null"`
+	// KnownHostsPath is the known_hosts file used to verify the host keys.
+	KnownHostsPath *string `yaml:"knownHostsPath" jsonschema:"oneof_type=string;null"`
+}
+
+type GitMirrorCache struct {
+	// Dir is where the mirrors are stored. The cache is disabled when unset.
+	Dir *string `yaml:"dir" jsonschema:"oneof_type=string;null"`
+	// MaxAge evicts mirrors that have not been used for this long.
+	MaxAge Duration `yaml:"maxAge"`
+	// MaxSizeMiB evicts the least recently used mirrors when the total
+	// size of the cache exceeds this many mebibytes.
+	MaxSizeMiB int64 `yaml:"maxSizeMiB"`
 }
 
 func (gh GitHub) Censored() GitHub {
 	gh.Auth = gh.Auth.Censored()
+	gh.PR.Committer = gh.PR.Committer.Censored()
 	return gh
 }
 
+// GitLab is used for the repositories on the GitLab instance's host,
+// instead of GitHub. The rest of the GitHub config, such as the PR
+// templates, is used for GitLab merge requests as well.
+type GitLab struct {
+	// URL of the GitLab instance, e.g "https://gitlab.example.com".
+	URL *string `jsonschema:"oneof_type=string;null" jsonschema_extras:"format=uri"`
+	// Tokens are the project and group access tokens. The token with the
+	// longest path that contains the repository is used.
+	Tokens []GitLabToken `yaml:",omitempty"`
+}
+
+type GitLabToken struct {
+	// Path is the full path of the project for project access tokens,
+	// e.g "my-group/my-project", or of the group for group access tokens,
+	// e.g "my-group".
+	Path  string
+	Token string
+}
+
+func (gl GitLab) Censored() GitLab {
+	if len(gl.Tokens) == 0 {
+		return gl
+	}
+	tokens := make([]GitLabToken, len(gl.Tokens))
+	for i, t := range gl.Tokens {
+		tokens[i] = GitLabToken{Path: t.Path, Token: redacted}
+	}
+	gl.Tokens = tokens
+	return gl
+}
+
+// Bitbucket is used for the repositories on the Bitbucket Server or
+// Data Center instance's host, instead of GitHub. The rest of the GitHub
+// config, such as the PR templates, is used for Bitbucket as well.
+type Bitbucket struct {
+	// URL of the Bitbucket instance, e.g "https://bitbucket.example.com".
+	URL *string `jsonschema:"oneof_type=string;null" jsonschema_extras:"format=uri"`
+	// Tokens are the HTTP access tokens. The token with the longest path
+	// that contains the repository is used.
+	Tokens []BitbucketToken `yaml:",omitempty"`
+}
+
+type BitbucketToken struct {
+	// Path is the project key for project tokens and personal tokens,
+	// e.g "PROJ", or the project key and repository slug for repository
+	// tokens, e.g "PROJ/my-repo".
+	Path string
+	// Username is used together with the token for Git over HTTPS,
+	// and must be set to the token owner's username for personal tokens.
+	// Defaults to "x-token-auth".
+	Username string `yaml:",omitempty"`
+	Token    string
+}
+
+func (bb Bitbucket) Censored() Bitbucket {
+	if len(bb.Tokens) == 0 {
+		return bb
+	}
+	tokens := make([]BitbucketToken, len(bb.Tokens))
+	for i, t := range bb.Tokens {
+		tokens[i] = BitbucketToken{Path: t.Path, Username: t.Username, Token: redacted}
+	}
+	bb.Tokens = tokens
+	return bb
+}
+
 type GitHubAuth struct {
 	Type  GitHubAuthType
 	Token *string `yaml:",omitempty" jsonschema:"oneof_type=string;null"`
@@ -151,11 +367,96 @@ type GitHubPR struct {
 	Branch      *Template
 	Commit      *Template
 	Committer   GitHubCommitter
+	// ExistingBranch is what to do when the branch already exists on the
+	// remote. An open PR for the branch is updated instead of creating
+	// a new one.
+	ExistingBranch ExistingBranchStrategy `yaml:"existingBranch"`
+	// PublishMode is how the commit is published to the remote.
+	PublishMode PublishMode `yaml:"publishMode"`
+	// Supersede closes the older open pull requests of the same package.
+	Supersede GitHubPRSupersede
+
+	GitHubPRMetadata `yaml:",inline" mapstructure:",squash"`
+}
+
+// GitHubPRMetadata is set on the created pull requests. It's configured
+// globally, and per repository via [PackageRepo.PR].
+type GitHubPRMetadata struct {
+	// Labels are added to the pull request. Labels that render empty
+	// are not added, which allows conditional labels.
+	Labels []*Template `yaml:",omitempty"`
+	// Reviewers are the users requested to review the pull request.
+	Reviewers []string `yaml:",omitempty"`
+	// TeamReviewers are the team slugs requested to review the pull request.
+	TeamReviewers []string `yaml:"teamReviewers,omitempty"`
+	// Assignees are the users assigned to the pull request.
+	Assignees []string `yaml:",omitempty"`
+	// Draft creates the pull request as a draft.
+	Draft *bool `yaml:",omitempty" jsonschema:"oneof_type=boolean;null"`
+	// Milestone is the title or number of the milestone
+	// to add the pull request to.
+	Milestone *string `yaml:",omitempty" jsonschema:"oneof_type=string;null"`
+}
+
+// Merge returns the metadata with the lists of the other metadata appended,
+// and the other metadata's draft and milestone settings if set.
+func (m GitHubPRMetadata) Merge(other GitHubPRMetadata) GitHubPRMetadata {
+	return GitHubPRMetadata{
+		Labels:        slices.Concat(m.Labels, other.Labels),
+		Reviewers:     appendUnique(m.Reviewers, other.Reviewers...),
+		TeamReviewers: appendUnique(m.TeamReviewers, other.TeamReviewers...),
+		Assignees:     appendUnique(m.Assignees, other.Assignees...),
+		Draft:         cmp.Or(other.Draft, m.Draft),
+		Milestone:     cmp.Or(other.Milestone, m.Milestone),
+	}
+}
+
+func appendUnique(list []string, values ...string) []string {
+	result := slices.Clone(list)
+	for _, v := range values {
+		if !slices.Contains(result, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+type GitHubPRSupersede struct {
+	// Enabled closes the open pull requests into the same base branch whose
+	// branch name matches the PR branch name of an older version of the
+	// package, and deletes their branches.
+	Enabled bool
+	// Comment is posted on the closed pull requests.
+	Comment *Template
+	// KeepLabel is the label of pull requests that are never closed.
+	KeepLabel string `yaml:"keepLabel" jsonschema:"default=do-not-close"`
 }
 
 type GitHubCommitter struct {
-	Name  *string `jsonschema:"oneof_type=string;null"`
-	Email *string `jsonschema:"oneof_type=string;null" jsonschema_extras:"format=idn-email"`
+	Name    *string `jsonschema:"oneof_type=string;null"`
+	Email   *string `jsonschema:"oneof_type=string;null" jsonschema_extras:"format=idn-email"`
+	Signing GitHubCommitSigning
+}
+
+func (c GitHubCommitter) Censored() GitHubCommitter {
+	c.Signing = c.Signing.Censored()
+	return c
+}
+
+type GitHubCommitSigning struct {
+	Format         CommitSigningFormat
+	PrivateKeyPath *string `yaml:"privateKeyPath,omitempty" jsonschema:"oneof_type=string;null"`
+	PrivateKeyPEM  *string `yaml:"privateKeyPem,omitempty" jsonschema:"oneof_type=string;null"`
+}
+
+func (s GitHubCommitSigning) Censored() GitHubCommitSigning {
+	if s.PrivateKeyPath != nil {
+		s.PrivateKeyPath = redactedPtr
+	}
+	if s.PrivateKeyPEM != nil {
+		s.PrivateKeyPEM = redactedPtr
+	}
+	return s
 }
 
 type Jira struct {
@@ -199,16 +500,39 @@ type JiraIssue struct {
 	// manually trigger the PR creation, instead of creating it automatically.
 	PRDeferredCreation bool `yaml:"prDeferredCreation"`
 
+	// PRChecks is for reporting the CI check results of the created
+	// pull requests back to the Jira issue.
+	PRChecks JiraIssuePRChecks `yaml:"prChecks"`
+
 	Comments JiraIssueComments
 }
 
+type JiraIssuePRChecks struct {
+	// Enabled watches the CI checks of the created pull requests, and
+	// comments on the Jira issue once they have passed or failed.
+	Enabled bool
+	// PollInterval is how often the checks are fetched from GitHub.
+	PollInterval Duration `yaml:"pollInterval"`
+	// Deadline is how long to wait for the checks to complete, after which
+	// the pull request is no longer watched.
+	Deadline Duration
+	// NoChecksTimeout is how long to wait for the first check to be
+	// reported, after which the repository is assumed to have no CI and
+	// the PRChecksNone comment is posted instead.
+	NoChecksTimeout Duration `yaml:"noChecksTimeout"`
+}
+
 type JiraIssueComments struct {
 	UpdatedIssue       *Template `yaml:"updatedIssue"`
 	NoConfig           *Template `yaml:"noConfig"`
 	NoPatches          *Template `yaml:"noPatches"`
+	AlreadyUpToDate    *Template `yaml:"alreadyUpToDate"`
 	PRCreated          *Template `yaml:"prCreated"`
 	PRFailed           *Template `yaml:"prFailed"`
 	PRDeferredCreation *Template `yaml:"prDeferredCreation"`
+	PRChecksPassed     *Template `yaml:"prChecksPassed"`
+	PRChecksFailed     *Template `yaml:"prChecksFailed"`
+	PRChecksNone       *Template `yaml:"prChecksNone"`
 }
 
 type HTTP struct {
@@ -236,14 +560,22 @@ type jsonSchemaInterface interface {
 	JSONSchema() *jsonschema.Schema
 }
 
+// Schema returns the JSON schema of the config file, including any
+// custom patch types added via [RegisterPatchSchema].
 func Schema() *jsonschema.Schema {
+	r := newSchemaReflector()
+	s := r.Reflect(&Config{})
+	s.ID = "https://github.com/RiskIdent/jelease/raw/main/jelease.schema.json"
+	addCustomPatchSchemas(s)
+	return s
+}
+
+func newSchemaReflector() *jsonschema.Reflector {
 	r := new(jsonschema.Reflector)
 	r.KeyNamer = util.ToCamelCase
 	r.Namer = func(t reflect.Type) string {
 		return util.ToCamelCase(t.Name())
 	}
 	r.RequiredFromJSONSchemaTags = true
-	s := r.Reflect(&Config{})
-	s.ID = "https://github.com/RiskIdent/jelease/raw/main/jelease.schema.json"
-	return s
+	return r
 }
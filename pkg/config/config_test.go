@@ -19,7 +19,13 @@ package config
 
 import (
 	"net/url"
+	"slices"
 	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/util"
+	"github.com/invopop/jsonschema"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
 )
 
 func TestCensored(t *testing.T) {
@@ -69,6 +75,177 @@ func TestCensored(t *testing.T) {
 	}
 }
 
+func TestFilePermissions_decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    FilePermissions
+		wantErr bool
+	}{
+		{name: "quoted", yaml: `permissions: "0644"`, want: 0o644},
+		{name: "without leading zero", yaml: `permissions: "600"`, want: 0o600},
+		{name: "0o prefix", yaml: `permissions: "0o750"`, want: 0o750},
+		{name: "not octal", yaml: `permissions: "0999"`, wantErr: true},
+		{name: "out of range", yaml: `permissions: "01777"`, wantErr: true},
+		{name: "unquoted", yaml: "permissions: 644", wantErr: true},
+		{name: "unquoted with leading zero", yaml: "permissions: 0755", wantErr: true},
+	}
+	decoders := []struct {
+		name   string
+		decode func(t *testing.T, data []byte, patch *PatchFile) error
+	}{
+		{name: "mapstructure", decode: func(t *testing.T, data []byte, patch *PatchFile) error {
+			var raw map[string]any
+			if err := yaml.Unmarshal(data, &raw); err != nil {
+				t.Fatal(err)
+			}
+			// Same as how viper decodes the config file
+			dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook: DecodeHook(),
+				Result:     patch,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return dec.Decode(raw)
+		}},
+		{name: "yaml", decode: func(t *testing.T, data []byte, patch *PatchFile) error {
+			// Same as how the "try package" page decodes the package config
+			return yaml.Unmarshal(data, patch)
+		}},
+	}
+	for _, dec := range decoders {
+		for _, tc := range tests {
+			t.Run(dec.name+"/"+tc.name, func(t *testing.T) {
+				var patch PatchFile
+				err := dec.decode(t, []byte(tc.yaml), &patch)
+				if tc.wantErr {
+					if err == nil {
+						t.Fatalf("want error, got %s", patch.Permissions)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if patch.Permissions != tc.want {
+					t.Errorf("want %s, got %s", tc.want, patch.Permissions)
+				}
+			})
+		}
+	}
+}
+
+func TestSchema_customPatchType(t *testing.T) {
+	schema := &jsonschema.Schema{Type: "object"}
+	if err := RegisterPatchSchema("testCustomPatch", schema); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterPatchSchema("regex", schema); err == nil {
+		t.Error("want error when registering built-in patch type, got nil")
+	}
+
+	def, ok := Schema().Definitions["packageRepoPatch"]
+	if !ok {
+		t.Fatal("missing packageRepoPatch definition")
+	}
+	if got, ok := def.Properties.Get("testCustomPatch"); !ok || got != schema {
+		t.Error("want custom patch type in properties")
+	}
+	last := def.OneOf[len(def.OneOf)-1]
+	if len(last.Required) != 1 || last.Required[0] != "testCustomPatch" {
+		t.Errorf("want custom patch type last in oneOf, got %v", last.Required)
+	}
+}
+
+func TestPackageRepoPatch_decodeCustom(t *testing.T) {
+	raw := map[string]any{
+		"when":   "true",
+		"myType": map[string]any{"foo": "bar"},
+	}
+	var patch PackageRepoPatch
+	// Same as how viper decodes the config file
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.TextUnmarshallerHookFunc(),
+		Result:     &patch,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(raw); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := patch.Custom["myType"]; !ok || len(patch.Custom) != 1 {
+		t.Errorf("want only myType in custom patches, got %v", patch.Custom)
+	}
+}
+
+func TestGitHubPR_decodeMetadata(t *testing.T) {
+	raw := map[string]any{
+		"title":     "Update",
+		"labels":    []any{"dependencies"},
+		"reviewers": []any{"alice"},
+		"draft":     true,
+	}
+	var pr GitHubPR
+	// Same as how viper decodes the config file
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.TextUnmarshallerHookFunc(),
+		Result:     &pr,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(raw); err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.Labels) != 1 || pr.Labels[0].String() != "dependencies" {
+		t.Errorf("want labels [dependencies], got %v", pr.Labels)
+	}
+	if !slices.Equal(pr.Reviewers, []string{"alice"}) {
+		t.Errorf("want reviewers [alice], got %v", pr.Reviewers)
+	}
+	if pr.Draft == nil || !*pr.Draft {
+		t.Errorf("want draft true, got %v", pr.Draft)
+	}
+}
+
+func TestGitHubPRMetadata_Merge(t *testing.T) {
+	global := GitHubPRMetadata{
+		Labels:    []*Template{MustTemplate("dependencies")},
+		Reviewers: []string{"alice"},
+		Draft:     util.Ref(true),
+		Milestone: util.Ref("v1"),
+	}
+	repo := GitHubPRMetadata{
+		Labels:        []*Template{MustTemplate("team-a")},
+		Reviewers:     []string{"alice", "bob"},
+		TeamReviewers: []string{"platform"},
+		Draft:         util.Ref(false),
+	}
+
+	got := global.Merge(repo)
+
+	if len(got.Labels) != 2 || got.Labels[1].String() != "team-a" {
+		t.Errorf("want labels [dependencies team-a], got %v", got.Labels)
+	}
+	if !slices.Equal(got.Reviewers, []string{"alice", "bob"}) {
+		t.Errorf("want reviewers [alice bob], got %v", got.Reviewers)
+	}
+	if !slices.Equal(got.TeamReviewers, []string{"platform"}) {
+		t.Errorf("want team reviewers [platform], got %v", got.TeamReviewers)
+	}
+	if util.Deref(got.Draft, true) {
+		t.Error("want repo draft setting to override global")
+	}
+	if util.Deref(got.Milestone, "") != "v1" {
+		t.Errorf("want global milestone v1, got %v", got.Milestone)
+	}
+	if len(global.Reviewers) != 1 {
+		t.Errorf("want global reviewers unchanged, got %v", global.Reviewers)
+	}
+}
+
 func mustParseURL(t *testing.T, value string) *url.URL {
 	t.Helper()
 	u, err := url.Parse(value)
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// ExistingBranchStrategy is what to do when the PR branch already exists
+// on the remote, such as when re-running an update.
+type ExistingBranchStrategy string
+
+const (
+	// ExistingBranchForcePush overwrites the remote branch with the newly
+	// created commit, unless the branch changed since it was checked.
+	ExistingBranchForcePush ExistingBranchStrategy = "forcePush"
+	// ExistingBranchKeep leaves the remote branch and its open PR as-is,
+	// but still creates a PR for the branch if there is none.
+	ExistingBranchKeep ExistingBranchStrategy = "keep"
+	// ExistingBranchFail fails the update for the repository.
+	ExistingBranchFail ExistingBranchStrategy = "fail"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := ExistingBranchForcePush
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f ExistingBranchStrategy) String() string {
+	return string(f)
+}
+
+func (f *ExistingBranchStrategy) Set(value string) error {
+	switch ExistingBranchStrategy(value) {
+	case ExistingBranchForcePush:
+		*f = ExistingBranchForcePush
+	case ExistingBranchKeep:
+		*f = ExistingBranchKeep
+	case ExistingBranchFail:
+		*f = ExistingBranchFail
+	default:
+		return fmt.Errorf("unknown existing branch strategy: %q, must be one of: forcePush, keep, fail", value)
+	}
+	return nil
+}
+
+func (f *ExistingBranchStrategy) Type() string {
+	return "strategy"
+}
+
+func (f *ExistingBranchStrategy) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (ExistingBranchStrategy) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Existing branch strategy",
+		Default: ExistingBranchForcePush,
+		Enum: []any{
+			ExistingBranchForcePush,
+			ExistingBranchKeep,
+			ExistingBranchFail,
+		},
+	}
+}
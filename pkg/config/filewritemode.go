@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// FileWriteMode decides how a file patch treats existing files.
+type FileWriteMode string
+
+const (
+	// FileWriteModeCreate creates the file, or overwrites it if it exists.
+	FileWriteModeCreate FileWriteMode = "create"
+	// FileWriteModeOverwrite overwrites the file, and fails if it's missing.
+	FileWriteModeOverwrite FileWriteMode = "overwrite"
+	// FileWriteModeCreateOnly creates the file, but leaves it as-is if it
+	// already exists.
+	FileWriteModeCreateOnly FileWriteMode = "createOnly"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := FileWriteModeCreate
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f FileWriteMode) String() string {
+	return string(f)
+}
+
+func (f *FileWriteMode) Set(value string) error {
+	switch FileWriteMode(value) {
+	case FileWriteModeCreate:
+		*f = FileWriteModeCreate
+	case FileWriteModeOverwrite:
+		*f = FileWriteModeOverwrite
+	case FileWriteModeCreateOnly:
+		*f = FileWriteModeCreateOnly
+	default:
+		return fmt.Errorf("unknown file write mode: %q, must be one of: create, overwrite, createOnly", value)
+	}
+	return nil
+}
+
+func (f *FileWriteMode) Type() string {
+	return "mode"
+}
+
+func (f *FileWriteMode) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (FileWriteMode) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "File write mode",
+		Default: FileWriteModeCreate,
+		Enum: []any{
+			FileWriteModeCreate,
+			FileWriteModeOverwrite,
+			FileWriteModeCreateOnly,
+		},
+	}
+}
+
+// FilePermissions is the Unix permission bits of a file, written as an
+// octal number in a string, such as "0644" or "0755".
+type FilePermissions fs.FileMode
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := FilePermissions(0)
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ encoding.TextMarshaler = f
+	var _ yaml.Unmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f FilePermissions) String() string {
+	return fmt.Sprintf("%04o", uint32(f))
+}
+
+func (f *FilePermissions) Set(value string) error {
+	perm, err := strconv.ParseUint(strings.TrimPrefix(value, "0o"), 8, 32)
+	if err != nil {
+		return fmt.Errorf("parse file permissions as octal number: %q", value)
+	}
+	if perm > 0o777 {
+		return fmt.Errorf("file permissions out of range: %q, must be between 0000 and 0777", value)
+	}
+	*f = FilePermissions(perm)
+	return nil
+}
+
+func (f *FilePermissions) Type() string {
+	return "permissions"
+}
+
+func (f *FilePermissions) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+// UnmarshalYAML only accepts strings, the same as [FilePermissionsHookFunc].
+// Without it, the YAML decoder passes the text of an unquoted 0644 or 644
+// to [FilePermissions.UnmarshalText], which would accept a config that
+// fails when the same YAML is decoded via viper.
+func (f *FilePermissions) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!str" {
+		return fmt.Errorf("line %d: file permissions must be a quoted octal string, such as \"0644\", got: %s", value.Line, value.Value)
+	}
+	return f.Set(value.Value)
+}
+
+// MarshalText writes the permissions as an octal string, so the output
+// of "jelease config" can be read back as a config file.
+func (f FilePermissions) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// FileMode returns the permissions as a [fs.FileMode].
+func (f FilePermissions) FileMode() fs.FileMode {
+	return fs.FileMode(f)
+}
+
+func (FilePermissions) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:     "string",
+		Title:    "File permissions",
+		Pattern:  `^(0o?)?[0-7]{3}$`,
+		Examples: []any{"0644", "0755"},
+	}
+}
+
+// FilePermissionsHookFunc returns a mapstructure decode hook that rejects
+// numbers as [FilePermissions]. The YAML parser has already turned an
+// unquoted "0644" into 420 and "644" into 644 by then, so there's no way
+// to tell which octal number was meant, and mapstructure would otherwise
+// copy the number as-is into the permission bits.
+func FilePermissionsHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from, to reflect.Type, data any) (any, error) {
+		if to != reflect.TypeFor[FilePermissions]() || from.Kind() == reflect.String {
+			return data, nil
+		}
+		return nil, fmt.Errorf("file permissions must be a quoted octal string, such as \"0644\", got: %v", data)
+	}
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// GitBackend is the implementation used to clone, commit, and push
+// to Git repositories.
+type GitBackend string
+
+const (
+	// GitBackendCmd uses the command-line version of Git.
+	GitBackendCmd GitBackend = "cmd"
+	// GitBackendGoGit uses the pure-Go library go-git, so Git does not
+	// need to be installed.
+	GitBackendGoGit GitBackend = "goGit"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := GitBackendCmd
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f GitBackend) String() string {
+	return string(f)
+}
+
+func (f *GitBackend) Set(value string) error {
+	switch GitBackend(value) {
+	case GitBackendCmd:
+		*f = GitBackendCmd
+	case GitBackendGoGit:
+		*f = GitBackendGoGit
+	default:
+		return fmt.Errorf("unknown Git backend: %q, must be one of: cmd, goGit", value)
+	}
+	return nil
+}
+
+func (f *GitBackend) Type() string {
+	return "backend"
+}
+
+func (f *GitBackend) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (GitBackend) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Git backend",
+		Default: GitBackendCmd,
+		Enum: []any{
+			GitBackendCmd,
+			GitBackendGoGit,
+		},
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// MergeMethod is how a pull request is merged.
+type MergeMethod string
+
+const (
+	// MergeMethodMerge adds all commits to the base branch with a merge commit.
+	MergeMethodMerge MergeMethod = "merge"
+	// MergeMethodSquash combines all commits into a single commit.
+	MergeMethodSquash MergeMethod = "squash"
+	// MergeMethodRebase adds all commits to the base branch individually.
+	MergeMethodRebase MergeMethod = "rebase"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := MergeMethodMerge
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f MergeMethod) String() string {
+	return string(f)
+}
+
+func (f *MergeMethod) Set(value string) error {
+	switch MergeMethod(value) {
+	case MergeMethodMerge:
+		*f = MergeMethodMerge
+	case MergeMethodSquash:
+		*f = MergeMethodSquash
+	case MergeMethodRebase:
+		*f = MergeMethodRebase
+	default:
+		return fmt.Errorf("unknown merge method: %q, must be one of: merge, squash, rebase", value)
+	}
+	return nil
+}
+
+func (f *MergeMethod) Type() string {
+	return "method"
+}
+
+func (f *MergeMethod) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (MergeMethod) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Merge method",
+		Default: MergeMethodMerge,
+		Enum: []any{
+			MergeMethodMerge,
+			MergeMethodSquash,
+			MergeMethodRebase,
+		},
+	}
+}
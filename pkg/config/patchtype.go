@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/RiskIdent/jelease/pkg/util"
+	"github.com/invopop/jsonschema"
+)
+
+// customPatchSchemas holds the JSON schemas of patch types that are added
+// outside of this package, such as by Go library users via the patches
+// package's registry. They are decoded into [PackageRepoPatch.Custom].
+var customPatchSchemas = struct {
+	sync.RWMutex
+	schemas map[string]*jsonschema.Schema
+}{schemas: map[string]*jsonschema.Schema{}}
+
+// RegisterPatchSchema adds the JSON schema of a custom patch type to the
+// result of [Schema], so that it can be used by its name inside a repo's
+// list of patches.
+func RegisterPatchSchema(name string, schema *jsonschema.Schema) error {
+	if IsBuiltinPatchType(name) {
+		return fmt.Errorf("patch type %q is a built-in patch type", name)
+	}
+	customPatchSchemas.Lock()
+	defer customPatchSchemas.Unlock()
+	if _, ok := customPatchSchemas.schemas[name]; ok {
+		return fmt.Errorf("patch type %q is already registered", name)
+	}
+	customPatchSchemas.schemas[name] = schema
+	return nil
+}
+
+// IsBuiltinPatchType returns true if the name is used by one of the fields
+// in [PackageRepoPatch], such as "regex" or "when".
+func IsBuiltinPatchType(name string) bool {
+	t := reflect.TypeFor[PackageRepoPatch]()
+	for field := range t.Fields() {
+		if field.Name != "Custom" && util.ToCamelCase(field.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addCustomPatchSchemas adds the registered custom patch types to the
+// package repo patch definition, next to the built-in patch types.
+func addCustomPatchSchemas(s *jsonschema.Schema) {
+	customPatchSchemas.RLock()
+	defer customPatchSchemas.RUnlock()
+	def, ok := s.Definitions["packageRepoPatch"]
+	if !ok || len(customPatchSchemas.schemas) == 0 {
+		return
+	}
+	names := make([]string, 0, len(customPatchSchemas.schemas))
+	for name := range customPatchSchemas.schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		def.Properties.Set(name, customPatchSchemas.schemas[name])
+		def.OneOf = append(def.OneOf, &jsonschema.Schema{
+			Required: []string{name},
+			Title:    name,
+		})
+	}
+}
+
+// ReflectSchema returns the JSON schema of a config type, named the same
+// way as in [Schema], but with all definitions inlined. It's meant for
+// the config types of custom patch types.
+func ReflectSchema(v any) *jsonschema.Schema {
+	r := newSchemaReflector()
+	r.DoNotReference = true
+	s := r.Reflect(v)
+	s.Version = ""
+	return s
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// PublishMode is how the commit is published to the remote repository.
+type PublishMode string
+
+const (
+	// PublishModePush pushes the commit using Git.
+	PublishModePush PublishMode = "push"
+	// PublishModeAPI recreates the commit using the GitHub Git Data API,
+	// which GitHub signs automatically when using GitHub App auth.
+	PublishModeAPI PublishMode = "api"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := PublishModePush
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f PublishMode) String() string {
+	return string(f)
+}
+
+func (f *PublishMode) Set(value string) error {
+	switch PublishMode(value) {
+	case PublishModePush:
+		*f = PublishModePush
+	case PublishModeAPI:
+		*f = PublishModeAPI
+	default:
+		return fmt.Errorf("unknown publish mode: %q, must be one of: push, api", value)
+	}
+	return nil
+}
+
+func (f *PublishMode) Type() string {
+	return "mode"
+}
+
+func (f *PublishMode) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (PublishMode) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Publish mode",
+		Default: PublishModePush,
+		Enum: []any{
+			PublishModePush,
+			PublishModeAPI,
+		},
+	}
+}
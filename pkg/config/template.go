@@ -54,6 +54,13 @@ type TemplateContext struct {
 	PackageDescription string
 	Version            string
 	JiraIssue          string
+	// PreviousVersion is the version that was in the file before patching.
+	// Only set in the templates of patches that detect it, and in the PR
+	// templates if all patches found the same previous version.
+	PreviousVersion string
+	// BaseBranch is the branch that the pull request targets.
+	// Not set when evaluating the repository's `when` condition.
+	BaseBranch string
 }
 
 // Ensure the type implements the interfaces
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// VersionChange is the largest version change that is allowed,
+// such as only allowing patch version bumps to be auto-merged.
+type VersionChange string
+
+const (
+	// VersionChangePatch only allows changes after the major and minor
+	// version, e.g v1.2.3 -> v1.2.4
+	VersionChangePatch VersionChange = "patch"
+	// VersionChangeMinor allows changes in the minor version or later,
+	// e.g v1.2.3 -> v1.3.0
+	VersionChangeMinor VersionChange = "minor"
+	// VersionChangeMajor allows any version change, e.g v1.2.3 -> v2.0.0
+	VersionChangeMajor VersionChange = "major"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := VersionChangePatch
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f VersionChange) String() string {
+	return string(f)
+}
+
+func (f *VersionChange) Set(value string) error {
+	switch VersionChange(value) {
+	case VersionChangePatch:
+		*f = VersionChangePatch
+	case VersionChangeMinor:
+		*f = VersionChangeMinor
+	case VersionChangeMajor:
+		*f = VersionChangeMajor
+	default:
+		return fmt.Errorf("unknown version change: %q, must be one of: patch, minor, major", value)
+	}
+	return nil
+}
+
+func (f *VersionChange) Type() string {
+	return "change"
+}
+
+func (f *VersionChange) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (VersionChange) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Version change",
+		Default: VersionChangePatch,
+		Enum: []any{
+			VersionChangePatch,
+			VersionChangeMinor,
+			VersionChangeMajor,
+		},
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// VersionPolicy decides if a patch is applied when the release version is
+// lower than the version that's already in the repository, such as when
+// a backport is released on an older maintenance line.
+type VersionPolicy string
+
+const (
+	// VersionPolicyAny applies the patch regardless of the previous version.
+	VersionPolicyAny VersionPolicy = "any"
+	// VersionPolicyUpgradeOnly skips the patch if the release version is
+	// lower than the previous version.
+	VersionPolicyUpgradeOnly VersionPolicy = "upgradeOnly"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := VersionPolicyAny
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f VersionPolicy) String() string {
+	return string(f)
+}
+
+func (f *VersionPolicy) Set(value string) error {
+	switch VersionPolicy(value) {
+	case VersionPolicyAny:
+		*f = VersionPolicyAny
+	case VersionPolicyUpgradeOnly:
+		*f = VersionPolicyUpgradeOnly
+	default:
+		return fmt.Errorf("unknown version policy: %q, must be one of: any, upgradeOnly", value)
+	}
+	return nil
+}
+
+func (f *VersionPolicy) Type() string {
+	return "policy"
+}
+
+func (f *VersionPolicy) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (VersionPolicy) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "Version policy",
+		Default: VersionPolicyAny,
+		Enum: []any{
+			VersionPolicyAny,
+			VersionPolicyUpgradeOnly,
+		},
+	}
+}
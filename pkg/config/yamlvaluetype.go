@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/pflag"
+)
+
+// YAMLValueType is the type of the value written by a YAML patch.
+type YAMLValueType string
+
+const (
+	YAMLValueTypeString YAMLValueType = "string"
+	YAMLValueTypeInt    YAMLValueType = "int"
+	YAMLValueTypeFloat  YAMLValueType = "float"
+	YAMLValueTypeBool   YAMLValueType = "bool"
+	// YAMLValueTypeAuto resolves the type from the value itself,
+	// the same way an unquoted value would be resolved in a YAML file.
+	YAMLValueTypeAuto YAMLValueType = "auto"
+)
+
+func _() {
+	// Ensure the type implements the interfaces
+	f := YAMLValueTypeString
+	var _ pflag.Value = &f
+	var _ encoding.TextUnmarshaler = &f
+	var _ jsonSchemaInterface = f
+}
+
+func (f YAMLValueType) String() string {
+	return string(f)
+}
+
+func (f *YAMLValueType) Set(value string) error {
+	switch YAMLValueType(value) {
+	case YAMLValueTypeString:
+		*f = YAMLValueTypeString
+	case YAMLValueTypeInt:
+		*f = YAMLValueTypeInt
+	case YAMLValueTypeFloat:
+		*f = YAMLValueTypeFloat
+	case YAMLValueTypeBool:
+		*f = YAMLValueTypeBool
+	case YAMLValueTypeAuto:
+		*f = YAMLValueTypeAuto
+	default:
+		return fmt.Errorf("unknown YAML value type: %q, must be one of: string, int, float, bool, auto", value)
+	}
+	return nil
+}
+
+func (f *YAMLValueType) Type() string {
+	return "type"
+}
+
+func (f *YAMLValueType) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
+
+func (YAMLValueType) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:    "string",
+		Title:   "YAML value type",
+		Default: YAMLValueTypeString,
+		Enum: []any{
+			YAMLValueTypeString,
+			YAMLValueTypeInt,
+			YAMLValueTypeFloat,
+			YAMLValueTypeBool,
+			YAMLValueTypeAuto,
+		},
+	}
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forge
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CheckState is the state of a CI check, or the combined state of all
+// the checks of a commit.
+type CheckState string
+
+const (
+	CheckStatePending CheckState = "pending"
+	CheckStateSuccess CheckState = "success"
+	CheckStateFailure CheckState = "failure"
+)
+
+// Check is a single CI check on a commit, such as a GitHub Actions check run,
+// a commit status, or a GitLab pipeline.
+type Check struct {
+	Name  string
+	URL   string
+	State CheckState
+}
+
+// CommitChecks is the CI checks of a commit.
+type CommitChecks struct {
+	// State is pending until all the checks have completed, and then
+	// failure if any of them failed. It's also pending if there are
+	// no checks yet.
+	State  CheckState
+	Checks []Check
+}
+
+// Failed returns the checks that failed.
+func (c CommitChecks) Failed() []Check {
+	var failed []Check
+	for _, check := range c.Checks {
+		if check.State == CheckStateFailure {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
+
+// NewCommitChecks combines the state of the checks. See [CommitChecks.State].
+func NewCommitChecks(checks []Check) CommitChecks {
+	state := CheckStateSuccess
+	if len(checks) == 0 {
+		state = CheckStatePending
+	}
+	for _, check := range checks {
+		switch check.State {
+		case CheckStatePending:
+			return CommitChecks{State: CheckStatePending, Checks: checks}
+		case CheckStateFailure:
+			state = CheckStateFailure
+		}
+	}
+	return CommitChecks{State: state, Checks: checks}
+}
+
+// ErrNoChecks is returned by [WaitForChecks] when no checks have been
+// reported for the commit, such as for repositories without any CI.
+var ErrNoChecks = errors.New("no CI checks reported")
+
+// WaitForChecks polls the checks of the pull request's head commit until
+// they're no longer pending, or until the context is done, in which case
+// the last fetched checks are returned together with the context's error.
+//
+// If no checks at all have been reported after the noChecksTimeout, then it
+// stops early with [ErrNoChecks]. A zero noChecksTimeout waits for checks
+// until the context is done.
+//
+// Failing to fetch the checks is only logged, and retried on the next poll.
+func WaitForChecks(ctx context.Context, gh Client, pr PullRequest, interval, noChecksTimeout time.Duration) (CommitChecks, error) {
+	ref := cmp.Or(pr.HeadSHA, pr.Commit.Hash)
+	start := time.Now()
+	var checks CommitChecks
+	for {
+		latest, err := gh.GetCommitChecks(ctx, pr.RepoRef, ref)
+		if err != nil {
+			log.Warn().Err(err).Str("url", pr.URL).
+				Msg("Failed to get CI checks of PR. Will retry.")
+		} else {
+			checks = latest
+			if checks.State != CheckStatePending {
+				return checks, nil
+			}
+			if len(checks.Checks) == 0 && noChecksTimeout > 0 && time.Since(start) >= noChecksTimeout {
+				return checks, ErrNoChecks
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return checks, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
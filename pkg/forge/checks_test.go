@@ -0,0 +1,103 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forge
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNewCommitChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   CheckState
+	}{
+		{name: "no checks", want: CheckStatePending},
+		{name: "success", checks: []Check{{State: CheckStateSuccess}, {State: CheckStateSuccess}}, want: CheckStateSuccess},
+		{name: "failure", checks: []Check{{State: CheckStateSuccess}, {State: CheckStateFailure}}, want: CheckStateFailure},
+		{name: "pending with failure", checks: []Check{{State: CheckStateFailure}, {State: CheckStatePending}}, want: CheckStatePending},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := NewCommitChecks(tc.checks).State; got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+type fakeChecksClient struct {
+	Client
+	states []CheckState
+	refs   []string
+}
+
+func (c *fakeChecksClient) GetCommitChecks(_ context.Context, _ RepoRef, ref string) (CommitChecks, error) {
+	c.refs = append(c.refs, ref)
+	if len(c.states) == 0 {
+		return CommitChecks{}, errors.New("no more states")
+	}
+	state := c.states[0]
+	c.states = c.states[1:]
+	return CommitChecks{State: state}, nil
+}
+
+func TestWaitForChecks(t *testing.T) {
+	fake := &fakeChecksClient{states: []CheckState{CheckStatePending, CheckStatePending, CheckStateSuccess}}
+	pr := PullRequest{HeadSHA: "abc123"}
+
+	checks, err := WaitForChecks(context.Background(), fake, pr, time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checks.State != CheckStateSuccess {
+		t.Errorf("want state %q, got %q", CheckStateSuccess, checks.State)
+	}
+	if len(fake.refs) != 3 || fake.refs[0] != "abc123" {
+		t.Errorf("want 3 polls of %q, got %q", "abc123", fake.refs)
+	}
+}
+
+func TestWaitForChecks_deadline(t *testing.T) {
+	fake := &fakeChecksClient{states: []CheckState{CheckStatePending}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	checks, err := WaitForChecks(ctx, fake, PullRequest{HeadSHA: "abc123"}, time.Millisecond, 0)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got: %v", err)
+	}
+	if checks.State != CheckStatePending {
+		t.Errorf("want last state %q, got %q", CheckStatePending, checks.State)
+	}
+}
+
+func TestWaitForChecks_noChecks(t *testing.T) {
+	fake := &fakeChecksClient{states: slices.Repeat([]CheckState{CheckStatePending}, 1000)}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := WaitForChecks(ctx, fake, PullRequest{HeadSHA: "abc123"}, time.Millisecond, 10*time.Millisecond)
+	if !errors.Is(err, ErrNoChecks) {
+		t.Fatalf("want no checks error, got: %v", err)
+	}
+}
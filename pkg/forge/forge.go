@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package forge contains the API of Git forges, such as GitHub, GitLab, or
+// Bitbucket, that the pull requests are created on, and the [Router] that
+// picks the forge of a repository based on the host of its remote URL.
+package forge
+
+import (
+	"context"
+	"errors"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+// ErrPullRequestNotFound is returned by [Client.FindPullRequest] when there
+// is no open pull request for the branch.
+var ErrPullRequestNotFound = errors.New("pull request not found")
+
+// Client is the API of a Git forge. GitLab merge requests and other forges'
+// equivalents of pull requests are also called pull requests here.
+type Client interface {
+	// ParseRepoRef parses the repository from a remote URL.
+	ParseRepoRef(remote string) (RepoRef, error)
+	CreatePullRequest(ctx context.Context, pr NewPullRequest) (PullRequest, error)
+	// FindPullRequest returns the open pull request from the head branch
+	// into the base branch, or [ErrPullRequestNotFound].
+	FindPullRequest(ctx context.Context, repo RepoRef, head, base string) (PullRequest, error)
+	// UpdatePullRequest sets the title and description of an existing
+	// pull request.
+	UpdatePullRequest(ctx context.Context, number int, pr NewPullRequest) (PullRequest, error)
+	// ListPullRequests returns all open pull requests into the base branch.
+	ListPullRequests(ctx context.Context, repo RepoRef, base string) ([]PullRequest, error)
+	// ClosePullRequest closes a pull request, after commenting on it
+	// if the comment is not empty.
+	ClosePullRequest(ctx context.Context, repo RepoRef, number int, comment string) error
+	// CreateCommit creates a commit using the forge's API instead of
+	// pushing it with Git, and returns its hash. Forges without such an API
+	// return an error wrapping [errors.ErrUnsupported], as do the
+	// CreateBranch and UpdateBranch methods.
+	CreateCommit(ctx context.Context, commit NewCommit) (string, error)
+	CreateBranch(ctx context.Context, repo RepoRef, branch, hash string) error
+	UpdateBranch(ctx context.Context, repo RepoRef, branch, hash string) error
+	DeleteBranch(ctx context.Context, repo RepoRef, branch string) error
+	// EnableAutoMerge enables auto-merge on a pull request, so it's merged
+	// once the required checks pass.
+	EnableAutoMerge(ctx context.Context, pr PullRequest, method config.MergeMethod) error
+	// GetCommitChecks returns the CI checks of a commit.
+	GetCommitChecks(ctx context.Context, repo RepoRef, ref string) (CommitChecks, error)
+	TestConnection(ctx context.Context) error
+	GitCredentialsForRepo(ctx context.Context, repo RepoRef) (git.Credentials, error)
+}
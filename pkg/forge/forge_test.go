@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forge
+
+import (
+	"testing"
+)
+
+type fakeClient struct {
+	Client
+	name string
+}
+
+func TestRouterForRemote(t *testing.T) {
+	gh := &fakeClient{name: "github"}
+	gl := &fakeClient{name: "gitlab"}
+	r := NewRouter(gh)
+	if err := r.AddHost("https://GitLab.example.com/", gl); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remote string
+		want   *fakeClient
+	}{
+		{remote: "https://gitlab.example.com/my-group/my-project", want: gl},
+		{remote: "git@gitlab.example.com:my-group/my-project.git", want: gl},
+		{remote: "https://github.com/RiskIdent/jelease", want: gh},
+		{remote: "git@github.com:RiskIdent/jelease.git", want: gh},
+		{remote: "https://gitlab.example.com.evil.com/my-group/my-project", want: gh},
+	}
+	for _, tc := range tests {
+		t.Run(tc.remote, func(t *testing.T) {
+			got := r.forRemote(tc.remote)
+			if got != tc.want {
+				t.Errorf("want %s, got %s", tc.want.name, got.(*fakeClient).name)
+			}
+		})
+	}
+}
+
+func TestRouterAddHost_missingHost(t *testing.T) {
+	r := NewRouter(&fakeClient{})
+	if err := r.AddHost("gitlab.example.com", &fakeClient{}); err == nil {
+		t.Error("want error, got nil")
+	}
+}
@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forge
+
+import (
+	"fmt"
+
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+type RepoRefSlim struct {
+	Owner string
+	Repo  string
+}
+
+func (r RepoRefSlim) String() string {
+	return fmt.Sprintf("%s/%s", r.Owner, r.Repo)
+}
+
+// RepoRef is a repository on a forge. The Owner is the GitHub user or
+// organization, the full path of the GitLab group, or the Bitbucket
+// project key.
+type RepoRef struct {
+	URL   string
+	Owner string
+	Repo  string
+}
+
+func (r RepoRef) String() string {
+	return r.URL
+}
+
+func (r RepoRef) Slim() RepoRefSlim {
+	return RepoRefSlim{
+		Owner: r.Owner,
+		Repo:  r.Repo,
+	}
+}
+
+type NewPullRequest struct {
+	RepoRef
+	Title         string
+	Description   string
+	Head          string
+	Base          string
+	Labels        []string
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
+	// Draft is only used when creating the pull request.
+	Draft bool
+	// Milestone is the title or number of the milestone, or empty.
+	Milestone string
+	Commit    git.Commit
+}
+
+type PullRequest struct {
+	RepoRef
+	ID int64
+	// NodeID is the ID used in the GitHub GraphQL API. Only set by GitHub.
+	NodeID      string
+	Number      int
+	URL         string
+	Title       string
+	Description string
+	// Head is the branch with the owner prefix, "owner:branch", where the
+	// owner differs from the repository's owner for pull requests from forks.
+	Head string
+	// HeadBranch is the branch name of the head, without the owner prefix
+	// that is in Head.
+	HeadBranch string
+	// HeadSHA is the commit hash of the head branch.
+	HeadSHA string
+	Base    string
+	Labels  []string
+	// Reviewers and TeamReviewers are the requested reviewers.
+	Reviewers     []string
+	TeamReviewers []string
+	Assignees     []string
+	Draft         bool
+	// Milestone is the title of the milestone, or empty.
+	Milestone string
+	Commit    git.Commit
+	// Updated is true when an already existing pull request was updated,
+	// instead of creating a new one.
+	Updated bool
+	// AutoMerge is true when auto-merge was enabled on the pull request.
+	AutoMerge bool
+}
+
+// NewCommit is a commit to create using the forge's API, such as the
+// GitHub Git Data API, instead of pushing it with Git.
+type NewCommit struct {
+	RepoRef
+	ParentHash string
+	Message    string
+	Files      []git.ChangedFile
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+// Router is a [Client] that sends each request to the forge of the
+// repository's host, and to the fallback client for all other hosts.
+type Router struct {
+	fallback Client
+	hosts    map[string]Client
+}
+
+func _() {
+	// Ensure the type implements the interfaces
+	var _ Client = &Router{}
+}
+
+// NewRouter creates a [Router] that sends all requests to the fallback
+// client, until other forges are added using [Router.AddHost].
+func NewRouter(fallback Client) *Router {
+	return &Router{
+		fallback: fallback,
+		hosts:    map[string]Client{},
+	}
+}
+
+// AddHost sends the requests for repositories on the host of the forge's
+// URL to the client.
+func (r *Router) AddHost(forgeURL string, client Client) error {
+	u, err := url.Parse(forgeURL)
+	if err != nil {
+		return err
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in URL: %s", forgeURL)
+	}
+	r.hosts[strings.ToLower(u.Host)] = client
+	return nil
+}
+
+// forRemote returns the client of the remote's host.
+func (r *Router) forRemote(remote string) Client {
+	if git.IsSSHRemote(remote) {
+		httpsRemote, err := git.SSHRemoteToHTTPS(remote)
+		if err != nil {
+			return r.fallback
+		}
+		remote = httpsRemote
+	}
+	u, err := url.Parse(remote)
+	if err != nil {
+		return r.fallback
+	}
+	if client, ok := r.hosts[strings.ToLower(u.Host)]; ok {
+		return client
+	}
+	return r.fallback
+}
+
+func (r *Router) forRepo(repo RepoRef) Client {
+	return r.forRemote(repo.URL)
+}
+
+func (r *Router) ParseRepoRef(remote string) (RepoRef, error) {
+	return r.forRemote(remote).ParseRepoRef(remote)
+}
+
+// TestConnection tests the connection towards all the forges.
+func (r *Router) TestConnection(ctx context.Context) error {
+	errs := []error{r.fallback.TestConnection(ctx)}
+	for host, client := range r.hosts {
+		if err := client.TestConnection(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", host, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Router) GitCredentialsForRepo(ctx context.Context, repo RepoRef) (git.Credentials, error) {
+	return r.forRepo(repo).GitCredentialsForRepo(ctx, repo)
+}
+
+func (r *Router) CreatePullRequest(ctx context.Context, pr NewPullRequest) (PullRequest, error) {
+	return r.forRepo(pr.RepoRef).CreatePullRequest(ctx, pr)
+}
+
+func (r *Router) FindPullRequest(ctx context.Context, repo RepoRef, head, base string) (PullRequest, error) {
+	return r.forRepo(repo).FindPullRequest(ctx, repo, head, base)
+}
+
+func (r *Router) UpdatePullRequest(ctx context.Context, number int, pr NewPullRequest) (PullRequest, error) {
+	return r.forRepo(pr.RepoRef).UpdatePullRequest(ctx, number, pr)
+}
+
+func (r *Router) ListPullRequests(ctx context.Context, repo RepoRef, base string) ([]PullRequest, error) {
+	return r.forRepo(repo).ListPullRequests(ctx, repo, base)
+}
+
+func (r *Router) ClosePullRequest(ctx context.Context, repo RepoRef, number int, comment string) error {
+	return r.forRepo(repo).ClosePullRequest(ctx, repo, number, comment)
+}
+
+func (r *Router) CreateCommit(ctx context.Context, commit NewCommit) (string, error) {
+	return r.forRepo(commit.RepoRef).CreateCommit(ctx, commit)
+}
+
+func (r *Router) CreateBranch(ctx context.Context, repo RepoRef, branch, hash string) error {
+	return r.forRepo(repo).CreateBranch(ctx, repo, branch, hash)
+}
+
+func (r *Router) UpdateBranch(ctx context.Context, repo RepoRef, branch, hash string) error {
+	return r.forRepo(repo).UpdateBranch(ctx, repo, branch, hash)
+}
+
+func (r *Router) DeleteBranch(ctx context.Context, repo RepoRef, branch string) error {
+	return r.forRepo(repo).DeleteBranch(ctx, repo, branch)
+}
+
+func (r *Router) EnableAutoMerge(ctx context.Context, pr PullRequest, method config.MergeMethod) error {
+	return r.forRepo(pr.RepoRef).EnableAutoMerge(ctx, pr, method)
+}
+
+func (r *Router) GetCommitChecks(ctx context.Context, repo RepoRef, ref string) (CommitChecks, error) {
+	return r.forRepo(repo).GetCommitChecks(ctx, repo, ref)
+}
@@ -35,6 +35,9 @@ import (
 type Cmd struct {
 	Credentials Credentials
 	Committer   Committer
+	// Mirror is an optional cache of mirrors of the remote repositories,
+	// which speeds up cloning the same repositories many times.
+	Mirror *MirrorCache
 }
 
 var _ Git = Cmd{}
@@ -55,16 +58,78 @@ func addCredentialsToRemote(remote string, cred Credentials) (string, error) {
 	return u.String(), nil
 }
 
+// cmdRemote is a remote repository, together with what's needed to
+// authenticate towards it using the command-line version of Git.
+type cmdRemote struct {
+	// url is without credentials, so it's safe to store on disk.
+	url string
+	// urlWithCred has the credentials added for HTTP(S) remotes.
+	urlWithCred string
+	// sshCommand is only set for SSH remotes.
+	sshCommand string
+}
+
+func newCmdRemote(remote string, cred Credentials) (cmdRemote, error) {
+	if IsSSHRemote(remote) {
+		return cmdRemote{
+			url:         remote,
+			urlWithCred: remote,
+			sshCommand:  sshCommand(cred.SSH),
+		}, nil
+	}
+	remoteWithCred, err := addCredentialsToRemote(remote, cred)
+	if err != nil {
+		return cmdRemote{}, err
+	}
+	return cmdRemote{url: remote, urlWithCred: remoteWithCred}, nil
+}
+
+// git runs a Git command with the config needed to access the remote.
+func (r cmdRemote) git(args ...string) ([]byte, error) {
+	if r.sshCommand != "" {
+		args = slices.Concat([]string{"-c", "core.sshCommand=" + r.sshCommand}, args)
+	}
+	return runGitCmd(args...)
+}
+
 func (g Cmd) Clone(targetDir, remote string) (Repo, error) {
-	log.Debug().Str("dir", targetDir).Str("remote", remote).Msg("Cloning into dir")
-	remoteWithCred, err := addCredentialsToRemote(remote, g.Credentials)
+	return g.CloneWithOptions(targetDir, remote, CloneOptions{})
+}
+
+func (g Cmd) CloneWithOptions(targetDir, remote string, opts CloneOptions) (Repo, error) {
+	log.Debug().Str("dir", targetDir).Str("remote", remote).
+		Str("branch", opts.Branch).Strs("sparse", opts.SparsePaths).
+		Msg("Cloning into dir")
+	r, err := newCmdRemote(remote, g.Credentials)
 	if err != nil {
 		return nil, err
 	}
-	_, err = runGitCmd("clone", "--single-branch", "--depth", "1", "--", remoteWithCred, targetDir)
+	if g.Mirror != nil {
+		err = g.cloneViaMirror(targetDir, r, opts)
+	} else {
+		args := []string{"clone", "--single-branch", "--depth", "1"}
+		if opts.SparsePaths != nil {
+			// Partial clone, so only the files of the sparse paths are fetched
+			args = append(args, "--filter=blob:none")
+		}
+		_, err = r.git(append(args, cloneOptionArgs(opts, r.urlWithCred, targetDir)...)...)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("clone repo: %w", err)
 	}
+	if r.sshCommand != "" {
+		// So pushing uses the same SSH key
+		if _, err := runGitCmd("-C", targetDir, "config", "core.sshCommand", r.sshCommand); err != nil {
+			return nil, fmt.Errorf("set SSH command: %w", err)
+		}
+	}
+	if opts.SparsePaths != nil {
+		// Also fetches the missing files of a partial clone
+		args := append([]string{"-C", targetDir, "sparse-checkout", "set", "--cone", "--"}, opts.SparsePaths...)
+		if _, err := r.git(args...); err != nil {
+			return nil, fmt.Errorf("sparse checkout: %w", err)
+		}
+	}
 	branchOutput, err := runAsCommitterInDir(g.Committer, targetDir, "branch", "--show-current")
 	if err != nil {
 		return nil, fmt.Errorf("check current branch: %w", err)
@@ -78,6 +143,20 @@ func (g Cmd) Clone(targetDir, remote string) (Repo, error) {
 	}, nil
 }
 
+// cloneOptionArgs returns the trailing arguments of "git clone",
+// only passing the branch if set so the remote's default branch is used.
+// With sparse paths, only the root directory is checked out initially.
+func cloneOptionArgs(opts CloneOptions, remote, targetDir string) []string {
+	var args []string
+	if opts.SparsePaths != nil {
+		args = append(args, "--sparse")
+	}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	return append(args, "--", remote, targetDir)
+}
+
 type CmdRepo struct {
 	Committer     Committer
 	directory     string
@@ -136,7 +215,26 @@ func (r *CmdRepo) StageChanges() error {
 	return nil
 }
 
+func (r *CmdRepo) hasStagedChanges() (bool, error) {
+	_, err := r.run("diff", "--staged", "--quiet")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("check staged changes: %w", err)
+	}
+	return false, nil
+}
+
 func (r *CmdRepo) CreateCommit(message string) (Commit, error) {
+	hasChanges, err := r.hasStagedChanges()
+	if err != nil {
+		return Commit{}, err
+	}
+	if !hasChanges {
+		return Commit{}, ErrNoChanges
+	}
 	diff, err := r.DiffStaged()
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed diffing changes. Trying to continue anyways.")
@@ -145,6 +243,11 @@ func (r *CmdRepo) CreateCommit(message string) (Commit, error) {
 	if _, err := r.run("commit", "-m", message, "--no-gpg-sign"); err != nil {
 		return Commit{}, fmt.Errorf("commit changes: %w", err)
 	}
+	if r.Committer.Signer != nil {
+		if err := r.signHeadCommit(); err != nil {
+			return Commit{}, fmt.Errorf("sign commit: %w", err)
+		}
+	}
 	output, err := r.run("show", "--no-notes", "--no-patch", "--format=%H%n%h%n%P%n%p%n%s")
 	if err != nil {
 		return Commit{}, fmt.Errorf("get commit details: %w", err)
@@ -163,6 +266,32 @@ func (r *CmdRepo) CreateCommit(message string) (Commit, error) {
 	}, nil
 }
 
+// signHeadCommit replaces the HEAD commit with a signed copy of it.
+// The signing is done by jelease instead of Git, so that neither GPG
+// nor ssh-keygen needs to be installed.
+func (r *CmdRepo) signHeadCommit() error {
+	unsigned, err := r.run("cat-file", "commit", "HEAD")
+	if err != nil {
+		return err
+	}
+	signature, err := r.Committer.Signer.Sign(bytes.NewReader(unsigned))
+	if err != nil {
+		return err
+	}
+	signed, err := addSignatureHeader(unsigned, signature)
+	if err != nil {
+		return err
+	}
+	output, err := runGitCmdWithInput(signed, "-C", r.directory, "hash-object", "-t", "commit", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	if _, err := r.run("update-ref", "HEAD", strings.TrimSpace(string(output))); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *CmdRepo) PushChanges() error {
 	_, err := r.run("push", "--set-upstream", "origin", r.currentBranch)
 	if err != nil {
@@ -171,6 +300,63 @@ func (r *CmdRepo) PushChanges() error {
 	return nil
 }
 
+func (r *CmdRepo) RemoteBranchHash(branchName string) (string, error) {
+	output, err := r.run("ls-remote", "--heads", "origin", "refs/heads/"+branchName)
+	if err != nil {
+		return "", fmt.Errorf("list remote branch: %w", err)
+	}
+	for line := range strings.Lines(string(output)) {
+		hash, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok && ref == "refs/heads/"+branchName {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrRemoteBranchNotFound, branchName)
+}
+
+func (r *CmdRepo) ForcePushChanges(expectedHash string) error {
+	lease := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", r.currentBranch, expectedHash)
+	_, err := r.run("push", "--set-upstream", lease, "origin", r.currentBranch)
+	if err != nil {
+		return fmt.Errorf("force push changes: %w", err)
+	}
+	return nil
+}
+
+func (r *CmdRepo) ChangedFiles(commit Commit) ([]ChangedFile, error) {
+	output, err := r.run("diff-tree", "-r", "-z", "--no-renames", commit.ParentHash, commit.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	// Each change is in the format:
+	//   :<old mode> <new mode> <old hash> <new hash> <status>\0<path>\0
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("list changed files: unexpected output: %q", output)
+	}
+	var files []ChangedFile
+	for i := 0; i < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) != 5 {
+			return nil, fmt.Errorf("list changed files: unexpected output: %q", fields[i])
+		}
+		file := ChangedFile{Path: fields[i+1]}
+		if meta[4] == "D" {
+			file.Mode = meta[0]
+			file.Deleted = true
+		} else {
+			file.Mode = meta[1]
+			content, err := r.run("cat-file", "blob", meta[3])
+			if err != nil {
+				return nil, fmt.Errorf("read changed file: %s: %w", file.Path, err)
+			}
+			file.Content = content
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
 func (r *CmdRepo) Close() error {
 	return os.RemoveAll(r.directory)
 }
@@ -187,10 +373,17 @@ func runAsCommitterInDir(committer Committer, targetDir string, args ...string)
 }
 
 func runGitCmd(args ...string) ([]byte, error) {
+	return runGitCmdWithInput(nil, args...)
+}
+
+func runGitCmdWithInput(input []byte, args ...string) ([]byte, error) {
 	if log.Logger.GetLevel() <= zerolog.DebugLevel {
 		log.Trace().Strs("args", censorArgs(args)).Msg("Executing Git command.")
 	}
 	cmd := exec.Command("git", args...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		var exitErr *exec.ExitError
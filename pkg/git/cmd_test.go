@@ -17,7 +17,12 @@
 
 package git
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestAddCredentialsToURL(t *testing.T) {
 	tests := []struct {
@@ -68,3 +73,47 @@ func TestAddCredentialsToURL(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdRepoCreateCommit_noChanges(t *testing.T) {
+	committer := Committer{Name: "Jelease test", Email: "jelease@example.com"}
+	remote := t.TempDir()
+	if _, err := runAsCommitterInDir(committer, remote, "init", "--initial-branch=main"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(remote, "file.txt"), []byte("v1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runAsCommitterInDir(committer, remote, "add", "--all"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runAsCommitterInDir(committer, remote, "commit", "-m", "Initial commit", "--no-gpg-sign"); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := Cmd{Committer: committer}.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	if err := repo.StageChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateCommit("Update to v1.0.0"); !errors.Is(err, ErrNoChanges) {
+		t.Fatalf("want ErrNoChanges, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo.Directory(), "file.txt"), []byte("v2.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StageChanges(); err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CreateCommit("Update to v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.Subject != "Update to v2.0.0" {
+		t.Errorf("want subject %q, got %q", "Update to v2.0.0", commit.Subject)
+	}
+}
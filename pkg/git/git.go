@@ -18,13 +18,37 @@
 package git
 
 import (
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 )
 
+// ErrNoChanges is returned by [Repo.CreateCommit] when there are no staged
+// changes to commit.
+var ErrNoChanges = errors.New("no changes to commit")
+
+// ErrRemoteBranchNotFound is returned by [Repo.RemoteBranchHash] when the
+// branch does not exist on the remote.
+var ErrRemoteBranchNotFound = errors.New("remote branch not found")
+
 type Git interface {
+	// Clone clones the remote's default branch.
 	Clone(targetDir, remote string) (Repo, error)
+	// CloneWithOptions clones the remote, with options such as which
+	// branch to clone.
+	CloneWithOptions(targetDir, remote string, opts CloneOptions) (Repo, error)
+}
+
+type CloneOptions struct {
+	// Branch is the branch to clone, which then becomes the repo's main
+	// branch. Clones the remote's default branch if empty.
+	Branch string
+	// SparsePaths are the directories to check out, using a sparse checkout.
+	// Files in the root directory are always checked out, so an empty
+	// non-nil slice checks out only the root directory.
+	// Checks out the whole repository if nil.
+	SparsePaths []string
 }
 
 type Repo interface {
@@ -39,16 +63,29 @@ type Repo interface {
 	StageChanges() error
 	CreateCommit(message string) (Commit, error)
 	PushChanges() error
+	// RemoteBranchHash returns the commit hash of a branch on the remote,
+	// or [ErrRemoteBranchNotFound] if it doesn't exist.
+	RemoteBranchHash(branchName string) (string, error)
+	// ForcePushChanges pushes the current branch, overwriting the remote
+	// branch, but only if it still points at the expected commit hash.
+	ForcePushChanges(expectedHash string) error
+	// ChangedFiles returns the files changed by the commit, compared to
+	// its parent commit.
+	ChangedFiles(commit Commit) ([]ChangedFile, error)
 }
 
 type Credentials struct {
 	Username string
 	Password string
+	// SSH is used instead of the username and password for SSH remotes.
+	SSH *SSHCredentials
 }
 
 type Committer struct {
 	Name  string // maps to Git config user.name
 	Email string // maps to Git config user.email
+	// Signer is used to sign the commits. Commits are not signed if nil.
+	Signer Signer
 }
 
 type Commit struct {
@@ -64,7 +101,17 @@ func (c Commit) String() string {
 	return c.Hash
 }
 
-func CloneTemp(g Git, tmpDirPattern, remote string) (Repo, error) {
+// ChangedFile is a file that was added, modified, or deleted by a commit.
+type ChangedFile struct {
+	Path string
+	// Mode is the Git file mode, such as "100644" or "100755".
+	// For deleted files, it's the mode the file had before.
+	Mode    string
+	Content []byte
+	Deleted bool
+}
+
+func CloneTemp(g Git, tmpDirPattern, remote string, opts CloneOptions) (Repo, error) {
 	parentDir, filePattern := filepath.Split(tmpDirPattern)
 	if err := os.MkdirAll(parentDir, 0700); err != nil {
 		return nil, err
@@ -73,5 +120,5 @@ func CloneTemp(g Git, tmpDirPattern, remote string) (Repo, error) {
 	if err != nil {
 		return nil, err
 	}
-	return g.Clone(tmpDir, remote)
+	return g.CloneWithOptions(tmpDir, remote, opts)
 }
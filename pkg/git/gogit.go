@@ -0,0 +1,593 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/rs/zerolog/log"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// GoGit implements [Git] using the pure-Go library go-git, so it does not
+// need the command-line version of Git to be installed.
+type GoGit struct {
+	Credentials Credentials
+	Committer   Committer
+}
+
+var _ Git = GoGit{}
+
+func (g GoGit) auth(remote string) (transport.AuthMethod, error) {
+	if IsSSHRemote(remote) {
+		return goGitSSHAuth(remote, g.Credentials.SSH)
+	}
+	if g.Credentials.Username == "" && g.Credentials.Password == "" {
+		return nil, nil
+	}
+	return &http.BasicAuth{
+		Username: g.Credentials.Username,
+		Password: g.Credentials.Password,
+	}, nil
+}
+
+func (g GoGit) Clone(targetDir, remote string) (Repo, error) {
+	return g.CloneWithOptions(targetDir, remote, CloneOptions{})
+}
+
+// CloneWithOptions clones the remote. Go-git does not support partial
+// clones, so with sparse paths all files are still fetched, but only the
+// sparse paths are checked out.
+func (g GoGit) CloneWithOptions(targetDir, remote string, opts CloneOptions) (Repo, error) {
+	log.Debug().Str("dir", targetDir).Str("remote", remote).
+		Str("branch", opts.Branch).Strs("sparse", opts.SparsePaths).
+		Msg("Cloning into dir")
+	auth, err := g.auth(remote)
+	if err != nil {
+		return nil, fmt.Errorf("clone repo: %w", err)
+	}
+	var refName plumbing.ReferenceName
+	if opts.Branch != "" {
+		refName = plumbing.NewBranchReferenceName(opts.Branch)
+	}
+	repo, err := gogit.PlainClone(targetDir, false, &gogit.CloneOptions{
+		URL:           remote,
+		Auth:          auth,
+		ReferenceName: refName,
+		SingleBranch:  true,
+		Depth:         1,
+		NoCheckout:    opts.SparsePaths != nil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("clone repo: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("check current branch: %w", err)
+	}
+	if opts.SparsePaths != nil {
+		if err := goGitSparseCheckout(repo, head, opts.SparsePaths); err != nil {
+			return nil, fmt.Errorf("sparse checkout: %w", err)
+		}
+	}
+	branchName := head.Name().Short()
+	return &GoGitRepo{
+		Committer:     g.Committer,
+		auth:          auth,
+		repo:          repo,
+		directory:     targetDir,
+		currentBranch: branchName,
+		mainBranch:    branchName,
+	}, nil
+}
+
+// goGitSparseCheckout checks out only the sparse paths and the files in the
+// root directory, the same as Git's sparse checkout in cone mode.
+func goGitSparseCheckout(repo *gogit.Repository, head *plumbing.Reference, sparsePaths []string) error {
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+	// Go-git matches by prefix, so the trailing slash avoids
+	// also matching "foo/bar-baz" when given "foo/bar"
+	var patterns []string
+	for _, p := range sparsePaths {
+		patterns = append(patterns, strings.TrimSuffix(p, "/")+"/")
+	}
+	for _, entry := range tree.Entries {
+		if entry.Mode.IsFile() {
+			patterns = append(patterns, entry.Name)
+		}
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	return wt.Checkout(&gogit.CheckoutOptions{
+		Branch:                    head.Name(),
+		SparseCheckoutDirectories: patterns,
+	})
+}
+
+type GoGitRepo struct {
+	Committer     Committer
+	auth          transport.AuthMethod
+	repo          *gogit.Repository
+	directory     string
+	currentBranch string
+	mainBranch    string
+}
+
+var _ Repo = &GoGitRepo{}
+
+func (r *GoGitRepo) Directory() string {
+	return r.directory
+}
+
+func (r *GoGitRepo) CurrentBranch() string {
+	return r.currentBranch
+}
+
+func (r *GoGitRepo) MainBranch() string {
+	return r.mainBranch
+}
+
+func (r *GoGitRepo) CheckoutNewBranch(branchName string) error {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("checkout branch: %s: %w", branchName, err)
+	}
+	if err := wt.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(branchName),
+		Create: true,
+		Keep:   true,
+	}); err != nil {
+		return fmt.Errorf("checkout branch: %s: %w", branchName, err)
+	}
+	r.currentBranch = branchName
+	return nil
+}
+
+func (r *GoGitRepo) DiffChanges() (string, error) {
+	diff, err := r.diff(false)
+	if err != nil {
+		return "", fmt.Errorf("diff changes: %w", err)
+	}
+	return diff, nil
+}
+
+func (r *GoGitRepo) DiffStaged() (string, error) {
+	diff, err := r.diff(true)
+	if err != nil {
+		return "", fmt.Errorf("diff changes: %w", err)
+	}
+	return diff, nil
+}
+
+func (r *GoGitRepo) StageChanges() error {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("stage all changes: %w", err)
+	}
+	if err := wt.AddWithOptions(&gogit.AddOptions{All: true}); err != nil {
+		return fmt.Errorf("stage all changes: %w", err)
+	}
+	return nil
+}
+
+func (r *GoGitRepo) CreateCommit(message string) (Commit, error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return Commit{}, fmt.Errorf("commit changes: %w", err)
+	}
+	diff, err := r.DiffStaged()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed diffing changes. Trying to continue anyways.")
+		diff = "# Failed to diff. See console output"
+	}
+	var author *object.Signature
+	if r.Committer.Name != "" || r.Committer.Email != "" {
+		author = &object.Signature{
+			Name:  r.Committer.Name,
+			Email: r.Committer.Email,
+			When:  time.Now(),
+		}
+	}
+	hash, err := wt.Commit(message, &gogit.CommitOptions{
+		Author: author,
+		Signer: r.Committer.Signer,
+	})
+	if errors.Is(err, gogit.ErrEmptyCommit) {
+		return Commit{}, ErrNoChanges
+	}
+	if err != nil {
+		return Commit{}, fmt.Errorf("commit changes: %w", err)
+	}
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return Commit{}, fmt.Errorf("get commit details: %w", err)
+	}
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	result := Commit{
+		Hash:     hash.String(),
+		AbbrHash: abbrevHash(hash),
+		Subject:  subject,
+		Diff:     diff,
+	}
+	if len(commit.ParentHashes) > 0 {
+		result.ParentHash = commit.ParentHashes[0].String()
+		result.ParentAbbrHash = abbrevHash(commit.ParentHashes[0])
+	}
+	return result, nil
+}
+
+func abbrevHash(hash plumbing.Hash) string {
+	return hash.String()[:7]
+}
+
+func (r *GoGitRepo) PushChanges() error {
+	branchRef := plumbing.NewBranchReferenceName(r.currentBranch)
+	err := r.repo.Push(&gogit.PushOptions{
+		RemoteName: gogit.DefaultRemoteName,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec(branchRef + ":" + branchRef)},
+		Auth:       r.auth,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("push changes: %w", err)
+	}
+	return nil
+}
+
+func (r *GoGitRepo) RemoteBranchHash(branchName string) (string, error) {
+	remote, err := r.repo.Remote(gogit.DefaultRemoteName)
+	if err != nil {
+		return "", fmt.Errorf("list remote branch: %w", err)
+	}
+	refs, err := remote.List(&gogit.ListOptions{Auth: r.auth})
+	if err != nil {
+		return "", fmt.Errorf("list remote branch: %w", err)
+	}
+	branchRef := plumbing.NewBranchReferenceName(branchName)
+	for _, ref := range refs {
+		if ref.Name() == branchRef {
+			return ref.Hash().String(), nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrRemoteBranchNotFound, branchName)
+}
+
+func (r *GoGitRepo) ForcePushChanges(expectedHash string) error {
+	branchRef := plumbing.NewBranchReferenceName(r.currentBranch)
+	hash := plumbing.NewHash(expectedHash)
+	// go-git requires the remote-tracking branch to exist when pushing
+	// with a lease, even though the expected hash is given explicitly.
+	remoteRef := plumbing.NewRemoteReferenceName(gogit.DefaultRemoteName, r.currentBranch)
+	if err := r.repo.Storer.SetReference(plumbing.NewHashReference(remoteRef, hash)); err != nil {
+		return fmt.Errorf("force push changes: %w", err)
+	}
+	err := r.repo.Push(&gogit.PushOptions{
+		RemoteName: gogit.DefaultRemoteName,
+		RefSpecs:   []gitconfig.RefSpec{gitconfig.RefSpec("+" + branchRef + ":" + branchRef)},
+		Auth:       r.auth,
+		ForceWithLease: &gogit.ForceWithLease{
+			RefName: branchRef,
+			Hash:    hash,
+		},
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("force push changes: %w", err)
+	}
+	return nil
+}
+
+func (r *GoGitRepo) ChangedFiles(commit Commit) ([]ChangedFile, error) {
+	tree, err := r.commitTree(commit.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	parentTree, err := r.commitTree(commit.ParentHash)
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("list changed files: %w", err)
+	}
+	var files []ChangedFile
+	for _, change := range changes {
+		from, to, err := change.Files()
+		if err != nil {
+			return nil, fmt.Errorf("read changed file: %s: %w", change, err)
+		}
+		if to == nil {
+			files = append(files, ChangedFile{
+				Path:    change.From.Name,
+				Mode:    gitFileMode(from.Mode),
+				Deleted: true,
+			})
+			continue
+		}
+		content, err := to.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("read changed file: %s: %w", change.To.Name, err)
+		}
+		files = append(files, ChangedFile{
+			Path:    change.To.Name,
+			Mode:    gitFileMode(to.Mode),
+			Content: []byte(content),
+		})
+	}
+	return files, nil
+}
+
+func (r *GoGitRepo) commitTree(hash string) (*object.Tree, error) {
+	commit, err := r.repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, err
+	}
+	return commit.Tree()
+}
+
+// gitFileMode formats the mode the same way as Git, e.g "100644",
+// as go-git pads it with an extra zero.
+func gitFileMode(mode filemode.FileMode) string {
+	return fmt.Sprintf("%06o", uint32(mode))
+}
+
+func (r *GoGitRepo) Close() error {
+	return os.RemoveAll(r.directory)
+}
+
+// diff creates a unified diff of either the staged changes (HEAD vs index),
+// or the unstaged changes (index vs worktree), like `git diff [--staged]`.
+// Untracked files are not included.
+func (r *GoGitRepo) diff(staged bool) (string, error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", err
+	}
+	idx, err := r.repo.Storer.Index()
+	if err != nil {
+		return "", err
+	}
+	head, err := r.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	headCommit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return "", err
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+
+	paths := make([]string, 0, len(status))
+	for path := range status {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	var patch goGitPatch
+	for _, path := range paths {
+		fileStatus := status[path]
+		var from, to *goGitDiffFile
+		if staged {
+			if fileStatus.Staging == gogit.Unmodified || fileStatus.Staging == gogit.Untracked {
+				continue
+			}
+			if from, err = r.headFile(headTree, path); err != nil {
+				return "", err
+			}
+			if to, err = r.indexFile(idx.Entries, path); err != nil {
+				return "", err
+			}
+		} else {
+			if fileStatus.Worktree == gogit.Unmodified || fileStatus.Worktree == gogit.Untracked {
+				continue
+			}
+			if from, err = r.indexFile(idx.Entries, path); err != nil {
+				return "", err
+			}
+			if to, err = r.worktreeFile(path); err != nil {
+				return "", err
+			}
+		}
+		patch = append(patch, newGoGitFilePatch(from, to))
+	}
+
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (r *GoGitRepo) headFile(tree *object.Tree, path string) (*goGitDiffFile, error) {
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return &goGitDiffFile{path: path, mode: file.Mode, hash: file.Hash, content: content}, nil
+}
+
+func (r *GoGitRepo) indexFile(entries []*index.Entry, path string) (*goGitDiffFile, error) {
+	for _, entry := range entries {
+		if entry.Name != path {
+			continue
+		}
+		blob, err := r.repo.BlobObject(entry.Hash)
+		if err != nil {
+			return nil, err
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		return &goGitDiffFile{path: path, mode: entry.Mode, hash: entry.Hash, content: string(content)}, nil
+	}
+	return nil, nil
+}
+
+func (r *GoGitRepo) worktreeFile(path string) (*goGitDiffFile, error) {
+	absPath := filepath.Join(r.directory, path)
+	stat, err := os.Lstat(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var content []byte
+	if stat.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(absPath)
+		if err != nil {
+			return nil, err
+		}
+		content = []byte(target)
+	} else if content, err = os.ReadFile(absPath); err != nil {
+		return nil, err
+	}
+	mode, err := filemode.NewFromOSFileMode(stat.Mode())
+	if err != nil {
+		return nil, err
+	}
+	return &goGitDiffFile{
+		path:    path,
+		mode:    mode,
+		hash:    plumbing.ComputeHash(plumbing.BlobObject, content),
+		content: string(content),
+	}, nil
+}
+
+// goGitPatch implements go-git's [fdiff.Patch], so diffs can be encoded
+// using go-git's unified diff encoder.
+type goGitPatch []fdiff.FilePatch
+
+func (p goGitPatch) FilePatches() []fdiff.FilePatch { return p }
+func (p goGitPatch) Message() string                { return "" }
+
+type goGitFilePatch struct {
+	from, to *goGitDiffFile
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+func newGoGitFilePatch(from, to *goGitDiffFile) *goGitFilePatch {
+	var fromContent, toContent string
+	if from != nil {
+		fromContent = from.content
+	}
+	if to != nil {
+		toContent = to.content
+	}
+	patch := &goGitFilePatch{from: from, to: to}
+	if isBinary(fromContent) || isBinary(toContent) {
+		patch.binary = true
+		return patch
+	}
+	for _, d := range diff.Do(fromContent, toContent) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		patch.chunks = append(patch.chunks, goGitChunk{content: d.Text, op: op})
+	}
+	return patch
+}
+
+// isBinary uses the same heuristic as Git, by looking for a NUL byte
+// in the first 8000 bytes.
+func isBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), 8000)], 0) != -1
+}
+
+func (p *goGitFilePatch) IsBinary() bool        { return p.binary }
+func (p *goGitFilePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+func (p *goGitFilePatch) Files() (from, to fdiff.File) {
+	// Avoid returning typed nil pointers, as the encoder checks for nil
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+type goGitDiffFile struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content string
+}
+
+func (f *goGitDiffFile) Hash() plumbing.Hash     { return f.hash }
+func (f *goGitDiffFile) Mode() filemode.FileMode { return f.mode }
+func (f *goGitDiffFile) Path() string            { return f.path }
+
+type goGitChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c goGitChunk) Content() string       { return c.content }
+func (c goGitChunk) Type() fdiff.Operation { return c.op }
@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// errLocked is returned by [lockFile] when not waiting for a lock
+// that is already taken.
+var errLocked = errors.New("file is locked")
+
+// MirrorCache keeps a bare mirror of each remote repository on disk, so that
+// cloning only needs to fetch what changed since the last time. The working
+// copies are then created as local clones of the mirrors.
+//
+// Each mirror is locked while in use, so the cache can be shared between
+// multiple processes.
+type MirrorCache struct {
+	Dir string
+	// MaxAge evicts mirrors that have not been used for this long.
+	// Zero means no age limit.
+	MaxAge time.Duration
+	// MaxSize is the total size in bytes of all mirrors. The least recently
+	// used mirrors are evicted when it's exceeded. Zero means no size limit.
+	MaxSize int64
+}
+
+const mirrorSuffix = ".git"
+
+var mirrorNameUnsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// mirrorName returns the directory name of a remote's mirror, which is
+// somewhat readable, while the hash keeps it unique.
+func mirrorName(remote string) string {
+	readable := remote
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		readable = u.Host + u.Path
+	}
+	readable = strings.TrimSuffix(readable, ".git")
+	readable = strings.Trim(mirrorNameUnsafeChars.ReplaceAllString(readable, "_"), "_.")
+	if len(readable) > 80 {
+		readable = readable[len(readable)-80:]
+	}
+	sum := sha256.Sum256([]byte(remote))
+	return readable + "-" + hex.EncodeToString(sum[:4]) + mirrorSuffix
+}
+
+// cloneViaMirror fetches the remote into its mirror, and then clones the
+// mirror into the target directory. The clone's "origin" points at the
+// remote, so pushing works the same as with a regular clone.
+func (g Cmd) cloneViaMirror(targetDir string, r cmdRemote, opts CloneOptions) error {
+	m := g.Mirror
+	if err := os.MkdirAll(m.Dir, 0700); err != nil {
+		return fmt.Errorf("create mirror cache dir: %w", err)
+	}
+	mirrorDir := filepath.Join(m.Dir, mirrorName(r.url))
+	unlock, err := lockFile(mirrorDir+".lock", true)
+	if err != nil {
+		return fmt.Errorf("lock mirror: %w", err)
+	}
+	defer unlock()
+
+	if err := updateMirror(mirrorDir, r); err != nil {
+		return err
+	}
+	if _, err := runGitCmd(append([]string{"clone", "--single-branch"}, cloneOptionArgs(opts, mirrorDir, targetDir)...)...); err != nil {
+		// Fetching can succeed even though some objects are broken
+		log.Warn().Err(err).Str("mirror", mirrorDir).Msg("Failed to clone from mirror. Re-cloning mirror.")
+		if err := os.RemoveAll(targetDir); err != nil {
+			return err
+		}
+		if err := recloneMirror(mirrorDir, r); err != nil {
+			return err
+		}
+		if _, err := runGitCmd(append([]string{"clone", "--single-branch"}, cloneOptionArgs(opts, mirrorDir, targetDir)...)...); err != nil {
+			return fmt.Errorf("clone from mirror: %w", err)
+		}
+	}
+	if _, err := runGitCmd("-C", targetDir, "remote", "set-url", "origin", r.urlWithCred); err != nil {
+		return fmt.Errorf("set remote URL: %w", err)
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(mirrorDir, now, now); err != nil {
+		log.Warn().Err(err).Str("mirror", mirrorDir).Msg("Failed to mark mirror as used.")
+	}
+	if err := m.evict(mirrorDir); err != nil {
+		log.Warn().Err(err).Str("dir", m.Dir).Msg("Failed to evict old mirrors.")
+	}
+	return nil
+}
+
+// updateMirror fetches the latest branches into the mirror, or clones it
+// if it doesn't exist. If fetching fails, the mirror is assumed to be
+// corrupt and is cloned again.
+func updateMirror(mirrorDir string, r cmdRemote) error {
+	if _, err := os.Stat(mirrorDir); errors.Is(err, fs.ErrNotExist) {
+		log.Debug().Str("mirror", mirrorDir).Str("remote", r.url).Msg("Cloning new mirror.")
+		return cloneMirror(mirrorDir, r)
+	}
+	log.Debug().Str("mirror", mirrorDir).Str("remote", r.url).Msg("Fetching into mirror.")
+	// Credentials are only passed on the command line,
+	// so they're never stored on disk
+	_, err := r.git("-C", mirrorDir, "fetch", "--prune", "--", r.urlWithCred, "+refs/heads/*:refs/heads/*")
+	if err != nil {
+		log.Warn().Err(err).Str("mirror", mirrorDir).Msg("Failed to fetch into mirror. Re-cloning mirror.")
+		return recloneMirror(mirrorDir, r)
+	}
+	return nil
+}
+
+func recloneMirror(mirrorDir string, r cmdRemote) error {
+	if err := os.RemoveAll(mirrorDir); err != nil {
+		return fmt.Errorf("remove broken mirror: %w", err)
+	}
+	return cloneMirror(mirrorDir, r)
+}
+
+// cloneMirror clones into a temporary directory first, so that a failed
+// clone never leaves a half-done mirror behind.
+func cloneMirror(mirrorDir string, r cmdRemote) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(mirrorDir), filepath.Base(mirrorDir)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("clone mirror: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	if _, err := r.git("clone", "--bare", "--", r.urlWithCred, tmpDir); err != nil {
+		return fmt.Errorf("clone mirror: %w", err)
+	}
+	if _, err := runGitCmd("-C", tmpDir, "remote", "set-url", "origin", r.url); err != nil {
+		return fmt.Errorf("clone mirror: set remote URL: %w", err)
+	}
+	if err := os.Rename(tmpDir, mirrorDir); err != nil {
+		return fmt.Errorf("clone mirror: %w", err)
+	}
+	return nil
+}
+
+type mirrorInfo struct {
+	dir      string
+	lastUsed time.Time
+	size     int64
+}
+
+// evict removes mirrors that are older than the max age, followed by the
+// least recently used mirrors until the total size is below the max size.
+// The mirror in the keep directory is never removed, and neither are
+// mirrors that are locked by someone else.
+func (m *MirrorCache) evict(keep string) error {
+	if m.MaxAge <= 0 && m.MaxSize <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		return err
+	}
+	var mirrors []mirrorInfo
+	var totalSize int64
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), mirrorSuffix) {
+			continue
+		}
+		dir := filepath.Join(m.Dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size, err := dirSize(dir)
+		if err != nil {
+			return err
+		}
+		mirrors = append(mirrors, mirrorInfo{dir: dir, lastUsed: info.ModTime(), size: size})
+		totalSize += size
+	}
+	// Oldest first
+	slices.SortFunc(mirrors, func(a, b mirrorInfo) int {
+		return a.lastUsed.Compare(b.lastUsed)
+	})
+
+	now := time.Now()
+	for _, mirror := range mirrors {
+		tooOld := m.MaxAge > 0 && now.Sub(mirror.lastUsed) > m.MaxAge
+		tooBig := m.MaxSize > 0 && totalSize > m.MaxSize
+		if !tooOld && !tooBig {
+			continue
+		}
+		if mirror.dir == keep {
+			continue
+		}
+		removed, err := removeMirrorUnlessLocked(mirror.dir)
+		if err != nil {
+			return err
+		}
+		if removed {
+			log.Debug().Str("mirror", mirror.dir).
+				Time("lastUsed", mirror.lastUsed).
+				Int64("size", mirror.size).
+				Msg("Evicted mirror from cache.")
+			totalSize -= mirror.size
+		}
+	}
+	return nil
+}
+
+func removeMirrorUnlessLocked(mirrorDir string) (bool, error) {
+	unlock, err := lockFile(mirrorDir+".lock", false)
+	if errors.Is(err, errLocked) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if err := os.RemoveAll(mirrorDir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
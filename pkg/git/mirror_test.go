@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// pushTestCommit adds a commit on top of the remote's "main" branch.
+func pushTestCommit(t *testing.T, remote, path, content string) string {
+	t.Helper()
+	work := filepath.Join(t.TempDir(), "work")
+	if _, err := runGitCmd("clone", "--", remote, work); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(work, path), content)
+	for _, args := range [][]string{
+		{"add", "--all"},
+		{"commit", "-m", "Update " + path, "--no-gpg-sign"},
+		{"push", "origin", "main"},
+	} {
+		if _, err := runAsCommitterInDir(testCommitter, work, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return revParse(t, remote, "main")
+}
+
+func cloneViaTestMirror(t *testing.T, g Cmd, remote string) Repo {
+	t.Helper()
+	repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestMirrorCache_fetchesIncrementally(t *testing.T) {
+	remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+	g := Cmd{Committer: testCommitter, Mirror: &MirrorCache{Dir: t.TempDir()}}
+
+	repo := cloneViaTestMirror(t, g, remote)
+	mirrorDir := filepath.Join(g.Mirror.Dir, mirrorName(remote))
+	if got, want := revParse(t, mirrorDir, "main"), revParse(t, remote, "main"); got != want {
+		t.Errorf("want mirror at %q, got %q", want, got)
+	}
+	repo.Close()
+
+	newHash := pushTestCommit(t, remote, "file.txt", "v2.0.0\n")
+	repo = cloneViaTestMirror(t, g, remote)
+	if got := revParse(t, filepath.Join(repo.Directory(), ".git"), "HEAD"); got != newHash {
+		t.Errorf("want clone at new commit %q, got %q", newHash, got)
+	}
+	if got := revParse(t, mirrorDir, "main"); got != newHash {
+		t.Errorf("want mirror at new commit %q, got %q", newHash, got)
+	}
+}
+
+func TestMirrorCache_pushesToRemote(t *testing.T) {
+	remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+	g := Cmd{Committer: testCommitter, Mirror: &MirrorCache{Dir: t.TempDir()}}
+	repo := cloneViaTestMirror(t, g, remote)
+
+	if err := repo.CheckoutNewBranch("update"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+	if err := repo.StageChanges(); err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CreateCommit("Update to v2.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PushChanges(); err != nil {
+		t.Fatal(err)
+	}
+	if got := revParse(t, remote, "update"); got != commit.Hash {
+		t.Errorf("want pushed commit %q on remote, got %q", commit.Hash, got)
+	}
+}
+
+func TestMirrorCache_recoversFromCorruption(t *testing.T) {
+	remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+	g := Cmd{Committer: testCommitter, Mirror: &MirrorCache{Dir: t.TempDir()}}
+	cloneViaTestMirror(t, g, remote).Close()
+
+	mirrorDir := filepath.Join(g.Mirror.Dir, mirrorName(remote))
+	if err := os.RemoveAll(filepath.Join(mirrorDir, "objects")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mirrorDir, "HEAD"), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := cloneViaTestMirror(t, g, remote)
+	if got, want := revParse(t, filepath.Join(repo.Directory(), ".git"), "HEAD"), revParse(t, remote, "main"); got != want {
+		t.Errorf("want clone at %q, got %q", want, got)
+	}
+	if got, want := revParse(t, mirrorDir, "main"), revParse(t, remote, "main"); got != want {
+		t.Errorf("want re-cloned mirror at %q, got %q", want, got)
+	}
+}
+
+func TestMirrorCache_evict(t *testing.T) {
+	newMirror := func(t *testing.T, dir, name string, size int, lastUsed time.Time) string {
+		t.Helper()
+		mirrorDir := filepath.Join(dir, name+mirrorSuffix)
+		writeTestFile(t, filepath.Join(mirrorDir, "objects", "pack"), string(make([]byte, size)))
+		if err := os.Chtimes(mirrorDir, lastUsed, lastUsed); err != nil {
+			t.Fatal(err)
+		}
+		return mirrorDir
+	}
+	assertExists := func(t *testing.T, dir string, want bool) {
+		t.Helper()
+		_, err := os.Stat(dir)
+		if exists := !errors.Is(err, os.ErrNotExist); exists != want {
+			t.Errorf("want %s exists=%t, got exists=%t", filepath.Base(dir), want, exists)
+		}
+	}
+	now := time.Now()
+
+	t.Run("maxAge", func(t *testing.T) {
+		m := MirrorCache{Dir: t.TempDir(), MaxAge: time.Hour}
+		old := newMirror(t, m.Dir, "old", 10, now.Add(-2*time.Hour))
+		recent := newMirror(t, m.Dir, "recent", 10, now.Add(-time.Minute))
+		oldButKept := newMirror(t, m.Dir, "kept", 10, now.Add(-2*time.Hour))
+		if err := m.evict(oldButKept); err != nil {
+			t.Fatal(err)
+		}
+		assertExists(t, old, false)
+		assertExists(t, recent, true)
+		assertExists(t, oldButKept, true)
+	})
+
+	t.Run("maxSize", func(t *testing.T) {
+		m := MirrorCache{Dir: t.TempDir(), MaxSize: 250}
+		oldest := newMirror(t, m.Dir, "oldest", 100, now.Add(-3*time.Hour))
+		older := newMirror(t, m.Dir, "older", 100, now.Add(-2*time.Hour))
+		newest := newMirror(t, m.Dir, "newest", 100, now.Add(-time.Hour))
+		if err := m.evict(newest); err != nil {
+			t.Fatal(err)
+		}
+		assertExists(t, oldest, false)
+		assertExists(t, older, true)
+		assertExists(t, newest, true)
+	})
+
+	t.Run("skips locked", func(t *testing.T) {
+		m := MirrorCache{Dir: t.TempDir(), MaxAge: time.Hour}
+		locked := newMirror(t, m.Dir, "locked", 10, now.Add(-2*time.Hour))
+		unlock, err := lockFile(locked+".lock", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unlock()
+		if err := m.evict(""); err != nil {
+			t.Fatal(err)
+		}
+		assertExists(t, locked, true)
+	})
+}
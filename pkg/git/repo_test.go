@@ -0,0 +1,551 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testBackends returns all implementations of [Git], so that they're all
+// tested against the same test suite.
+func testBackends(committer Committer) map[string]Git {
+	return map[string]Git{
+		"cmd":   Cmd{Committer: committer},
+		"goGit": GoGit{Committer: committer},
+	}
+}
+
+var testCommitter = Committer{Name: "Jelease test", Email: "jelease@example.com"}
+
+// newTestRemote creates a bare repository with an initial commit on the
+// "main" branch, to be used as the remote when cloning.
+func newTestRemote(t *testing.T, files map[string]string) string {
+	t.Helper()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if _, err := runGitCmd("init", "--bare", "--initial-branch=main", remote); err != nil {
+		t.Fatal(err)
+	}
+	work := t.TempDir()
+	if _, err := runAsCommitterInDir(testCommitter, work, "init", "--initial-branch=main"); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range files {
+		writeTestFile(t, filepath.Join(work, path), content)
+	}
+	for _, args := range [][]string{
+		{"add", "--all"},
+		{"commit", "-m", "Initial commit", "--no-gpg-sign"},
+		{"push", remote, "main"},
+	} {
+		if _, err := runAsCommitterInDir(testCommitter, work, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return remote
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func revParse(t *testing.T, gitDir, rev string) string {
+	t.Helper()
+	out, err := runGitCmd("--git-dir", gitDir, "rev-parse", rev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestRepo_clone(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			if repo.MainBranch() != "main" {
+				t.Errorf("want main branch %q, got %q", "main", repo.MainBranch())
+			}
+			if repo.CurrentBranch() != "main" {
+				t.Errorf("want current branch %q, got %q", "main", repo.CurrentBranch())
+			}
+			content, err := os.ReadFile(filepath.Join(repo.Directory(), "file.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != "v1.0.0\n" {
+				t.Errorf("want cloned file content %q, got %q", "v1.0.0\n", content)
+			}
+
+			if err := repo.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(repo.Directory()); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("want directory to be removed on close, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestRepo_cloneBranch(t *testing.T) {
+	backends := testBackends(testCommitter)
+	backends["cmdMirror"] = Cmd{Committer: testCommitter, Mirror: &MirrorCache{Dir: t.TempDir()}}
+	for name, g := range backends {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			branchHash := pushTestBranch(t, g, remote, "release/1.x", "v1.1.0\n")
+
+			repo, err := g.CloneWithOptions(filepath.Join(t.TempDir(), "clone"), remote, CloneOptions{Branch: "release/1.x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			if repo.MainBranch() != "release/1.x" {
+				t.Errorf("want main branch %q, got %q", "release/1.x", repo.MainBranch())
+			}
+			content, err := os.ReadFile(filepath.Join(repo.Directory(), "file.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != "v1.1.0\n" {
+				t.Errorf("want cloned file content %q, got %q", "v1.1.0\n", content)
+			}
+
+			if err := repo.CheckoutNewBranch("jelease/release-1.x"); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v1.1.1\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v1.1.1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if commit.ParentHash != branchHash {
+				t.Errorf("want commit parent %q, got %q", branchHash, commit.ParentHash)
+			}
+		})
+	}
+}
+
+func TestRepo_cloneBranch_notFound(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			if _, err := g.CloneWithOptions(filepath.Join(t.TempDir(), "clone"), remote, CloneOptions{Branch: "release/9.x"}); err == nil {
+				t.Fatal("want error when cloning missing branch, got nil")
+			}
+		})
+	}
+}
+
+func TestRepo_sparseCheckout(t *testing.T) {
+	backends := testBackends(testCommitter)
+	backends["cmdMirror"] = Cmd{Committer: testCommitter, Mirror: &MirrorCache{Dir: t.TempDir()}}
+	for name, g := range backends {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{
+				"README.md":               "# Monorepo\n",
+				"deploy/helm/values.yaml": "tag: v1.0.0\n",
+				"src/main.go":             "package main\n",
+			})
+			// Allow partial clones, which needs the file:// protocol
+			if _, err := runGitCmd("--git-dir", remote, "config", "uploadpack.allowFilter", "true"); err != nil {
+				t.Fatal(err)
+			}
+			repo, err := g.CloneWithOptions(filepath.Join(t.TempDir(), "clone"), "file://"+filepath.ToSlash(remote), CloneOptions{
+				SparsePaths: []string{"deploy/helm"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			for path, wantExists := range map[string]bool{
+				"README.md":               true,
+				"deploy/helm/values.yaml": true,
+				"src/main.go":             false,
+			} {
+				_, err := os.Stat(filepath.Join(repo.Directory(), path))
+				if exists := err == nil; exists != wantExists {
+					t.Errorf("%s: want checked out %t, got %t", path, wantExists, exists)
+				}
+			}
+
+			if err := repo.CheckoutNewBranch("jelease/v2.0.0"); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(repo.Directory(), "deploy/helm/values.yaml"), "tag: v2.0.0\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(commit.Diff, "src/main.go") || strings.Contains(commit.Diff, "README.md") {
+				t.Errorf("want only the sparse path changed, got diff:\n%s", commit.Diff)
+			}
+			if err := repo.PushChanges(); err != nil {
+				t.Fatal(err)
+			}
+
+			out, err := runGitCmd("--git-dir", remote, "ls-tree", "-r", "--name-only", "refs/heads/jelease/v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantFiles := "README.md\ndeploy/helm/values.yaml\nsrc/main.go\n"
+			if string(out) != wantFiles {
+				t.Errorf("want pushed files:\n%s\ngot:\n%s", wantFiles, out)
+			}
+		})
+	}
+}
+
+func TestRepo_sparseCheckout_rootOnly(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{
+				"versions.lock": "v1.0.0\n",
+				"src/main.go":   "package main\n",
+			})
+			if _, err := runGitCmd("--git-dir", remote, "config", "uploadpack.allowFilter", "true"); err != nil {
+				t.Fatal(err)
+			}
+			repo, err := g.CloneWithOptions(filepath.Join(t.TempDir(), "clone"), "file://"+filepath.ToSlash(remote), CloneOptions{
+				SparsePaths: []string{},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			for path, wantExists := range map[string]bool{
+				"versions.lock": true,
+				"src/main.go":   false,
+			} {
+				_, err := os.Stat(filepath.Join(repo.Directory(), path))
+				if exists := err == nil; exists != wantExists {
+					t.Errorf("%s: want checked out %t, got %t", path, wantExists, exists)
+				}
+			}
+		})
+	}
+}
+
+func TestRepo_noChanges(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			// Same content, so no actual change
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v1.0.0\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := repo.CreateCommit("Update to v1.0.0"); !errors.Is(err, ErrNoChanges) {
+				t.Fatalf("want ErrNoChanges, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepo_diffAndCommit(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{
+				"file.txt":    "v1.0.0\n",
+				"removed.txt": "old\n",
+			})
+			initialHash := revParse(t, remote, "main")
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+			writeTestFile(t, filepath.Join(repo.Directory(), "sub", "added.txt"), "new\n")
+			if err := os.Remove(filepath.Join(repo.Directory(), "removed.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			changes, err := repo.DiffChanges()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertDiffContains(t, "unstaged", changes,
+				"--- a/file.txt", "+++ b/file.txt", "-v1.0.0", "+v2.0.0",
+				"--- a/removed.txt", "-old")
+			if strings.Contains(changes, "added.txt") {
+				t.Errorf("want untracked files to not be in unstaged diff, got:\n%s", changes)
+			}
+
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			staged, err := repo.DiffStaged()
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertDiffContains(t, "staged", staged,
+				"+v2.0.0", "-old", "+++ b/sub/added.txt", "+new")
+
+			commit, err := repo.CreateCommit("Update to v2.0.0\n\nSome description")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if commit.Subject != "Update to v2.0.0" {
+				t.Errorf("want subject %q, got %q", "Update to v2.0.0", commit.Subject)
+			}
+			if len(commit.Hash) != 40 || !strings.HasPrefix(commit.Hash, commit.AbbrHash) || len(commit.AbbrHash) < 7 {
+				t.Errorf("want full and abbreviated hash, got %q and %q", commit.Hash, commit.AbbrHash)
+			}
+			if commit.ParentHash != initialHash || !strings.HasPrefix(initialHash, commit.ParentAbbrHash) {
+				t.Errorf("want parent %q, got %q (%q)", initialHash, commit.ParentHash, commit.ParentAbbrHash)
+			}
+			assertDiffContains(t, "commit", commit.Diff, "+v2.0.0", "+new", "-old")
+
+			staged, err = repo.DiffStaged()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if staged != "" {
+				t.Errorf("want no staged changes after commit, got:\n%s", staged)
+			}
+		})
+	}
+}
+
+func TestRepo_pushNewBranch(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			initialHash := revParse(t, remote, "main")
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			if err := repo.CheckoutNewBranch("jelease/v2.0.0"); err != nil {
+				t.Fatal(err)
+			}
+			if repo.CurrentBranch() != "jelease/v2.0.0" {
+				t.Errorf("want current branch %q, got %q", "jelease/v2.0.0", repo.CurrentBranch())
+			}
+			if repo.MainBranch() != "main" {
+				t.Errorf("want main branch %q, got %q", "main", repo.MainBranch())
+			}
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.PushChanges(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := revParse(t, remote, "refs/heads/jelease/v2.0.0"); got != commit.Hash {
+				t.Errorf("want pushed branch at %q, got %q", commit.Hash, got)
+			}
+			if got := revParse(t, remote, "refs/heads/main"); got != initialHash {
+				t.Errorf("want main branch unchanged at %q, got %q", initialHash, got)
+			}
+			out, err := runGitCmd("--git-dir", remote, "log", "-1", "--format=%an <%ae>", "refs/heads/jelease/v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := strings.TrimSpace(string(out)), "Jelease test <jelease@example.com>"; got != want {
+				t.Errorf("want author %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestRepo_forcePushExistingBranch(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+			initialHash := revParse(t, remote, "main")
+			existingHash := pushTestBranch(t, g, remote, "jelease/v2.0.0", "v2.0.0\n")
+
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			if _, err := repo.RemoteBranchHash("jelease/v3.0.0"); !errors.Is(err, ErrRemoteBranchNotFound) {
+				t.Errorf("want ErrRemoteBranchNotFound for missing branch, got %v", err)
+			}
+			remoteHash, err := repo.RemoteBranchHash("jelease/v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if remoteHash != existingHash {
+				t.Errorf("want remote branch hash %q, got %q", existingHash, remoteHash)
+			}
+
+			if err := repo.CheckoutNewBranch("jelease/v2.0.0"); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0 rebuilt\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.PushChanges(); err == nil {
+				t.Error("want error when pushing to existing diverged branch without force")
+			}
+			if err := repo.ForcePushChanges(initialHash); err == nil {
+				t.Error("want error when force pushing with wrong lease")
+			}
+			if got := revParse(t, remote, "refs/heads/jelease/v2.0.0"); got != existingHash {
+				t.Errorf("want remote branch unchanged at %q, got %q", existingHash, got)
+			}
+			if err := repo.ForcePushChanges(remoteHash); err != nil {
+				t.Fatal(err)
+			}
+			if got := revParse(t, remote, "refs/heads/jelease/v2.0.0"); got != commit.Hash {
+				t.Errorf("want force pushed branch at %q, got %q", commit.Hash, got)
+			}
+		})
+	}
+}
+
+func TestRepo_changedFiles(t *testing.T) {
+	for name, g := range testBackends(testCommitter) {
+		t.Run(name, func(t *testing.T) {
+			remote := newTestRemote(t, map[string]string{
+				"file.txt":      "v1.0.0\n",
+				"removed.txt":   "old\n",
+				"unchanged.txt": "same\n",
+			})
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+			writeTestFile(t, filepath.Join(repo.Directory(), "sub", "script.sh"), "#!/bin/sh\n")
+			if err := os.Chmod(filepath.Join(repo.Directory(), "sub", "script.sh"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Remove(filepath.Join(repo.Directory(), "removed.txt")); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := repo.ChangedFiles(commit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []ChangedFile{
+				{Path: "file.txt", Mode: "100644", Content: []byte("v2.0.0\n")},
+				{Path: "removed.txt", Mode: "100644", Deleted: true},
+				{Path: "sub/script.sh", Mode: "100755", Content: []byte("#!/bin/sh\n")},
+			}
+			if len(files) != len(want) {
+				t.Fatalf("want %d changed files, got %d: %+v", len(want), len(files), files)
+			}
+			for i, file := range files {
+				if file.Path != want[i].Path || file.Mode != want[i].Mode ||
+					string(file.Content) != string(want[i].Content) || file.Deleted != want[i].Deleted {
+					t.Errorf("changed file #%d:\nwant: %+v\ngot:  %+v", i, want[i], file)
+				}
+			}
+		})
+	}
+}
+
+// pushTestBranch pushes a new branch with a single commit to the remote.
+func pushTestBranch(t *testing.T, g Git, remote, branchName, content string) string {
+	t.Helper()
+	repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	if err := repo.CheckoutNewBranch(branchName); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), content)
+	if err := repo.StageChanges(); err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CreateCommit("Existing commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.PushChanges(); err != nil {
+		t.Fatal(err)
+	}
+	return commit.Hash
+}
+
+func assertDiffContains(t *testing.T, name, diff string, lines ...string) {
+	t.Helper()
+	diffLines := strings.Split(diff, "\n")
+	for _, line := range lines {
+		found := false
+		for _, diffLine := range diffLines {
+			if diffLine == line {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("%s diff: want line %q, got:\n%s", name, line, diff)
+		}
+	}
+}
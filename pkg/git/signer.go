@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signer creates a detached signature of a commit, which is then stored in
+// the commit's "gpgsig" header. It's the same interface as go-git's Signer.
+type Signer interface {
+	Sign(message io.Reader) ([]byte, error)
+}
+
+// NewSSHSigner creates a [Signer] that signs commits using an SSH key,
+// the same way as Git does with the config "gpg.format=ssh".
+// Passphrase-protected keys are not supported.
+func NewSSHSigner(privateKeyPEM []byte) (Signer, error) {
+	signer, err := ssh.ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		var passErr *ssh.PassphraseMissingError
+		if errors.As(err, &passErr) {
+			return nil, errors.New("parse SSH signing key: passphrase-protected keys are not supported")
+		}
+		return nil, fmt.Errorf("parse SSH signing key: %w", err)
+	}
+	return sshSigner{signer: signer}, nil
+}
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+const (
+	sshSigMagic     = "SSHSIG"
+	sshSigNamespace = "git"
+	sshSigHashAlgo  = "sha512"
+)
+
+// Sign creates an armored signature in the SSHSIG format, as described in:
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, message); err != nil {
+		return nil, err
+	}
+	signedData := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSigNamespace, "", sshSigHashAlgo, hash.Sum(nil)})...)
+
+	var sig *ssh.Signature
+	var err error
+	if algSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// The SHA-1 based "ssh-rsa" algorithm is not allowed in SSHSIG
+		sig, err = algSigner.SignWithAlgorithm(rand.Reader, signedData, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = s.signer.Sign(rand.Reader, signedData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sign with SSH key: %w", err)
+	}
+
+	blob := append([]byte(sshSigMagic), ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, s.signer.PublicKey().Marshal(), sshSigNamespace, "", sshSigHashAlgo, ssh.Marshal(sig)})...)
+
+	var buf bytes.Buffer
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	for len(encoded) > 70 {
+		buf.WriteString(encoded[:70])
+		buf.WriteByte('\n')
+		encoded = encoded[70:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteString("\n-----END SSH SIGNATURE-----\n")
+	return buf.Bytes(), nil
+}
+
+// NewGPGSigner creates a [Signer] that signs commits using an armored
+// OpenPGP private key. Passphrase-protected keys are not supported.
+func NewGPGSigner(armoredPrivateKey []byte) (Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredPrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse GPG signing key: %w", err)
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, errors.New("parse GPG signing key: no private key found")
+	}
+	if entities[0].PrivateKey.Encrypted {
+		return nil, errors.New("parse GPG signing key: passphrase-protected keys are not supported")
+	}
+	return gpgSigner{entity: entities[0]}, nil
+}
+
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func (s gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("sign with GPG key: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// addSignatureHeader adds a "gpgsig" header to a raw commit object,
+// which is where Git stores both GPG and SSH signatures.
+func addSignatureHeader(commit, signature []byte) ([]byte, error) {
+	headers, message, ok := bytes.Cut(commit, []byte("\n\n"))
+	if !ok {
+		return nil, errors.New("add signature: commit has no message")
+	}
+	var buf bytes.Buffer
+	buf.Write(headers)
+	buf.WriteString("\ngpgsig ")
+	sig := strings.TrimSuffix(string(signature), "\n")
+	buf.WriteString(strings.ReplaceAll(sig, "\n", "\n "))
+	buf.WriteString("\n\n")
+	buf.Write(message)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"golang.org/x/crypto/ssh"
+)
+
+// testKeyring is a signer, together with the local keyring that can
+// verify its signatures using "git verify-commit".
+type testKeyring struct {
+	signer    Signer
+	gitConfig []string
+	env       []string
+}
+
+func newSSHTestKeyring(t *testing.T, privateKey any) testKeyring {
+	t.Helper()
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSSHSigner(pem.EncodeToMemory(block))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshSigner, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowedSigners := filepath.Join(t.TempDir(), "allowed_signers")
+	writeTestFile(t, allowedSigners, testCommitter.Email+" "+string(ssh.MarshalAuthorizedKey(sshSigner.PublicKey())))
+	return testKeyring{
+		signer:    signer,
+		gitConfig: []string{"-c", "gpg.ssh.allowedSignersFile=" + allowedSigners},
+	}
+}
+
+func newGPGTestKeyring(t *testing.T) testKeyring {
+	t.Helper()
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg is not installed")
+	}
+	entity, err := openpgp.NewEntity(testCommitter.Name, "", testCommitter.Email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var privateKey bytes.Buffer
+	w, err := armor.Encode(&privateKey, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.SerializePrivate(w, nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	signer, err := NewGPGSigner(privateKey.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var publicKey bytes.Buffer
+	if err := entity.Serialize(&publicKey); err != nil {
+		t.Fatal(err)
+	}
+	// Using MkdirTemp instead of t.TempDir, as the path to GPG's home dir
+	// must be short enough for the GPG agent's socket file.
+	gpgHome, err := os.MkdirTemp("", "gpg-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--homedir", gpgHome, "--kill", "all").Run()
+		os.RemoveAll(gpgHome)
+	})
+	env := append(os.Environ(), "GNUPGHOME="+gpgHome)
+	cmd := exec.Command("gpg", "--batch", "--import")
+	cmd.Env = env
+	cmd.Stdin = &publicKey
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("import GPG public key: %s\n%s", err, out)
+	}
+	return testKeyring{signer: signer, env: env}
+}
+
+func TestRepo_signedCommit(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyrings := map[string]func(t *testing.T) testKeyring{
+		"ssh-ed25519": func(t *testing.T) testKeyring { return newSSHTestKeyring(t, ed25519Key) },
+		"ssh-rsa":     func(t *testing.T) testKeyring { return newSSHTestKeyring(t, rsaKey) },
+		"gpg":         newGPGTestKeyring,
+	}
+
+	for keyName, newKeyring := range keyrings {
+		t.Run(keyName, func(t *testing.T) {
+			keyring := newKeyring(t)
+			committer := testCommitter
+			committer.Signer = keyring.signer
+
+			for name, g := range testBackends(committer) {
+				t.Run(name, func(t *testing.T) {
+					remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+					repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), remote)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer repo.Close()
+
+					writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+					if err := repo.StageChanges(); err != nil {
+						t.Fatal(err)
+					}
+					commit, err := repo.CreateCommit("Update to v2.0.0")
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got := revParse(t, filepath.Join(repo.Directory(), ".git"), "HEAD"); got != commit.Hash {
+						t.Errorf("want returned hash to be the signed commit %q, got %q", got, commit.Hash)
+					}
+
+					args := slices.Concat(keyring.gitConfig, []string{"-C", repo.Directory(), "verify-commit", "--verbose", commit.Hash})
+					cmd := exec.Command("git", args...)
+					cmd.Env = keyring.env
+					out, err := cmd.CombinedOutput()
+					if err != nil {
+						t.Fatalf("verify commit: %s\n%s", err, out)
+					}
+					if !strings.Contains(string(out), "Good") {
+						t.Errorf("want good signature, got:\n%s", out)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestNewSSHSigner_encrypted(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSSHSigner(pem.EncodeToMemory(block)); err == nil {
+		t.Error("want error for passphrase-protected key")
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// SSHCredentials is used to authenticate towards SSH remotes, such as
+// "git@github.com:RiskIdent/jelease.git".
+type SSHCredentials struct {
+	// PrivateKeyPath is the path to the SSH private key, such as a deploy key.
+	// If empty, then the SSH client's default keys are used.
+	PrivateKeyPath string
+	// KnownHostsPath is the path to a known_hosts file used to verify the
+	// remote's host key. If empty, then the SSH client's default is used.
+	KnownHostsPath string
+}
+
+// scpLikeRemote matches the short scp-like syntax for SSH remotes,
+// such as "git@github.com:RiskIdent/jelease.git".
+var scpLikeRemote = regexp.MustCompile(`^(?:[^@/:]+@)?[^@/:]+:[^/]`)
+
+// IsSSHRemote returns true if the remote is an SSH URL, either in the
+// "ssh://" URL format or the scp-like "user@host:path" format.
+func IsSSHRemote(remote string) bool {
+	if strings.Contains(remote, "://") {
+		return strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "git+ssh://")
+	}
+	return scpLikeRemote.MatchString(remote)
+}
+
+// SSHRemoteToHTTPS converts an SSH remote to the HTTPS URL of the same host
+// and path, which is what forges such as GitHub use to identify repositories.
+func SSHRemoteToHTTPS(remote string) (string, error) {
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return "", err
+		}
+		// The port is for SSH, and not for HTTPS
+		return (&url.URL{Scheme: "https", Host: u.Hostname(), Path: u.Path}).String(), nil
+	}
+	// The scp-like syntax: [user@]host:path
+	hostAndPath := remote
+	if at, colon := strings.Index(remote, "@"), strings.Index(remote, ":"); at >= 0 && at < colon {
+		hostAndPath = remote[at+1:]
+	}
+	host, path, _ := strings.Cut(hostAndPath, ":")
+	return "https://" + host + "/" + strings.TrimPrefix(path, "/"), nil
+}
+
+// sshCommand returns the command Git should use for SSH,
+// as set via the "core.sshCommand" config or $GIT_SSH_COMMAND.
+func sshCommand(cred *SSHCredentials) string {
+	// Never prompt for passwords or unknown host keys
+	args := []string{"ssh", "-o", "BatchMode=yes"}
+	if cred != nil && cred.PrivateKeyPath != "" {
+		args = append(args, "-i", cred.PrivateKeyPath, "-o", "IdentitiesOnly=yes")
+	}
+	if cred != nil && cred.KnownHostsPath != "" {
+		args = append(args,
+			"-o", "UserKnownHostsFile="+cred.KnownHostsPath,
+			"-o", "StrictHostKeyChecking=yes")
+	}
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return strings.Join(args, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[a-zA-Z0-9_./=:@-]+$`)
+
+// shellQuote quotes the argument for use in a POSIX shell,
+// which is how Git runs the SSH command.
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// goGitSSHAuth creates go-git's authentication for an SSH remote.
+func goGitSSHAuth(remote string, cred *SSHCredentials) (*gitssh.PublicKeys, error) {
+	if cred == nil || cred.PrivateKeyPath == "" {
+		return nil, errors.New("SSH remotes require an SSH private key to be configured")
+	}
+	auth, err := gitssh.NewPublicKeysFromFile(sshRemoteUser(remote), cred.PrivateKeyPath, "")
+	if err != nil {
+		return nil, fmt.Errorf("read SSH private key: %w", err)
+	}
+	if cred.KnownHostsPath != "" {
+		callback, err := gitssh.NewKnownHostsCallback(cred.KnownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("read SSH known hosts: %w", err)
+		}
+		auth.HostKeyCallback = callback
+	}
+	return auth, nil
+}
+
+// sshRemoteUser returns the user from the SSH remote, or "git" by default.
+func sshRemoteUser(remote string) string {
+	if strings.Contains(remote, "://") {
+		if u, err := url.Parse(remote); err == nil && u.User != nil && u.User.Username() != "" {
+			return u.User.Username()
+		}
+		return gitssh.DefaultUsername
+	}
+	if user, _, ok := strings.Cut(remote, "@"); ok && !strings.Contains(user, ":") {
+		return user
+	}
+	return gitssh.DefaultUsername
+}
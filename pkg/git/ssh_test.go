@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func TestIsSSHRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   bool
+	}{
+		{remote: "git@github.com:RiskIdent/jelease.git", want: true},
+		{remote: "github.com:RiskIdent/jelease.git", want: true},
+		{remote: "ssh://git@github.com/RiskIdent/jelease.git", want: true},
+		{remote: "ssh://git@github.com:2222/RiskIdent/jelease.git", want: true},
+		{remote: "https://github.com/RiskIdent/jelease", want: false},
+		{remote: "https://user@github.com/RiskIdent/jelease", want: false},
+		{remote: "/tmp/remote.git", want: false},
+		{remote: "C:/remote.git", want: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.remote, func(t *testing.T) {
+			if got := IsSSHRemote(tc.remote); got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRepo_sshRemote(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh is not installed")
+	}
+	remote := newTestRemote(t, map[string]string{"file.txt": "v1.0.0\n"})
+	cred := newTestSSHServer(t)
+	sshRemote := "ssh://git@" + cred.addr + remote
+
+	creds := Credentials{SSH: &cred.SSHCredentials}
+	backends := map[string]Git{
+		"cmd":   Cmd{Committer: testCommitter, Credentials: creds},
+		"goGit": GoGit{Committer: testCommitter, Credentials: creds},
+	}
+	for name, g := range backends {
+		t.Run(name, func(t *testing.T) {
+			repo, err := g.Clone(filepath.Join(t.TempDir(), "clone"), sshRemote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repo.Close()
+			branch := "jelease/ssh-" + name
+			if err := repo.CheckoutNewBranch(branch); err != nil {
+				t.Fatal(err)
+			}
+			writeTestFile(t, filepath.Join(repo.Directory(), "file.txt"), "v2.0.0\n")
+			if err := repo.StageChanges(); err != nil {
+				t.Fatal(err)
+			}
+			commit, err := repo.CreateCommit("Update to v2.0.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.PushChanges(); err != nil {
+				t.Fatal(err)
+			}
+			if got := revParse(t, remote, "refs/heads/"+branch); got != commit.Hash {
+				t.Errorf("want pushed branch at %q, got %q", commit.Hash, got)
+			}
+		})
+	}
+
+	t.Run("unknown host key", func(t *testing.T) {
+		otherKnownHosts := filepath.Join(t.TempDir(), "known_hosts")
+		writeTestFile(t, otherKnownHosts, "")
+		g := Cmd{Credentials: Credentials{SSH: &SSHCredentials{
+			PrivateKeyPath: cred.PrivateKeyPath,
+			KnownHostsPath: otherKnownHosts,
+		}}}
+		if _, err := g.Clone(filepath.Join(t.TempDir(), "clone"), sshRemote); err == nil {
+			t.Error("want error when host key is not in known_hosts")
+		}
+	})
+}
+
+type testSSHServer struct {
+	SSHCredentials
+	addr string
+}
+
+// newTestSSHServer starts an SSH server that only allows running
+// git-upload-pack and git-receive-pack, like GitHub's SSH server.
+func newTestSSHServer(t *testing.T) testSSHServer {
+	t.Helper()
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientPub, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientSSHPub, err := ssh.NewPublicKey(clientPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientSSHPub.Marshal()) {
+				return nil, errors.New("unknown public key")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+
+	dir := t.TempDir()
+	keyBlock, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(keyBlock), 0600); err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	knownHostsPath := filepath.Join(dir, "known_hosts")
+	knownHostsLine := knownhosts.Line([]string{knownhosts.Normalize(addr)}, hostSigner.PublicKey())
+	writeTestFile(t, knownHostsPath, knownHostsLine+"\n")
+
+	return testSSHServer{
+		SSHCredentials: SSHCredentials{
+			PrivateKeyPath: keyPath,
+			KnownHostsPath: knownHostsPath,
+		},
+		addr: addr,
+	}
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	defer conn.Close()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go serveTestSSHSession(channel, requests)
+	}
+}
+
+func serveTestSSHSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		if req.Type != "exec" || len(req.Payload) < 4 {
+			req.Reply(false, nil)
+			continue
+		}
+		command := string(req.Payload[4:])
+		service, path, ok := strings.Cut(command, " ")
+		if !ok || (service != "git-upload-pack" && service != "git-receive-pack") {
+			req.Reply(false, nil)
+			return
+		}
+		req.Reply(true, nil)
+		cmd := exec.Command("git", strings.TrimPrefix(service, "git-"), strings.Trim(path, "'"))
+		cmd.Stdin = channel
+		cmd.Stdout = channel
+		cmd.Stderr = channel.Stderr()
+		exitCode := 0
+		if err := cmd.Run(); err != nil {
+			exitCode = 1
+		}
+		io.Copy(io.Discard, channel)
+		status := make([]byte, 4)
+		binary.BigEndian.PutUint32(status, uint32(exitCode))
+		channel.SendRequest("exit-status", false, status)
+		return
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"strings"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/git"
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/golang-jwt/jwt/v4"
@@ -40,7 +41,7 @@ import (
 type appsClient struct {
 	appsTransport       *ghinstallation.AppsTransport
 	noInstallClient     *github.Client
-	installationPerRepo map[RepoRefSlim]installation
+	installationPerRepo map[forge.RepoRefSlim]installation
 	baseURL             *string
 }
 
@@ -50,7 +51,7 @@ type installation struct {
 	installationID int64
 }
 
-func NewAppClient(ghCfg *config.GitHub) (Client, error) {
+func NewAppClient(ghCfg *config.GitHub) (forge.Client, error) {
 	appsTransport, err := newAppsTransport(ghCfg)
 	if err != nil {
 		return nil, err
@@ -63,11 +64,15 @@ func NewAppClient(ghCfg *config.GitHub) (Client, error) {
 	return &appsClient{
 		appsTransport:       appsTransport,
 		noInstallClient:     nonInstallClient,
-		installationPerRepo: make(map[RepoRefSlim]installation),
+		installationPerRepo: make(map[forge.RepoRefSlim]installation),
 		baseURL:             ghCfg.URL,
 	}, nil
 }
 
+func (c *appsClient) ParseRepoRef(remote string) (forge.RepoRef, error) {
+	return ParseRepoRef(remote)
+}
+
 func (c *appsClient) TestConnection(ctx context.Context) error {
 	// get empty means get the currently authenticated app
 	app, _, err := c.noInstallClient.Apps.Get(ctx, "")
@@ -81,7 +86,7 @@ func (c *appsClient) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-func (c *appsClient) GitCredentialsForRepo(ctx context.Context, repo RepoRef) (git.Credentials, error) {
+func (c *appsClient) GitCredentialsForRepo(ctx context.Context, repo forge.RepoRef) (git.Credentials, error) {
 	inst, err := c.findInstallationForRepo(ctx, repo)
 	if err != nil {
 		return git.Credentials{}, err
@@ -100,15 +105,95 @@ func (c *appsClient) GitCredentialsForRepo(ctx context.Context, repo RepoRef) (g
 	}, nil
 }
 
-func (c *appsClient) CreatePullRequest(ctx context.Context, pr NewPullRequest) (PullRequest, error) {
+func (c *appsClient) CreatePullRequest(ctx context.Context, pr forge.NewPullRequest) (forge.PullRequest, error) {
 	inst, err := c.findInstallationForRepo(ctx, pr.RepoRef)
 	if err != nil {
-		return PullRequest{}, err
+		return forge.PullRequest{}, err
 	}
 	return CreatePullRequest(ctx, inst.client, pr)
 }
 
-func (c *appsClient) findInstallationForRepo(ctx context.Context, repo RepoRef) (installation, error) {
+func (c *appsClient) FindPullRequest(ctx context.Context, repo forge.RepoRef, head, base string) (forge.PullRequest, error) {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	return FindPullRequest(ctx, inst.client, repo, head, base)
+}
+
+func (c *appsClient) UpdatePullRequest(ctx context.Context, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	inst, err := c.findInstallationForRepo(ctx, pr.RepoRef)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	return UpdatePullRequest(ctx, inst.client, number, pr)
+}
+
+func (c *appsClient) ListPullRequests(ctx context.Context, repo forge.RepoRef, base string) ([]forge.PullRequest, error) {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return ListPullRequests(ctx, inst.client, repo, base)
+}
+
+func (c *appsClient) ClosePullRequest(ctx context.Context, repo forge.RepoRef, number int, comment string) error {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return ClosePullRequest(ctx, inst.client, repo, number, comment)
+}
+
+func (c *appsClient) CreateCommit(ctx context.Context, commit forge.NewCommit) (string, error) {
+	inst, err := c.findInstallationForRepo(ctx, commit.RepoRef)
+	if err != nil {
+		return "", err
+	}
+	return CreateCommit(ctx, inst.client, commit)
+}
+
+func (c *appsClient) CreateBranch(ctx context.Context, repo forge.RepoRef, branch, hash string) error {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return CreateBranch(ctx, inst.client, repo, branch, hash)
+}
+
+func (c *appsClient) UpdateBranch(ctx context.Context, repo forge.RepoRef, branch, hash string) error {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return UpdateBranch(ctx, inst.client, repo, branch, hash)
+}
+
+func (c *appsClient) DeleteBranch(ctx context.Context, repo forge.RepoRef, branch string) error {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return DeleteBranch(ctx, inst.client, repo, branch)
+}
+
+func (c *appsClient) EnableAutoMerge(ctx context.Context, pr forge.PullRequest, method config.MergeMethod) error {
+	inst, err := c.findInstallationForRepo(ctx, pr.RepoRef)
+	if err != nil {
+		return err
+	}
+	return EnableAutoMerge(ctx, inst.client, pr, method)
+}
+
+func (c *appsClient) GetCommitChecks(ctx context.Context, repo forge.RepoRef, ref string) (forge.CommitChecks, error) {
+	inst, err := c.findInstallationForRepo(ctx, repo)
+	if err != nil {
+		return forge.CommitChecks{}, err
+	}
+	return GetCommitChecks(ctx, inst.client, repo, ref)
+}
+
+func (c *appsClient) findInstallationForRepo(ctx context.Context, repo forge.RepoRef) (installation, error) {
 	if inst, ok := c.installationPerRepo[repo.Slim()]; ok {
 		return inst, nil
 	}
@@ -140,7 +225,7 @@ func (c *appsClient) cacheInstallationClient(ctx context.Context, inst installat
 		return fmt.Errorf("list which repos to cache client for: %w", err)
 	}
 	for _, repoData := range reposResp.Repositories {
-		refSlim := RepoRefSlim{
+		refSlim := forge.RepoRefSlim{
 			Owner: repoData.Owner.GetLogin(),
 			Repo:  repoData.GetName(),
 		}
@@ -157,7 +242,7 @@ func (c *appsClient) cacheInstallationClient(ctx context.Context, inst installat
 	return nil
 }
 
-func (c *appsClient) findInstallationIDForRepo(ctx context.Context, repo RepoRef) (int64, error) {
+func (c *appsClient) findInstallationIDForRepo(ctx context.Context, repo forge.RepoRef) (int64, error) {
 	inst, resp, err := c.noInstallClient.Apps.FindRepositoryInstallation(ctx, repo.Owner, repo.Repo)
 	if err != nil {
 		if resp != nil && resp.StatusCode == 404 {
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/google/go-github/v48/github"
+)
+
+const enableAutoMergeMutation = `mutation($pullRequestId: ID!, $mergeMethod: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $pullRequestId, mergeMethod: $mergeMethod}) {
+    clientMutationId
+  }
+}`
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables,omitempty"`
+}
+
+type graphQLResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// EnableAutoMerge enables auto-merge on a pull request, so GitHub merges it
+// once all the required checks and reviews pass. The repository must allow
+// auto-merge, and the base branch must have protection rules.
+//
+// There is no REST API for this, so it uses the GitHub GraphQL API.
+func EnableAutoMerge(ctx context.Context, gh *github.Client, pr forge.PullRequest, method config.MergeMethod) error {
+	if pr.NodeID == "" {
+		return fmt.Errorf("enable auto-merge on PR %s: missing pull request node ID", pr.URL)
+	}
+	err := doGraphQL(ctx, gh, graphQLRequest{
+		Query: enableAutoMergeMutation,
+		Variables: map[string]any{
+			"pullRequestId": pr.NodeID,
+			"mergeMethod":   strings.ToUpper(string(cmp.Or(method, config.MergeMethodMerge))),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("enable auto-merge on PR %s: %w", pr.URL, err)
+	}
+	return nil
+}
+
+func doGraphQL(ctx context.Context, gh *github.Client, body graphQLRequest) error {
+	req, err := gh.NewRequest("POST", graphQLURL(gh), body)
+	if err != nil {
+		return err
+	}
+	var resp graphQLResponse
+	if _, err := gh.Do(ctx, req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			msgs[i] = e.Message
+		}
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// graphQLURL returns the GraphQL endpoint, which for GitHub Enterprise is
+// at /api/graphql instead of under the /api/v3/ REST API path.
+func graphQLURL(gh *github.Client) string {
+	base := gh.BaseURL.String()
+	if prefix, ok := strings.CutSuffix(base, "/api/v3/"); ok {
+		return prefix + "/api/graphql"
+	}
+	return base + "graphql"
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+	"github.com/google/go-github/v48/github"
+)
+
+func TestEnableAutoMerge(t *testing.T) {
+	var got struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		forgeapitest.WriteJSON(t, w, map[string]any{
+			"data": map[string]any{"enablePullRequestAutoMerge": map[string]any{"clientMutationId": nil}},
+		})
+	})
+	gh := newTestGitHub(t, mux)
+
+	pr := forge.PullRequest{NodeID: "PR_kwDOAbc", URL: "https://github.com/RiskIdent/jelease/pull/2"}
+	if err := EnableAutoMerge(context.Background(), gh, pr, config.MergeMethodSquash); err != nil {
+		t.Fatal(err)
+	}
+	if got.Variables["pullRequestId"] != "PR_kwDOAbc" {
+		t.Errorf("want pullRequestId %q, got %v", "PR_kwDOAbc", got.Variables["pullRequestId"])
+	}
+	if got.Variables["mergeMethod"] != "SQUASH" {
+		t.Errorf("want mergeMethod %q, got %v", "SQUASH", got.Variables["mergeMethod"])
+	}
+	if got.Query != enableAutoMergeMutation {
+		t.Errorf("want query %q, got %q", enableAutoMergeMutation, got.Query)
+	}
+}
+
+func TestEnableAutoMerge_graphQLError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, map[string]any{
+			"errors": []map[string]any{{"message": "Pull request Auto merge is not allowed for this repository"}},
+		})
+	})
+	gh := newTestGitHub(t, mux)
+
+	err := EnableAutoMerge(context.Background(), gh, forge.PullRequest{NodeID: "PR_kwDOAbc"}, "")
+	if err == nil {
+		t.Fatal("want error, got nil")
+	}
+	want := "enable auto-merge on PR : Pull request Auto merge is not allowed for this repository"
+	if err.Error() != want {
+		t.Errorf("want error %q, got %q", want, err)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{baseURL: "https://api.github.com/", want: "https://api.github.com/graphql"},
+		{baseURL: "https://github.example.com/api/v3/", want: "https://github.example.com/api/graphql"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.baseURL, func(t *testing.T) {
+			gh := github.NewClient(nil)
+			baseURL, err := url.Parse(tc.baseURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gh.BaseURL = baseURL
+			if got := graphQLURL(gh); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
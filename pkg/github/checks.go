@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/google/go-github/v48/github"
+)
+
+// GetCommitChecks returns both the check runs and the commit statuses of
+// a commit.
+func GetCommitChecks(ctx context.Context, gh *github.Client, repo forge.RepoRef, ref string) (forge.CommitChecks, error) {
+	runs, err := listCheckRuns(ctx, gh, repo, ref)
+	if err != nil {
+		return forge.CommitChecks{}, fmt.Errorf("list check runs: %w", err)
+	}
+	statuses, err := listCommitStatuses(ctx, gh, repo, ref)
+	if err != nil {
+		return forge.CommitChecks{}, fmt.Errorf("get commit statuses: %w", err)
+	}
+	return forge.NewCommitChecks(append(runs, statuses...)), nil
+}
+
+func listCheckRuns(ctx context.Context, gh *github.Client, repo forge.RepoRef, ref string) ([]forge.Check, error) {
+	opts := &github.ListCheckRunsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var checks []forge.Check
+	for {
+		result, resp, err := gh.Checks.ListCheckRunsForRef(ctx, repo.Owner, repo.Repo, ref, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range result.CheckRuns {
+			checks = append(checks, forge.Check{
+				Name:  run.GetName(),
+				URL:   run.GetHTMLURL(),
+				State: checkRunState(run),
+			})
+		}
+		if resp.NextPage == 0 {
+			return checks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func checkRunState(run *github.CheckRun) forge.CheckState {
+	if run.GetStatus() != "completed" {
+		return forge.CheckStatePending
+	}
+	switch run.GetConclusion() {
+	case "success", "neutral", "skipped":
+		return forge.CheckStateSuccess
+	default:
+		return forge.CheckStateFailure
+	}
+}
+
+func listCommitStatuses(ctx context.Context, gh *github.Client, repo forge.RepoRef, ref string) ([]forge.Check, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var checks []forge.Check
+	for {
+		combined, resp, err := gh.Repositories.GetCombinedStatus(ctx, repo.Owner, repo.Repo, ref, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range combined.Statuses {
+			checks = append(checks, forge.Check{
+				Name:  status.GetContext(),
+				URL:   status.GetTargetURL(),
+				State: commitStatusState(status),
+			})
+		}
+		if resp.NextPage == 0 {
+			return checks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func commitStatusState(status *github.RepoStatus) forge.CheckState {
+	switch status.GetState() {
+	case "success":
+		return forge.CheckStateSuccess
+	case "pending":
+		return forge.CheckStatePending
+	default:
+		return forge.CheckStateFailure
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+)
+
+func TestGetCommitChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/commits/abc123/check-runs", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, map[string]any{
+			"total_count": 3,
+			"check_runs": []map[string]any{
+				{"name": "build", "status": "completed", "conclusion": "success", "html_url": "https://example.com/build"},
+				{"name": "lint", "status": "completed", "conclusion": "skipped"},
+				{"name": "test", "status": "completed", "conclusion": "failure", "html_url": "https://example.com/test"},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/commits/abc123/status", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, map[string]any{
+			"state": "failure",
+			"statuses": []map[string]any{
+				{"context": "ci/jenkins", "state": "error", "target_url": "https://example.com/jenkins"},
+			},
+		})
+	})
+	gh := newTestGitHub(t, mux)
+
+	checks, err := GetCommitChecks(context.Background(), gh, forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checks.State != forge.CheckStateFailure {
+		t.Errorf("want state %q, got %q", forge.CheckStateFailure, checks.State)
+	}
+	wantFailed := []forge.Check{
+		{Name: "test", URL: "https://example.com/test", State: forge.CheckStateFailure},
+		{Name: "ci/jenkins", URL: "https://example.com/jenkins", State: forge.CheckStateFailure},
+	}
+	failed := checks.Failed()
+	if len(failed) != len(wantFailed) {
+		t.Fatalf("want failed checks %v, got %v", wantFailed, failed)
+	}
+	for i, want := range wantFailed {
+		if failed[i] != want {
+			t.Errorf("failed check #%d: want %v, got %v", i, want, failed[i])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/util"
+	"github.com/google/go-github/v48/github"
+)
+
+// CreateCommit creates a tree with the changed files on top of the parent
+// commit's tree, and then a commit of that tree. No author nor committer is
+// set, so GitHub signs the commit when authenticated as a GitHub App.
+// Returns the hash of the new commit.
+func CreateCommit(ctx context.Context, gh *github.Client, commit forge.NewCommit) (string, error) {
+	parent, _, err := gh.Git.GetCommit(ctx, commit.Owner, commit.Repo, commit.ParentHash)
+	if err != nil {
+		return "", fmt.Errorf("get parent commit: %w", err)
+	}
+	entries := make([]*github.TreeEntry, 0, len(commit.Files))
+	for _, file := range commit.Files {
+		entry := &github.TreeEntry{
+			Path: util.Ref(file.Path),
+			Mode: util.Ref(file.Mode),
+			Type: util.Ref("blob"),
+		}
+		switch {
+		case file.Deleted:
+			// Setting neither SHA nor content deletes the file
+		case utf8.Valid(file.Content):
+			entry.Content = util.Ref(string(file.Content))
+		default:
+			// Binary files must be uploaded separately, as the tree
+			// content field only supports UTF-8
+			blob, _, err := gh.Git.CreateBlob(ctx, commit.Owner, commit.Repo, &github.Blob{
+				Content:  util.Ref(base64.StdEncoding.EncodeToString(file.Content)),
+				Encoding: util.Ref("base64"),
+			})
+			if err != nil {
+				return "", fmt.Errorf("create blob: %s: %w", file.Path, err)
+			}
+			entry.SHA = blob.SHA
+		}
+		entries = append(entries, entry)
+	}
+	tree, _, err := gh.Git.CreateTree(ctx, commit.Owner, commit.Repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("create tree: %w", err)
+	}
+	created, _, err := gh.Git.CreateCommit(ctx, commit.Owner, commit.Repo, &github.Commit{
+		Message: util.Ref(commit.Message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: util.Ref(commit.ParentHash)}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("create commit: %w", err)
+	}
+	return created.GetSHA(), nil
+}
+
+// CreateBranch creates a new branch pointing at the commit hash.
+func CreateBranch(ctx context.Context, gh *github.Client, repo forge.RepoRef, branch, hash string) error {
+	_, _, err := gh.Git.CreateRef(ctx, repo.Owner, repo.Repo, &github.Reference{
+		Ref:    util.Ref("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: util.Ref(hash)},
+	})
+	if err != nil {
+		return fmt.Errorf("create branch: %w", err)
+	}
+	return nil
+}
+
+// UpdateBranch force-updates an existing branch to point at the commit hash.
+func UpdateBranch(ctx context.Context, gh *github.Client, repo forge.RepoRef, branch, hash string) error {
+	_, _, err := gh.Git.UpdateRef(ctx, repo.Owner, repo.Repo, &github.Reference{
+		Ref:    util.Ref("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: util.Ref(hash)},
+	}, true)
+	if err != nil {
+		return fmt.Errorf("update branch: %w", err)
+	}
+	return nil
+}
+
+func DeleteBranch(ctx context.Context, gh *github.Client, repo forge.RepoRef, branch string) error {
+	if _, err := gh.Git.DeleteRef(ctx, repo.Owner, repo.Repo, "heads/"+branch); err != nil {
+		return fmt.Errorf("delete branch: %w", err)
+	}
+	return nil
+}
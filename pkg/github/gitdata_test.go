@@ -0,0 +1,206 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+	"github.com/RiskIdent/jelease/pkg/util"
+	"github.com/google/go-github/v48/github"
+)
+
+// newTestGitHub creates a GitHub client towards a fake GitHub API,
+// where the handler is registered using the given [http.ServeMux].
+func newTestGitHub(t *testing.T, mux *http.ServeMux) *github.Client {
+	t.Helper()
+	server := forgeapitest.NewServer(t, mux)
+	gh := github.NewClient(server.Client())
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gh.BaseURL = baseURL
+	return gh
+}
+
+type testTreeEntry struct {
+	Path    string  `json:"path"`
+	Mode    string  `json:"mode"`
+	Type    string  `json:"type"`
+	SHA     *string `json:"sha"`
+	Content *string `json:"content"`
+}
+
+func TestCreateCommit(t *testing.T) {
+	var gotBlob struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	var gotTree struct {
+		BaseTree string          `json:"base_tree"`
+		Tree     []testTreeEntry `json:"tree"`
+	}
+	var gotCommit struct {
+		Message   string   `json:"message"`
+		Tree      string   `json:"tree"`
+		Parents   []string `json:"parents"`
+		Author    any      `json:"author"`
+		Committer any      `json:"committer"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/git/commits/parent123", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, map[string]any{"sha": "parent123", "tree": map[string]any{"sha": "basetree123"}})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotBlob)
+		forgeapitest.WriteJSON(t, w, map[string]any{"sha": "blob123"})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/git/trees", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotTree)
+		forgeapitest.WriteJSON(t, w, map[string]any{"sha": "tree123"})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/git/commits", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotCommit)
+		forgeapitest.WriteJSON(t, w, map[string]any{"sha": "commit123"})
+	})
+	gh := newTestGitHub(t, mux)
+
+	hash, err := CreateCommit(context.Background(), gh, forge.NewCommit{
+		RepoRef:    forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"},
+		ParentHash: "parent123",
+		Message:    "Update to v2.0.0\n\nSome description",
+		Files: []git.ChangedFile{
+			{Path: "file.txt", Mode: "100644", Content: []byte("v2.0.0\n")},
+			{Path: "empty.txt", Mode: "100644", Content: []byte{}},
+			{Path: "image.bin", Mode: "100644", Content: []byte{0xff, 0xfe}},
+			{Path: "removed.txt", Mode: "100644", Deleted: true},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != "commit123" {
+		t.Errorf("want hash %q, got %q", "commit123", hash)
+	}
+
+	if gotBlob.Content != "//4=" || gotBlob.Encoding != "base64" {
+		t.Errorf("want base64 blob %q, got %q (%s)", "//4=", gotBlob.Content, gotBlob.Encoding)
+	}
+	if gotTree.BaseTree != "basetree123" {
+		t.Errorf("want base tree %q, got %q", "basetree123", gotTree.BaseTree)
+	}
+	wantTree := []struct {
+		path    string
+		sha     *string
+		content *string
+	}{
+		{path: "file.txt", content: util.Ref("v2.0.0\n")},
+		{path: "empty.txt", content: util.Ref("")},
+		{path: "image.bin", sha: util.Ref("blob123")},
+		{path: "removed.txt"},
+	}
+	if len(gotTree.Tree) != len(wantTree) {
+		t.Fatalf("want %d tree entries, got %d", len(wantTree), len(gotTree.Tree))
+	}
+	for i, want := range wantTree {
+		got := gotTree.Tree[i]
+		if got.Path != want.path || got.Mode != "100644" || got.Type != "blob" {
+			t.Errorf("tree entry #%d: want path %q mode 100644 type blob, got %+v", i, want.path, got)
+		}
+		if util.Deref(got.SHA, "") != util.Deref(want.sha, "") || (got.SHA == nil) != (want.sha == nil) {
+			t.Errorf("tree entry #%d: want sha %v, got %v", i, util.Deref(want.sha, ""), util.Deref(got.SHA, ""))
+		}
+		if util.Deref(got.Content, "") != util.Deref(want.content, "") || (got.Content == nil) != (want.content == nil) {
+			t.Errorf("tree entry #%d: want content %q, got %q", i, util.Deref(want.content, ""), util.Deref(got.Content, ""))
+		}
+	}
+
+	if gotCommit.Message != "Update to v2.0.0\n\nSome description" {
+		t.Errorf("want commit message %q, got %q", "Update to v2.0.0\n\nSome description", gotCommit.Message)
+	}
+	if gotCommit.Tree != "tree123" {
+		t.Errorf("want commit tree %q, got %q", "tree123", gotCommit.Tree)
+	}
+	if len(gotCommit.Parents) != 1 || gotCommit.Parents[0] != "parent123" {
+		t.Errorf("want commit parents %q, got %q", []string{"parent123"}, gotCommit.Parents)
+	}
+	if gotCommit.Author != nil || gotCommit.Committer != nil {
+		t.Errorf("want no author nor committer, so GitHub signs the commit, got %v and %v", gotCommit.Author, gotCommit.Committer)
+	}
+}
+
+func TestCreateAndUpdateBranch(t *testing.T) {
+	var gotCreate struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	var gotUpdate struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotCreate)
+		forgeapitest.WriteJSON(t, w, map[string]any{"ref": gotCreate.Ref})
+	})
+	mux.HandleFunc("PATCH /repos/RiskIdent/jelease/git/refs/heads/jelease/pkg-v2.0.0", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotUpdate)
+		forgeapitest.WriteJSON(t, w, map[string]any{"ref": "refs/heads/jelease/pkg-v2.0.0"})
+	})
+	gh := newTestGitHub(t, mux)
+	repo := forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}
+
+	if err := CreateBranch(context.Background(), gh, repo, "jelease/pkg-v2.0.0", "commit123"); err != nil {
+		t.Fatal(err)
+	}
+	if gotCreate.Ref != "refs/heads/jelease/pkg-v2.0.0" || gotCreate.SHA != "commit123" {
+		t.Errorf("want created ref %q at %q, got %q at %q", "refs/heads/jelease/pkg-v2.0.0", "commit123", gotCreate.Ref, gotCreate.SHA)
+	}
+
+	if err := UpdateBranch(context.Background(), gh, repo, "jelease/pkg-v2.0.0", "commit456"); err != nil {
+		t.Fatal(err)
+	}
+	if gotUpdate.SHA != "commit456" || !gotUpdate.Force {
+		t.Errorf("want forced update to %q, got %q (force=%t)", "commit456", gotUpdate.SHA, gotUpdate.Force)
+	}
+}
+
+func TestDeleteBranch(t *testing.T) {
+	var deleted bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /repos/RiskIdent/jelease/git/refs/heads/jelease/pkg-v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	gh := newTestGitHub(t, mux)
+	repo := forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}
+
+	if err := DeleteBranch(context.Background(), gh, repo, "jelease/pkg-v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Error("want branch deleted")
+	}
+}
@@ -23,18 +23,13 @@ import (
 	"net/http"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/git"
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/google/go-github/v48/github"
 )
 
-type Client interface {
-	CreatePullRequest(ctx context.Context, pr NewPullRequest) (PullRequest, error)
-	TestConnection(ctx context.Context) error
-	GitCredentialsForRepo(ctx context.Context, repo RepoRef) (git.Credentials, error)
-}
-
-func New(ghCfg *config.GitHub) (Client, error) {
+func New(ghCfg *config.GitHub) (forge.Client, error) {
 	switch ghCfg.Auth.Type {
 	case config.GitHubAuthTypePAT:
 		return NewPATClient(ghCfg)
@@ -52,47 +47,119 @@ func newClientEnterpriceOrPublic(ghURL *string, httpClient *http.Client) (*githu
 	return github.NewClient(httpClient), nil
 }
 
-func CreatePullRequest(ctx context.Context, gh *github.Client, pr NewPullRequest) (PullRequest, error) {
+// CreatePullRequest creates a pull request, and then sets its metadata such
+// as labels and reviewers. The created pull request is returned even if
+// setting the metadata fails.
+func CreatePullRequest(ctx context.Context, gh *github.Client, pr forge.NewPullRequest) (forge.PullRequest, error) {
 	created, _, err := gh.PullRequests.Create(ctx, pr.Owner, pr.Repo, &github.NewPullRequest{
 		Title:               &pr.Title,
 		Body:                &pr.Description,
 		Head:                &pr.Head,
 		Base:                &pr.Base,
 		MaintainerCanModify: util.Ref(true),
+		Draft:               &pr.Draft,
+	})
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	result := newPullRequest(pr.RepoRef, created, pr.Commit)
+	if err := setPullRequestMetadata(ctx, gh, pr, &result); err != nil {
+		return result, fmt.Errorf("created PR %s: %w", result.URL, err)
+	}
+	return result, nil
+}
+
+func FindPullRequest(ctx context.Context, gh *github.Client, repo forge.RepoRef, head, base string) (forge.PullRequest, error) {
+	prs, _, err := gh.PullRequests.List(ctx, repo.Owner, repo.Repo, &github.PullRequestListOptions{
+		State: "open",
+		// Only finds branches in the same repository, not from forks
+		Head: repo.Owner + ":" + head,
+		Base: base,
+	})
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	if len(prs) == 0 {
+		return forge.PullRequest{}, fmt.Errorf("%w: %s:%s", forge.ErrPullRequestNotFound, repo.Owner, head)
+	}
+	return newPullRequest(repo, prs[0], git.Commit{}), nil
+}
+
+func UpdatePullRequest(ctx context.Context, gh *github.Client, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	updated, _, err := gh.PullRequests.Edit(ctx, pr.Owner, pr.Repo, number, &github.PullRequest{
+		Title: &pr.Title,
+		Body:  &pr.Description,
 	})
 	if err != nil {
-		return PullRequest{}, err
+		return forge.PullRequest{}, err
+	}
+	result := newPullRequest(pr.RepoRef, updated, pr.Commit)
+	result.Updated = true
+	if err := setPullRequestMetadata(ctx, gh, pr, &result); err != nil {
+		return result, fmt.Errorf("updated PR %s: %w", result.URL, err)
+	}
+	return result, nil
+}
+
+func ListPullRequests(ctx context.Context, gh *github.Client, repo forge.RepoRef, base string) ([]forge.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Base:        base,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var result []forge.PullRequest
+	for {
+		prs, resp, err := gh.PullRequests.List(ctx, repo.Owner, repo.Repo, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			result = append(result, newPullRequest(repo, pr, git.Commit{}))
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
 	}
-	return PullRequest{
-		RepoRef:     pr.RepoRef,
-		ID:          created.GetID(),
-		Number:      created.GetNumber(),
-		URL:         created.GetHTMLURL(),
-		Title:       created.GetTitle(),
-		Description: created.GetBody(),
-		Head:        created.Head.GetLabel(),
-		Base:        created.Base.GetLabel(),
-		Commit:      pr.Commit,
-	}, nil
 }
 
-type NewPullRequest struct {
-	RepoRef
-	Title       string
-	Description string
-	Head        string
-	Base        string
-	Commit      git.Commit
+func ClosePullRequest(ctx context.Context, gh *github.Client, repo forge.RepoRef, number int, comment string) error {
+	if comment != "" {
+		_, _, err := gh.Issues.CreateComment(ctx, repo.Owner, repo.Repo, number, &github.IssueComment{
+			Body: &comment,
+		})
+		if err != nil {
+			return fmt.Errorf("comment on pull request: %w", err)
+		}
+	}
+	_, _, err := gh.PullRequests.Edit(ctx, repo.Owner, repo.Repo, number, &github.PullRequest{
+		State: util.Ref("closed"),
+	})
+	if err != nil {
+		return fmt.Errorf("close pull request: %w", err)
+	}
+	return nil
 }
 
-type PullRequest struct {
-	RepoRef
-	ID          int64
-	Number      int
-	URL         string
-	Title       string
-	Description string
-	Head        string
-	Base        string
-	Commit      git.Commit
+func newPullRequest(repo forge.RepoRef, pr *github.PullRequest, commit git.Commit) forge.PullRequest {
+	return forge.PullRequest{
+		RepoRef:       repo,
+		ID:            pr.GetID(),
+		NodeID:        pr.GetNodeID(),
+		Number:        pr.GetNumber(),
+		URL:           pr.GetHTMLURL(),
+		Title:         pr.GetTitle(),
+		Description:   pr.GetBody(),
+		Head:          pr.Head.GetLabel(),
+		HeadBranch:    pr.Head.GetRef(),
+		HeadSHA:       pr.Head.GetSHA(),
+		Base:          pr.Base.GetLabel(),
+		Labels:        labelNames(pr.Labels),
+		Reviewers:     userLogins(pr.RequestedReviewers),
+		TeamReviewers: teamSlugs(pr.RequestedTeams),
+		Assignees:     userLogins(pr.Assignees),
+		Draft:         pr.GetDraft(),
+		Milestone:     pr.Milestone.GetTitle(),
+		Commit:        commit,
+	}
 }
@@ -0,0 +1,208 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+)
+
+func TestListPullRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/pulls", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != "open" || query.Get("base") != "main" {
+			t.Errorf("want open PRs into main, got query %q", r.URL.RawQuery)
+		}
+		if query.Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/RiskIdent/jelease/pulls?page=2>; rel="next"`, r.Host))
+			forgeapitest.WriteJSON(t, w, []map[string]any{{
+				"number": 1,
+				"head":   map[string]any{"label": "RiskIdent:jelease/pkg-v1.0.0", "ref": "jelease/pkg-v1.0.0"},
+				"labels": []map[string]any{{"name": "do-not-close"}},
+			}})
+			return
+		}
+		forgeapitest.WriteJSON(t, w, []map[string]any{{
+			"number": 2,
+			"head":   map[string]any{"label": "RiskIdent:jelease/pkg-v1.1.0", "ref": "jelease/pkg-v1.1.0"},
+		}})
+	})
+	gh := newTestGitHub(t, mux)
+
+	prs, err := ListPullRequests(context.Background(), gh, forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("want 2 PRs from both pages, got %d", len(prs))
+	}
+	if prs[0].HeadBranch != "jelease/pkg-v1.0.0" || prs[0].Head != "RiskIdent:jelease/pkg-v1.0.0" {
+		t.Errorf("want head branch %q, got %q (label %q)", "jelease/pkg-v1.0.0", prs[0].HeadBranch, prs[0].Head)
+	}
+	if !slices.Equal(prs[0].Labels, []string{"do-not-close"}) {
+		t.Errorf("want labels %q, got %q", []string{"do-not-close"}, prs[0].Labels)
+	}
+	if prs[1].Number != 2 {
+		t.Errorf("want second PR #2, got #%d", prs[1].Number)
+	}
+}
+
+func TestClosePullRequest(t *testing.T) {
+	var gotComment struct {
+		Body string `json:"body"`
+	}
+	var gotEdit struct {
+		State string `json:"state"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/issues/1/comments", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotComment)
+		forgeapitest.WriteJSON(t, w, map[string]any{"id": 1})
+	})
+	mux.HandleFunc("PATCH /repos/RiskIdent/jelease/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotEdit)
+		forgeapitest.WriteJSON(t, w, map[string]any{"number": 1, "state": gotEdit.State})
+	})
+	gh := newTestGitHub(t, mux)
+
+	err := ClosePullRequest(context.Background(), gh, forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}, 1, "Superseded by #2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotComment.Body != "Superseded by #2" {
+		t.Errorf("want comment %q, got %q", "Superseded by #2", gotComment.Body)
+	}
+	if gotEdit.State != "closed" {
+		t.Errorf("want state %q, got %q", "closed", gotEdit.State)
+	}
+}
+
+func TestCreatePullRequest_metadata(t *testing.T) {
+	var gotCreate struct {
+		Draft bool `json:"draft"`
+	}
+	var gotLabels, gotAssignees []string
+	var gotMilestone struct {
+		Milestone int `json:"milestone"`
+	}
+	var gotReviewers struct {
+		Reviewers     []string `json:"reviewers"`
+		TeamReviewers []string `json:"team_reviewers"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/pulls", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotCreate)
+		forgeapitest.WriteJSON(t, w, map[string]any{"number": 3, "draft": gotCreate.Draft})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/issues/3/labels", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotLabels)
+		forgeapitest.WriteJSON(t, w, []map[string]any{{"name": "existing"}, {"name": "dependencies"}})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/issues/3/assignees", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Assignees []string `json:"assignees"`
+		}
+		forgeapitest.DecodeBody(t, r, &body)
+		gotAssignees = body.Assignees
+		forgeapitest.WriteJSON(t, w, map[string]any{"assignees": []map[string]any{{"login": "alice"}}})
+	})
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/milestones", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, []map[string]any{
+			{"number": 1, "title": "v1"},
+			{"number": 2, "title": "v2"},
+		})
+	})
+	mux.HandleFunc("PATCH /repos/RiskIdent/jelease/issues/3", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotMilestone)
+		forgeapitest.WriteJSON(t, w, map[string]any{"milestone": map[string]any{"number": 2, "title": "v2"}})
+	})
+	mux.HandleFunc("POST /repos/RiskIdent/jelease/pulls/3/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotReviewers)
+		forgeapitest.WriteJSON(t, w, map[string]any{
+			"number":              3,
+			"requested_reviewers": []map[string]any{{"login": "bob"}},
+			"requested_teams":     []map[string]any{{"slug": "platform"}},
+		})
+	})
+	gh := newTestGitHub(t, mux)
+
+	pr, err := CreatePullRequest(context.Background(), gh, forge.NewPullRequest{
+		RepoRef:       forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"},
+		Title:         "Update",
+		Head:          "jelease/pkg-v2.0.0",
+		Base:          "main",
+		Labels:        []string{"dependencies"},
+		Reviewers:     []string{"bob"},
+		TeamReviewers: []string{"platform"},
+		Assignees:     []string{"alice"},
+		Draft:         true,
+		Milestone:     "v2",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !gotCreate.Draft || !pr.Draft {
+		t.Errorf("want draft PR, got request draft=%t, result draft=%t", gotCreate.Draft, pr.Draft)
+	}
+	if !slices.Equal(gotLabels, []string{"dependencies"}) {
+		t.Errorf("want labels added %q, got %q", []string{"dependencies"}, gotLabels)
+	}
+	if !slices.Equal(pr.Labels, []string{"existing", "dependencies"}) {
+		t.Errorf("want result labels %q, got %q", []string{"existing", "dependencies"}, pr.Labels)
+	}
+	if !slices.Equal(gotAssignees, []string{"alice"}) || !slices.Equal(pr.Assignees, []string{"alice"}) {
+		t.Errorf("want assignees [alice], got request %q, result %q", gotAssignees, pr.Assignees)
+	}
+	if gotMilestone.Milestone != 2 || pr.Milestone != "v2" {
+		t.Errorf("want milestone #2 v2, got request #%d, result %q", gotMilestone.Milestone, pr.Milestone)
+	}
+	if !slices.Equal(gotReviewers.Reviewers, []string{"bob"}) || !slices.Equal(gotReviewers.TeamReviewers, []string{"platform"}) {
+		t.Errorf("want reviewers [bob] and teams [platform] requested, got %+v", gotReviewers)
+	}
+	if !slices.Equal(pr.Reviewers, []string{"bob"}) || !slices.Equal(pr.TeamReviewers, []string{"platform"}) {
+		t.Errorf("want result reviewers [bob] and teams [platform], got %q and %q", pr.Reviewers, pr.TeamReviewers)
+	}
+}
+
+func TestFindMilestone_notFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/RiskIdent/jelease/milestones", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, []map[string]any{{"number": 1, "title": "v1"}})
+	})
+	gh := newTestGitHub(t, mux)
+	repo := forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"}
+
+	if _, err := findMilestone(context.Background(), gh, repo, "v9"); err == nil {
+		t.Error("want error for missing milestone, got nil")
+	}
+	number, err := findMilestone(context.Background(), gh, repo, "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if number != 42 {
+		t.Errorf("want milestone number 42 as-is, got %d", number)
+	}
+}
@@ -21,44 +21,31 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
-)
-
-type RepoRefSlim struct {
-	Owner string
-	Repo  string
-}
 
-func (r RepoRefSlim) String() string {
-	return fmt.Sprintf("%s/%s", r.Owner, r.Repo)
-}
-
-type RepoRef struct {
-	URL   string
-	Owner string
-	Repo  string
-}
-
-func (r RepoRef) String() string {
-	return r.URL
-}
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
 
-func (r RepoRef) Slim() RepoRefSlim {
-	return RepoRefSlim{
-		Owner: r.Owner,
-		Repo:  r.Repo,
+// ParseRepoRef parses the owner and repository name from a remote URL.
+// SSH remotes, such as "git@github.com:RiskIdent/jelease.git", are converted
+// to the HTTPS URL of the same host, as that's used for the GitHub API.
+func ParseRepoRef(remote string) (forge.RepoRef, error) {
+	if git.IsSSHRemote(remote) {
+		httpsRemote, err := git.SSHRemoteToHTTPS(remote)
+		if err != nil {
+			return forge.RepoRef{}, err
+		}
+		remote = httpsRemote
 	}
-}
-
-func ParseRepoRef(remote string) (RepoRef, error) {
 	u, err := url.Parse(remote)
 	if err != nil {
-		return RepoRef{}, err
+		return forge.RepoRef{}, err
 	}
 	u.User = nil
 	path := u.Path
 	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
 	if len(segments) < 2 {
-		return RepoRef{}, fmt.Errorf("expected https://host/OWNER/REPO in URL, got: %s", u.String())
+		return forge.RepoRef{}, fmt.Errorf("expected https://host/OWNER/REPO in URL, got: %s", u.String())
 	}
 	owner := segments[0]
 	repo := strings.TrimSuffix(segments[1], ".git")
@@ -67,7 +54,7 @@ func ParseRepoRef(remote string) (RepoRef, error) {
 	u.Fragment = ""
 	u.RawFragment = ""
 	u.RawQuery = ""
-	return RepoRef{
+	return forge.RepoRef{
 		URL:   u.String(),
 		Owner: owner,
 		Repo:  repo,
@@ -50,6 +50,27 @@ func TestGetRepoRef(t *testing.T) {
 			wantOwner: "RiskIdent",
 			wantRepo:  "jelease",
 		},
+		{
+			name:      "ssh scp-like",
+			remote:    "git@github.com:RiskIdent/jelease.git",
+			wantURL:   "https://github.com/RiskIdent/jelease",
+			wantOwner: "RiskIdent",
+			wantRepo:  "jelease",
+		},
+		{
+			name:      "ssh scp-like without user",
+			remote:    "some-github-enterprise.example.com:RiskIdent/jelease",
+			wantURL:   "https://some-github-enterprise.example.com/RiskIdent/jelease",
+			wantOwner: "RiskIdent",
+			wantRepo:  "jelease",
+		},
+		{
+			name:      "ssh URL with port",
+			remote:    "ssh://git@some-github-enterprise.example.com:2222/RiskIdent/jelease.git",
+			wantURL:   "https://some-github-enterprise.example.com/RiskIdent/jelease",
+			wantOwner: "RiskIdent",
+			wantRepo:  "jelease",
+		},
 		{
 			name:      "ignores extra stuff",
 			remote:    "https://some-github-enterprise.example.com/RiskIdent/jelease.git/woa?ignore=this#please",
@@ -71,6 +92,9 @@ func TestGetRepoRef(t *testing.T) {
 			if got.Repo != tc.wantRepo {
 				t.Errorf("want repo %q, got repo %q", tc.wantRepo, got.Repo)
 			}
+			if got.URL != tc.wantURL {
+				t.Errorf("want URL %q, got URL %q", tc.wantURL, got.URL)
+			}
 		})
 	}
 }
@@ -24,6 +24,7 @@ import (
 	"net/http"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/git"
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/google/go-github/v48/github"
@@ -36,7 +37,7 @@ type patClient struct {
 	gh   *github.Client
 }
 
-func NewPATClient(ghCfg *config.GitHub) (Client, error) {
+func NewPATClient(ghCfg *config.GitHub) (forge.Client, error) {
 	token := util.Deref(ghCfg.Auth.Token, "")
 	if token == "" {
 		return nil, errors.New("missing GitHub PAT (Personal Access Token) config")
@@ -55,6 +56,10 @@ func NewPATClient(ghCfg *config.GitHub) (Client, error) {
 	}, nil
 }
 
+func (c *patClient) ParseRepoRef(remote string) (forge.RepoRef, error) {
+	return ParseRepoRef(remote)
+}
+
 func (c *patClient) TestConnection(ctx context.Context) error {
 	// get empty means get the currently authenticated user
 	user, _, err := c.gh.Users.Get(ctx, "")
@@ -68,16 +73,56 @@ func (c *patClient) TestConnection(ctx context.Context) error {
 	return nil
 }
 
-func (c *patClient) GitCredentialsForRepo(context.Context, RepoRef) (git.Credentials, error) {
+func (c *patClient) GitCredentialsForRepo(context.Context, forge.RepoRef) (git.Credentials, error) {
 	// use the same credentials for all repos
 	return c.cred, nil
 }
 
-func (c *patClient) CreatePullRequest(ctx context.Context, pr NewPullRequest) (PullRequest, error) {
+func (c *patClient) CreatePullRequest(ctx context.Context, pr forge.NewPullRequest) (forge.PullRequest, error) {
 	// use the same client for all repos
 	return CreatePullRequest(ctx, c.gh, pr)
 }
 
+func (c *patClient) FindPullRequest(ctx context.Context, repo forge.RepoRef, head, base string) (forge.PullRequest, error) {
+	return FindPullRequest(ctx, c.gh, repo, head, base)
+}
+
+func (c *patClient) UpdatePullRequest(ctx context.Context, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	return UpdatePullRequest(ctx, c.gh, number, pr)
+}
+
+func (c *patClient) ListPullRequests(ctx context.Context, repo forge.RepoRef, base string) ([]forge.PullRequest, error) {
+	return ListPullRequests(ctx, c.gh, repo, base)
+}
+
+func (c *patClient) ClosePullRequest(ctx context.Context, repo forge.RepoRef, number int, comment string) error {
+	return ClosePullRequest(ctx, c.gh, repo, number, comment)
+}
+
+func (c *patClient) CreateCommit(ctx context.Context, commit forge.NewCommit) (string, error) {
+	return CreateCommit(ctx, c.gh, commit)
+}
+
+func (c *patClient) CreateBranch(ctx context.Context, repo forge.RepoRef, branch, hash string) error {
+	return CreateBranch(ctx, c.gh, repo, branch, hash)
+}
+
+func (c *patClient) UpdateBranch(ctx context.Context, repo forge.RepoRef, branch, hash string) error {
+	return UpdateBranch(ctx, c.gh, repo, branch, hash)
+}
+
+func (c *patClient) DeleteBranch(ctx context.Context, repo forge.RepoRef, branch string) error {
+	return DeleteBranch(ctx, c.gh, repo, branch)
+}
+
+func (c *patClient) EnableAutoMerge(ctx context.Context, pr forge.PullRequest, method config.MergeMethod) error {
+	return EnableAutoMerge(ctx, c.gh, pr, method)
+}
+
+func (c *patClient) GetCommitChecks(ctx context.Context, repo forge.RepoRef, ref string) (forge.CommitChecks, error) {
+	return GetCommitChecks(ctx, c.gh, repo, ref)
+}
+
 func newOAuthHTTPClient(token string) *http.Client {
 	tokenSource := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
 	return oauth2.NewClient(context.TODO(), tokenSource)
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/google/go-github/v48/github"
+)
+
+// setPullRequestMetadata adds the labels, assignees, milestone, and
+// requested reviewers to the pull request, and updates the result
+// with the values returned by GitHub.
+func setPullRequestMetadata(ctx context.Context, gh *github.Client, pr forge.NewPullRequest, result *forge.PullRequest) error {
+	if len(pr.Labels) > 0 {
+		labels, _, err := gh.Issues.AddLabelsToIssue(ctx, pr.Owner, pr.Repo, result.Number, pr.Labels)
+		if err != nil {
+			return fmt.Errorf("add labels: %w", err)
+		}
+		result.Labels = labelNames(labels)
+	}
+	if len(pr.Assignees) > 0 {
+		issue, _, err := gh.Issues.AddAssignees(ctx, pr.Owner, pr.Repo, result.Number, pr.Assignees)
+		if err != nil {
+			return fmt.Errorf("add assignees: %w", err)
+		}
+		result.Assignees = userLogins(issue.Assignees)
+	}
+	if pr.Milestone != "" {
+		number, err := findMilestone(ctx, gh, pr.RepoRef, pr.Milestone)
+		if err != nil {
+			return err
+		}
+		issue, _, err := gh.Issues.Edit(ctx, pr.Owner, pr.Repo, result.Number, &github.IssueRequest{
+			Milestone: &number,
+		})
+		if err != nil {
+			return fmt.Errorf("set milestone: %w", err)
+		}
+		result.Milestone = issue.Milestone.GetTitle()
+	}
+	if len(pr.Reviewers) > 0 || len(pr.TeamReviewers) > 0 {
+		updated, _, err := gh.PullRequests.RequestReviewers(ctx, pr.Owner, pr.Repo, result.Number, github.ReviewersRequest{
+			Reviewers:     pr.Reviewers,
+			TeamReviewers: pr.TeamReviewers,
+		})
+		if err != nil {
+			return fmt.Errorf("request reviewers: %w", err)
+		}
+		result.Reviewers = userLogins(updated.RequestedReviewers)
+		result.TeamReviewers = teamSlugs(updated.RequestedTeams)
+	}
+	return nil
+}
+
+// findMilestone returns the number of the milestone, which is either
+// given as-is or looked up by its title among the open milestones.
+func findMilestone(ctx context.Context, gh *github.Client, repo forge.RepoRef, milestone string) (int, error) {
+	if number, err := strconv.Atoi(milestone); err == nil {
+		return number, nil
+	}
+	opts := &github.MilestoneListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		milestones, resp, err := gh.Issues.ListMilestones(ctx, repo.Owner, repo.Repo, opts)
+		if err != nil {
+			return 0, fmt.Errorf("list milestones: %w", err)
+		}
+		for _, m := range milestones {
+			if m.GetTitle() == milestone {
+				return m.GetNumber(), nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return 0, fmt.Errorf("no open milestone found with title %q", milestone)
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func labelNames(labels []*github.Label) []string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.GetName())
+	}
+	return names
+}
+
+func userLogins(users []*github.User) []string {
+	var logins []string
+	for _, user := range users {
+		logins = append(logins, user.GetLogin())
+	}
+	return logins
+}
+
+func teamSlugs(teams []*github.Team) []string {
+	var slugs []string
+	for _, team := range teams {
+		slugs = append(slugs, team.GetSlug())
+	}
+	return slugs
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi"
+	"github.com/rs/zerolog/log"
+)
+
+// Client is a GitLab API client. It implements [forge.Client], so that
+// GitLab merge requests can be used in place of GitHub pull requests.
+type Client struct {
+	api    forgeapi.Client
+	tokens []config.GitLabToken
+}
+
+// tokenAuth is how GitLab expects personal, project, and group access tokens.
+var tokenAuth = forgeapi.TokenAuth{Header: "PRIVATE-TOKEN"}
+
+func _() {
+	// Ensure the type implements the interfaces
+	var _ forge.Client = &Client{}
+}
+
+// New creates a GitLab client using the project and group access tokens
+// from the config.
+func New(cfg *config.GitLab) (*Client, error) {
+	if cfg.URL == nil || *cfg.URL == "" {
+		return nil, errors.New("missing GitLab URL config")
+	}
+	if _, err := url.Parse(*cfg.URL); err != nil {
+		return nil, fmt.Errorf("parse GitLab URL: %w", err)
+	}
+	return &Client{
+		api: forgeapi.Client{
+			BaseURL: strings.TrimRight(*cfg.URL, "/") + "/api/v4",
+			Auth:    tokenAuth,
+			HTTP:    http.DefaultClient,
+		},
+		tokens: cfg.Tokens,
+	}, nil
+}
+
+// ParseRepoRef parses the project from a remote URL. See [ParseRepoRef].
+func (c *Client) ParseRepoRef(remote string) (forge.RepoRef, error) {
+	return ParseRepoRef(remote)
+}
+
+func (c *Client) TestConnection(ctx context.Context) error {
+	if len(c.tokens) == 0 {
+		return errors.New("no GitLab tokens configured")
+	}
+	for _, t := range c.tokens {
+		var u user
+		if _, err := c.api.Do(ctx, t.Token, http.MethodGet, "/user", nil, nil, &u); err != nil {
+			return fmt.Errorf("get current GitLab user for token of %q: %w", t.Path, err)
+		}
+		log.Debug().
+			Str("path", t.Path).
+			Str("username", u.Username).
+			Msg("Authenticated as GitLab user.")
+	}
+	return nil
+}
+
+// GitCredentialsForRepo returns the project's access token, which GitLab
+// accepts as the password over HTTPS with any non-blank username.
+func (c *Client) GitCredentialsForRepo(_ context.Context, repo forge.RepoRef) (git.Credentials, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return git.Credentials{}, err
+	}
+	return git.Credentials{
+		Username: "oauth2",
+		Password: token,
+	}, nil
+}
+
+// tokenForRepo returns the token with the longest path that contains the
+// project, so a project access token is preferred over a group access token.
+func (c *Client) tokenForRepo(repo forge.RepoRef) (string, error) {
+	token, ok := forgeapi.LongestPathMatch(c.tokens, projectPath(repo), func(t config.GitLabToken) string {
+		return t.Path
+	})
+	if !ok {
+		return "", fmt.Errorf("no GitLab token configured for project: %s", projectPath(repo))
+	}
+	return token.Token, nil
+}
+
+// doProject sends a request to the project's API using the project's token.
+func (c *Client) doProject(ctx context.Context, repo forge.RepoRef, method, path string, query url.Values, body, result any) (*http.Response, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	path = "/projects/" + url.PathEscape(projectPath(repo)) + path
+	return c.api.Do(ctx, token, method, path, query, body, result)
+}
@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi"
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+)
+
+var testRepo = forge.RepoRef{
+	URL:   "https://gitlab.example.com/my-group/my-subgroup/my-project",
+	Owner: "my-group/my-subgroup",
+	Repo:  "my-project",
+}
+
+// newTestClient creates a GitLab client towards a fake GitLab API,
+// where the handler is registered using the given [http.ServeMux].
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+	server := forgeapitest.NewServer(t, mux)
+	return &Client{
+		api: forgeapi.Client{
+			BaseURL: server.URL + "/api/v4",
+			Auth:    tokenAuth,
+			HTTP:    server.Client(),
+		},
+		tokens: []config.GitLabToken{
+			{Path: "my-group", Token: "group-token"},
+			{Path: "my-group/my-subgroup/my-project", Token: "project-token"},
+		},
+	}
+}
+
+// handleProject registers a handler for a project API endpoint, which
+// checks that the project path and token are the expected ones.
+func handleProject(t *testing.T, mux *http.ServeMux, method, path string, handler http.HandlerFunc) {
+	mux.HandleFunc(method+" /api/v4/projects/{project}"+path, forgeapitest.RequireHeader(t, "PRIVATE-TOKEN", "project-token", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.PathValue("project"); got != "my-group/my-subgroup/my-project" {
+			t.Errorf("want project path %q, got %q", "my-group/my-subgroup/my-project", got)
+		}
+		handler(w, r)
+	}))
+}
+
+func TestTokenForRepo(t *testing.T) {
+	c := &Client{tokens: []config.GitLabToken{
+		{Path: "my-group", Token: "group-token"},
+		{Path: "my-group/my-project", Token: "project-token"},
+	}}
+	tests := []struct {
+		name    string
+		owner   string
+		repo    string
+		want    string
+		wantErr bool
+	}{
+		{name: "project token", owner: "my-group", repo: "my-project", want: "project-token"},
+		{name: "group token", owner: "my-group", repo: "other-project", want: "group-token"},
+		{name: "subgroup", owner: "My-Group/sub", repo: "my-project", want: "group-token"},
+		{name: "group with same prefix", owner: "my-group-2", repo: "my-project", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := c.tokenForRepo(forge.RepoRef{Owner: tc.owner, Repo: tc.repo})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("want error: %t, got: %v", tc.wantErr, err)
+			}
+			if got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	var got mergeRequestOptions
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		ids := map[string]int64{"alice": 1, "bob": 2}
+		forgeapitest.WriteJSON(t, w, []user{{ID: ids[r.URL.Query().Get("username")]}})
+	})
+	handleProject(t, mux, "GET", "/milestones", func(w http.ResponseWriter, r *http.Request) {
+		if title := r.URL.Query().Get("title"); title != "v2.0" {
+			t.Errorf("want milestone title %q, got %q", "v2.0", title)
+		}
+		forgeapitest.WriteJSON(t, w, []milestone{{ID: 7, Title: "v2.0"}})
+	})
+	handleProject(t, mux, "POST", "/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		forgeapitest.WriteJSON(t, w, mergeRequest{
+			ID:              100,
+			IID:             2,
+			ProjectID:       10,
+			SourceProjectID: 10,
+			WebURL:          "https://gitlab.example.com/my-group/my-subgroup/my-project/-/merge_requests/2",
+			Title:           got.Title,
+			SourceBranch:    got.SourceBranch,
+			TargetBranch:    got.TargetBranch,
+			SHA:             "abc123",
+			Labels:          []string{"dependencies", "jelease"},
+			Assignees:       []user{{ID: 1, Username: "alice"}},
+			Draft:           true,
+			Milestone:       &milestone{ID: 7, Title: "v2.0"},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	pr, err := c.CreatePullRequest(context.Background(), forge.NewPullRequest{
+		RepoRef:     testRepo,
+		Title:       "Update pkg to v2.0.0",
+		Description: "Description",
+		Head:        "jelease/pkg-v2.0.0",
+		Base:        "main",
+		Labels:      []string{"dependencies", "jelease"},
+		Reviewers:   []string{"bob"},
+		Assignees:   []string{"alice"},
+		Draft:       true,
+		Milestone:   "v2.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := mergeRequestOptions{
+		SourceBranch: "jelease/pkg-v2.0.0",
+		TargetBranch: "main",
+		Title:        "Draft: Update pkg to v2.0.0",
+		Description:  "Description",
+		Labels:       "dependencies,jelease",
+		AssigneeIDs:  []int64{1},
+		ReviewerIDs:  []int64{2},
+		MilestoneID:  7,
+	}
+	if got.Title != want.Title || got.Labels != want.Labels || got.MilestoneID != want.MilestoneID ||
+		got.SourceBranch != want.SourceBranch || got.TargetBranch != want.TargetBranch ||
+		!slices.Equal(got.AssigneeIDs, want.AssigneeIDs) || !slices.Equal(got.ReviewerIDs, want.ReviewerIDs) {
+		t.Errorf("wrong merge request options\nwant: %+v\ngot:  %+v", want, got)
+	}
+	if pr.Number != 2 || pr.HeadSHA != "abc123" || pr.Milestone != "v2.0" || !pr.Draft {
+		t.Errorf("wrong pull request: %+v", pr)
+	}
+	if pr.Head != "my-group/my-subgroup:jelease/pkg-v2.0.0" {
+		t.Errorf("want head %q, got %q", "my-group/my-subgroup:jelease/pkg-v2.0.0", pr.Head)
+	}
+}
+
+func TestFindPullRequest_ignoresForks(t *testing.T) {
+	mux := http.NewServeMux()
+	handleProject(t, mux, "GET", "/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != "opened" || query.Get("source_branch") != "jelease/pkg-v2.0.0" || query.Get("target_branch") != "main" {
+			t.Errorf("wrong query: %q", r.URL.RawQuery)
+		}
+		forgeapitest.WriteJSON(t, w, []mergeRequest{
+			{IID: 1, ProjectID: 10, SourceProjectID: 20, SourceBranch: "jelease/pkg-v2.0.0"},
+			{IID: 2, ProjectID: 10, SourceProjectID: 10, SourceBranch: "jelease/pkg-v2.0.0"},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	pr, err := c.FindPullRequest(context.Background(), testRepo, "jelease/pkg-v2.0.0", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 2 {
+		t.Errorf("want MR !2, got !%d", pr.Number)
+	}
+}
+
+func TestListPullRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	handleProject(t, mux, "GET", "/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("X-Next-Page", "2")
+			forgeapitest.WriteJSON(t, w, []mergeRequest{{IID: 1, ProjectID: 10, SourceProjectID: 10, SourceBranch: "jelease/pkg-v1.0.0"}})
+			return
+		}
+		forgeapitest.WriteJSON(t, w, []mergeRequest{{IID: 2, ProjectID: 10, SourceProjectID: 20, SourceBranch: "jelease/pkg-v1.1.0"}})
+	})
+	c := newTestClient(t, mux)
+
+	prs, err := c.ListPullRequests(context.Background(), testRepo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("want 2 MRs from both pages, got %d", len(prs))
+	}
+	if prs[0].Head != "my-group/my-subgroup:jelease/pkg-v1.0.0" {
+		t.Errorf("want head %q, got %q", "my-group/my-subgroup:jelease/pkg-v1.0.0", prs[0].Head)
+	}
+	if prs[1].Head != "20:jelease/pkg-v1.1.0" {
+		t.Errorf("want fork head %q, got %q", "20:jelease/pkg-v1.1.0", prs[1].Head)
+	}
+}
+
+func TestClosePullRequest(t *testing.T) {
+	var gotNote struct {
+		Body string `json:"body"`
+	}
+	var gotEdit mergeRequestOptions
+	mux := http.NewServeMux()
+	handleProject(t, mux, "POST", "/merge_requests/1/notes", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotNote)
+		forgeapitest.WriteJSON(t, w, map[string]any{"id": 1})
+	})
+	handleProject(t, mux, "PUT", "/merge_requests/1", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &gotEdit)
+		forgeapitest.WriteJSON(t, w, mergeRequest{IID: 1})
+	})
+	c := newTestClient(t, mux)
+
+	if err := c.ClosePullRequest(context.Background(), testRepo, 1, "Superseded by !2"); err != nil {
+		t.Fatal(err)
+	}
+	if gotNote.Body != "Superseded by !2" {
+		t.Errorf("want note %q, got %q", "Superseded by !2", gotNote.Body)
+	}
+	if gotEdit.StateEvent != "close" {
+		t.Errorf("want state event %q, got %q", "close", gotEdit.StateEvent)
+	}
+}
+
+func TestEnableAutoMerge(t *testing.T) {
+	var got map[string]bool
+	mux := http.NewServeMux()
+	handleProject(t, mux, "PUT", "/merge_requests/2/merge", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.DecodeBody(t, r, &got)
+		forgeapitest.WriteJSON(t, w, mergeRequest{IID: 2})
+	})
+	c := newTestClient(t, mux)
+
+	pr := forge.PullRequest{RepoRef: testRepo, Number: 2}
+	if err := c.EnableAutoMerge(context.Background(), pr, config.MergeMethodSquash); err != nil {
+		t.Fatal(err)
+	}
+	if !got["auto_merge"] || !got["merge_when_pipeline_succeeds"] || !got["squash"] {
+		t.Errorf("want auto-merge with squash, got %v", got)
+	}
+	if err := c.EnableAutoMerge(context.Background(), pr, config.MergeMethodRebase); err == nil {
+		t.Error("want error for rebase merge method, got nil")
+	}
+}
+
+func TestGetCommitChecks(t *testing.T) {
+	mux := http.NewServeMux()
+	handleProject(t, mux, "GET", "/repository/commits/abc123/statuses", func(w http.ResponseWriter, r *http.Request) {
+		forgeapitest.WriteJSON(t, w, []commitStatus{
+			{Name: "build", Status: "success"},
+			{Name: "lint", Status: "failed", AllowFailure: true},
+			{Name: "test", Status: "failed", TargetURL: "https://gitlab.example.com/jobs/3"},
+			{Name: "deploy", Status: "manual"},
+		})
+	})
+	c := newTestClient(t, mux)
+
+	checks, err := c.GetCommitChecks(context.Background(), testRepo, "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checks.State != forge.CheckStateFailure {
+		t.Errorf("want state %q, got %q", forge.CheckStateFailure, checks.State)
+	}
+	wantFailed := []forge.Check{{Name: "test", URL: "https://gitlab.example.com/jobs/3", State: forge.CheckStateFailure}}
+	if !slices.Equal(checks.Failed(), wantFailed) {
+		t.Errorf("want failed checks %v, got %v", wantFailed, checks.Failed())
+	}
+}
+
+func TestGitCredentialsForRepo(t *testing.T) {
+	c := newTestClient(t, http.NewServeMux())
+	cred, err := c.GitCredentialsForRepo(context.Background(), testRepo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Username == "" || cred.Password != "project-token" {
+		t.Errorf("want non-blank username and project token, got %q / %q", cred.Username, cred.Password)
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+// ParseRepoRef parses the project path from a remote URL. GitLab projects
+// can be in nested groups, so the [forge.RepoRef] Owner is the full path
+// of the group, e.g "my-group/my-subgroup", and Repo is the project name.
+//
+// SSH remotes are converted to the HTTPS URL of the same host.
+func ParseRepoRef(remote string) (forge.RepoRef, error) {
+	if git.IsSSHRemote(remote) {
+		httpsRemote, err := git.SSHRemoteToHTTPS(remote)
+		if err != nil {
+			return forge.RepoRef{}, err
+		}
+		remote = httpsRemote
+	}
+	u, err := url.Parse(remote)
+	if err != nil {
+		return forge.RepoRef{}, err
+	}
+	// Links to pages inside the project, such as
+	// "/my-group/my-project/-/merge_requests", have the project path before "/-/"
+	path, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/-/")
+	path = strings.TrimSuffix(path, ".git")
+	owner, repo, ok := cutLast(path, "/")
+	if !ok || owner == "" || repo == "" {
+		return forge.RepoRef{}, fmt.Errorf("expected https://host/GROUP/PROJECT in URL, got: %s", remote)
+	}
+	return forge.RepoRef{
+		URL:   (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: path}).String(),
+		Owner: owner,
+		Repo:  repo,
+	}, nil
+}
+
+// projectPath returns the full path of the project, including all groups.
+func projectPath(repo forge.RepoRef) string {
+	return repo.Owner + "/" + repo.Repo
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
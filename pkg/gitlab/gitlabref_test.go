@@ -0,0 +1,85 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gitlab
+
+import (
+	"testing"
+)
+
+func TestParseRepoRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		remote    string
+		wantURL   string
+		wantOwner string
+		wantRepo  string
+	}{
+		{
+			name:      "regular",
+			remote:    "https://gitlab.example.com/my-group/my-project",
+			wantURL:   "https://gitlab.example.com/my-group/my-project",
+			wantOwner: "my-group",
+			wantRepo:  "my-project",
+		},
+		{
+			name:      "nested groups with .git",
+			remote:    "https://gitlab.example.com/my-group/my-subgroup/my-project.git",
+			wantURL:   "https://gitlab.example.com/my-group/my-subgroup/my-project",
+			wantOwner: "my-group/my-subgroup",
+			wantRepo:  "my-project",
+		},
+		{
+			name:      "link to page in project",
+			remote:    "https://gitlab.example.com/my-group/my-project/-/merge_requests?scope=all",
+			wantURL:   "https://gitlab.example.com/my-group/my-project",
+			wantOwner: "my-group",
+			wantRepo:  "my-project",
+		},
+		{
+			name:      "ssh scp-like",
+			remote:    "git@gitlab.example.com:my-group/my-subgroup/my-project.git",
+			wantURL:   "https://gitlab.example.com/my-group/my-subgroup/my-project",
+			wantOwner: "my-group/my-subgroup",
+			wantRepo:  "my-project",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseRepoRef(tc.remote)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Owner != tc.wantOwner {
+				t.Errorf("want owner %q, got owner %q", tc.wantOwner, got.Owner)
+			}
+			if got.Repo != tc.wantRepo {
+				t.Errorf("want repo %q, got repo %q", tc.wantRepo, got.Repo)
+			}
+			if got.URL != tc.wantURL {
+				t.Errorf("want URL %q, got URL %q", tc.wantURL, got.URL)
+			}
+		})
+	}
+}
+
+func TestParseRepoRef_missingGroup(t *testing.T) {
+	if _, err := ParseRepoRef("https://gitlab.example.com/my-project"); err == nil {
+		t.Error("want error, got nil")
+	}
+}
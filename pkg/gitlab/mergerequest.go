@@ -0,0 +1,372 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/rs/zerolog/log"
+)
+
+type mergeRequest struct {
+	ID              int64      `json:"id"`
+	IID             int        `json:"iid"`
+	ProjectID       int64      `json:"project_id"`
+	SourceProjectID int64      `json:"source_project_id"`
+	WebURL          string     `json:"web_url"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	SourceBranch    string     `json:"source_branch"`
+	TargetBranch    string     `json:"target_branch"`
+	SHA             string     `json:"sha"`
+	Labels          []string   `json:"labels"`
+	Assignees       []user     `json:"assignees"`
+	Reviewers       []user     `json:"reviewers"`
+	Draft           bool       `json:"draft"`
+	Milestone       *milestone `json:"milestone"`
+}
+
+type mergeRequestOptions struct {
+	SourceBranch string  `json:"source_branch,omitempty"`
+	TargetBranch string  `json:"target_branch,omitempty"`
+	Title        string  `json:"title,omitempty"`
+	Description  string  `json:"description,omitempty"`
+	Labels       string  `json:"labels,omitempty"`
+	AddLabels    string  `json:"add_labels,omitempty"`
+	AssigneeIDs  []int64 `json:"assignee_ids,omitempty"`
+	ReviewerIDs  []int64 `json:"reviewer_ids,omitempty"`
+	MilestoneID  int64   `json:"milestone_id,omitempty"`
+	StateEvent   string  `json:"state_event,omitempty"`
+}
+
+type user struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type milestone struct {
+	ID    int64  `json:"id"`
+	IID   int    `json:"iid"`
+	Title string `json:"title"`
+}
+
+func newPullRequest(repo forge.RepoRef, mr mergeRequest, commit git.Commit) forge.PullRequest {
+	// Same format as the GitHub head label, "owner:branch", which is used
+	// to tell merge requests from forks apart
+	head := repo.Owner + ":" + mr.SourceBranch
+	if mr.SourceProjectID != mr.ProjectID {
+		head = strconv.FormatInt(mr.SourceProjectID, 10) + ":" + mr.SourceBranch
+	}
+	var milestoneTitle string
+	if mr.Milestone != nil {
+		milestoneTitle = mr.Milestone.Title
+	}
+	return forge.PullRequest{
+		RepoRef:     repo,
+		ID:          mr.ID,
+		Number:      mr.IID,
+		URL:         mr.WebURL,
+		Title:       mr.Title,
+		Description: mr.Description,
+		Head:        head,
+		HeadBranch:  mr.SourceBranch,
+		HeadSHA:     mr.SHA,
+		Base:        mr.TargetBranch,
+		Labels:      mr.Labels,
+		Reviewers:   usernames(mr.Reviewers),
+		Assignees:   usernames(mr.Assignees),
+		Draft:       mr.Draft,
+		Milestone:   milestoneTitle,
+		Commit:      commit,
+	}
+}
+
+func usernames(users []user) []string {
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	return names
+}
+
+// CreatePullRequest creates a merge request with the labels, assignees,
+// reviewers, and milestone. Drafts are created by prefixing the title
+// with "Draft:". GitLab has no team reviewers, so those are ignored.
+func (c *Client) CreatePullRequest(ctx context.Context, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	opts, err := c.mergeRequestMetadata(ctx, pr)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	opts.SourceBranch = pr.Head
+	opts.TargetBranch = pr.Base
+	opts.Title = pr.Title
+	if pr.Draft {
+		opts.Title = "Draft: " + pr.Title
+	}
+	opts.Description = pr.Description
+	opts.Labels = strings.Join(pr.Labels, ",")
+
+	var mr mergeRequest
+	if _, err := c.doProject(ctx, pr.RepoRef, http.MethodPost, "/merge_requests", nil, opts, &mr); err != nil {
+		return forge.PullRequest{}, err
+	}
+	return newPullRequest(pr.RepoRef, mr, pr.Commit), nil
+}
+
+// UpdatePullRequest sets the title and description of an existing merge
+// request, adds the labels, and sets the assignees, reviewers,
+// and milestone if any are configured.
+func (c *Client) UpdatePullRequest(ctx context.Context, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	opts, err := c.mergeRequestMetadata(ctx, pr)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	opts.Title = pr.Title
+	opts.Description = pr.Description
+	opts.AddLabels = strings.Join(pr.Labels, ",")
+
+	var mr mergeRequest
+	path := fmt.Sprintf("/merge_requests/%d", number)
+	if _, err := c.doProject(ctx, pr.RepoRef, http.MethodPut, path, nil, opts, &mr); err != nil {
+		return forge.PullRequest{}, err
+	}
+	result := newPullRequest(pr.RepoRef, mr, pr.Commit)
+	result.Updated = true
+	return result, nil
+}
+
+// mergeRequestMetadata looks up the IDs of the assignees, reviewers,
+// and milestone, as the GitLab API doesn't accept them by name.
+func (c *Client) mergeRequestMetadata(ctx context.Context, pr forge.NewPullRequest) (mergeRequestOptions, error) {
+	if len(pr.TeamReviewers) > 0 {
+		log.Warn().Strs("teamReviewers", pr.TeamReviewers).
+			Msg("GitLab does not support team reviewers. Ignoring them.")
+	}
+	var opts mergeRequestOptions
+	var err error
+	if opts.AssigneeIDs, err = c.findUserIDs(ctx, pr.RepoRef, pr.Assignees); err != nil {
+		return opts, fmt.Errorf("find assignees: %w", err)
+	}
+	if opts.ReviewerIDs, err = c.findUserIDs(ctx, pr.RepoRef, pr.Reviewers); err != nil {
+		return opts, fmt.Errorf("find reviewers: %w", err)
+	}
+	if pr.Milestone != "" {
+		if opts.MilestoneID, err = c.findMilestoneID(ctx, pr.RepoRef, pr.Milestone); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func (c *Client) findUserIDs(ctx context.Context, repo forge.RepoRef, usernames []string) ([]int64, error) {
+	token, err := c.tokenForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, username := range usernames {
+		var users []user
+		query := url.Values{"username": {username}}
+		if _, err := c.api.Do(ctx, token, http.MethodGet, "/users", query, nil, &users); err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("GitLab user not found: %s", username)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+// findMilestoneID returns the ID of the project or group milestone with
+// the given title, or with the given project-specific number.
+func (c *Client) findMilestoneID(ctx context.Context, repo forge.RepoRef, titleOrNumber string) (int64, error) {
+	query := url.Values{"include_ancestors": {"true"}}
+	if _, err := strconv.Atoi(titleOrNumber); err == nil {
+		query.Set("iids[]", titleOrNumber)
+	} else {
+		query.Set("title", titleOrNumber)
+	}
+	var milestones []milestone
+	if _, err := c.doProject(ctx, repo, http.MethodGet, "/milestones", query, nil, &milestones); err != nil {
+		return 0, fmt.Errorf("find milestone: %w", err)
+	}
+	if len(milestones) == 0 {
+		return 0, fmt.Errorf("GitLab milestone not found: %s", titleOrNumber)
+	}
+	return milestones[0].ID, nil
+}
+
+// FindPullRequest returns the open merge request from the head branch into
+// the base branch, or [forge.ErrPullRequestNotFound]. Merge requests
+// from forks are ignored.
+func (c *Client) FindPullRequest(ctx context.Context, repo forge.RepoRef, head, base string) (forge.PullRequest, error) {
+	var mrs []mergeRequest
+	query := url.Values{
+		"state":         {"opened"},
+		"source_branch": {head},
+		"target_branch": {base},
+	}
+	if _, err := c.doProject(ctx, repo, http.MethodGet, "/merge_requests", query, nil, &mrs); err != nil {
+		return forge.PullRequest{}, err
+	}
+	for _, mr := range mrs {
+		if mr.SourceProjectID == mr.ProjectID {
+			return newPullRequest(repo, mr, git.Commit{}), nil
+		}
+	}
+	return forge.PullRequest{}, fmt.Errorf("%w: %s:%s", forge.ErrPullRequestNotFound, repo.Owner, head)
+}
+
+func (c *Client) ListPullRequests(ctx context.Context, repo forge.RepoRef, base string) ([]forge.PullRequest, error) {
+	query := url.Values{
+		"state":         {"opened"},
+		"target_branch": {base},
+		"per_page":      {"100"},
+	}
+	var result []forge.PullRequest
+	for {
+		var mrs []mergeRequest
+		resp, err := c.doProject(ctx, repo, http.MethodGet, "/merge_requests", query, nil, &mrs)
+		if err != nil {
+			return nil, err
+		}
+		for _, mr := range mrs {
+			result = append(result, newPullRequest(repo, mr, git.Commit{}))
+		}
+		nextPage := resp.Header.Get("X-Next-Page")
+		if nextPage == "" {
+			return result, nil
+		}
+		query.Set("page", nextPage)
+	}
+}
+
+func (c *Client) ClosePullRequest(ctx context.Context, repo forge.RepoRef, number int, comment string) error {
+	path := fmt.Sprintf("/merge_requests/%d", number)
+	if comment != "" {
+		body := map[string]string{"body": comment}
+		if _, err := c.doProject(ctx, repo, http.MethodPost, path+"/notes", nil, body, nil); err != nil {
+			return fmt.Errorf("comment on merge request: %w", err)
+		}
+	}
+	opts := mergeRequestOptions{StateEvent: "close"}
+	if _, err := c.doProject(ctx, repo, http.MethodPut, path, nil, opts, nil); err != nil {
+		return fmt.Errorf("close merge request: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteBranch(ctx context.Context, repo forge.RepoRef, branch string) error {
+	path := "/repository/branches/" + url.PathEscape(branch)
+	_, err := c.doProject(ctx, repo, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// CreateCommit is not supported, so the "api" publish mode can't be used
+// with GitLab.
+func (c *Client) CreateCommit(context.Context, forge.NewCommit) (string, error) {
+	return "", fmt.Errorf("create commit via GitLab API: %w", errors.ErrUnsupported)
+}
+
+func (c *Client) CreateBranch(context.Context, forge.RepoRef, string, string) error {
+	return fmt.Errorf("create branch via GitLab API: %w", errors.ErrUnsupported)
+}
+
+func (c *Client) UpdateBranch(context.Context, forge.RepoRef, string, string) error {
+	return fmt.Errorf("update branch via GitLab API: %w", errors.ErrUnsupported)
+}
+
+// EnableAutoMerge sets the merge request to be merged when the pipeline
+// succeeds. GitLab only allows rebasing as a project-wide merge method,
+// so only the merge and squash methods are supported.
+func (c *Client) EnableAutoMerge(ctx context.Context, pr forge.PullRequest, method config.MergeMethod) error {
+	if method == config.MergeMethodRebase {
+		return fmt.Errorf("enable auto-merge on MR %s: merge method %s: %w", pr.URL, method, errors.ErrUnsupported)
+	}
+	body := map[string]bool{
+		"auto_merge": true,
+		// Older name of auto_merge, from before GitLab 17.11
+		"merge_when_pipeline_succeeds": true,
+		"squash":                       method == config.MergeMethodSquash,
+	}
+	path := fmt.Sprintf("/merge_requests/%d/merge", pr.Number)
+	if _, err := c.doProject(ctx, pr.RepoRef, http.MethodPut, path, nil, body, nil); err != nil {
+		return fmt.Errorf("enable auto-merge on MR %s: %w", pr.URL, err)
+	}
+	return nil
+}
+
+type commitStatus struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	TargetURL    string `json:"target_url"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+// GetCommitChecks returns the statuses of a commit, which includes the
+// jobs of its pipelines.
+func (c *Client) GetCommitChecks(ctx context.Context, repo forge.RepoRef, ref string) (forge.CommitChecks, error) {
+	path := "/repository/commits/" + url.PathEscape(ref) + "/statuses"
+	query := url.Values{"per_page": {"100"}}
+	var checks []forge.Check
+	for {
+		var statuses []commitStatus
+		resp, err := c.doProject(ctx, repo, http.MethodGet, path, query, nil, &statuses)
+		if err != nil {
+			return forge.CommitChecks{}, err
+		}
+		for _, status := range statuses {
+			checks = append(checks, forge.Check{
+				Name:  status.Name,
+				URL:   status.TargetURL,
+				State: commitStatusState(status),
+			})
+		}
+		nextPage := resp.Header.Get("X-Next-Page")
+		if nextPage == "" {
+			return forge.NewCommitChecks(checks), nil
+		}
+		query.Set("page", nextPage)
+	}
+}
+
+func commitStatusState(status commitStatus) forge.CheckState {
+	switch status.Status {
+	case "success", "skipped", "manual":
+		return forge.CheckStateSuccess
+	case "failed":
+		if status.AllowFailure {
+			return forge.CheckStateSuccess
+		}
+		return forge.CheckStateFailure
+	case "canceled":
+		return forge.CheckStateFailure
+	default:
+		return forge.CheckStatePending
+	}
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+// Package forgeapi contains the parts shared by the REST API clients of the
+// forges that don't have their own client library, such as GitLab and
+// Bitbucket.
+package forgeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TokenAuth is how a forge expects the access token in requests.
+type TokenAuth struct {
+	// Header is the name of the HTTP header, e.g "Authorization".
+	Header string
+	// Prefix is written before the token in the header, e.g "Bearer ".
+	Prefix string
+}
+
+// Client sends JSON requests to a REST API.
+type Client struct {
+	// BaseURL is prepended to the path of each request.
+	BaseURL string
+	Auth    TokenAuth
+	HTTP    *http.Client
+}
+
+// Do sends a request with the body encoded as JSON, if not nil, and decodes
+// the JSON response into the result, if not nil. Responses with a status
+// code of 300 or above are returned as errors.
+func (c *Client) Do(ctx context.Context, token, method, path string, query url.Values, body, result any) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	reqURL := c.BaseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(c.Auth.Header, c.Auth.Prefix+token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return resp, fmt.Errorf("%s %s: decode response: %w", method, path, err)
+		}
+	}
+	return resp, nil
+}
+
+// LongestPathMatch returns the token whose path is the longest one that
+// equals or contains the path, ignoring case. This way a token of a
+// repository is preferred over a token of its group or project.
+func LongestPathMatch[T any](tokens []T, path string, tokenPath func(T) string) (T, bool) {
+	path = strings.ToLower(path)
+	var match T
+	longest := -1
+	for _, t := range tokens {
+		p := strings.ToLower(strings.Trim(tokenPath(t), "/"))
+		if path != p && !strings.HasPrefix(path, p+"/") {
+			continue
+		}
+		if len(p) > longest {
+			match = t
+			longest = len(p)
+		}
+	}
+	return match, longest != -1
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package forgeapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/internal/forgeapi/forgeapitest"
+)
+
+func TestLongestPathMatch(t *testing.T) {
+	tokens := []string{"my-group/", "my-group/my-project", "other"}
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "my-group/my-project", want: "my-group/my-project", wantOK: true},
+		{path: "My-Group/My-Project", want: "my-group/my-project", wantOK: true},
+		{path: "my-group/other-project", want: "my-group/", wantOK: true},
+		{path: "my-group-2/my-project", wantOK: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got, ok := LongestPathMatch(tokens, tc.path, func(s string) string { return s })
+			if ok != tc.wantOK || got != tc.want {
+				t.Errorf("want %q (%t), got %q (%t)", tc.want, tc.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/items", forgeapitest.RequireHeader(t, "Authorization", "Bearer my-token", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		forgeapitest.DecodeBody(t, r, &body)
+		forgeapitest.WriteJSON(t, w, map[string]string{"name": body["name"] + r.URL.Query().Get("suffix")})
+	}))
+	mux.HandleFunc("GET /api/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	server := forgeapitest.NewServer(t, mux)
+	c := &Client{
+		BaseURL: server.URL + "/api",
+		Auth:    TokenAuth{Header: "Authorization", Prefix: "Bearer "},
+		HTTP:    server.Client(),
+	}
+
+	var result map[string]string
+	query := map[string][]string{"suffix": {"-2"}}
+	if _, err := c.Do(context.Background(), "my-token", http.MethodPost, "/items", query, map[string]string{"name": "item"}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result["name"] != "item-2" {
+		t.Errorf("want name %q, got %q", "item-2", result["name"])
+	}
+
+	_, err := c.Do(context.Background(), "my-token", http.MethodGet, "/missing", nil, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found: not found") {
+		t.Errorf("want not found error, got: %v", err)
+	}
+}
@@ -24,42 +24,80 @@ import (
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/patch/filestore"
 	"github.com/RiskIdent/jelease/pkg/patch/patches"
+	"github.com/rs/zerolog/log"
 )
 
 // ApplyMany applies a series of patches in sequence using [Apply].
-func ApplyMany(repoDir string, patches []config.PackageRepoPatch, tmplCtx config.TemplateContext) error {
-	for _, p := range patches {
-		if err := Apply(repoDir, p, tmplCtx); err != nil {
-			return err
+// Patches that would downgrade the version, as decided by their version
+// policy, are not applied and are instead returned as skipped.
+// The RepoURL of the returned skipped patches is left empty.
+//
+// The versions found in the files before patching are also returned,
+// for the patch types that support it.
+func ApplyMany(repoDir string, patchList []config.PackageRepoPatch, tmplCtx config.TemplateContext) ([]string, []Skipped, error) {
+	var prevVersions []string
+	var skipped []Skipped
+	for i, p := range patchList {
+		versions, err := Apply(repoDir, p, tmplCtx)
+		if errors.Is(err, patches.ErrDowngrade) {
+			log.Info().Int("patch", i+1).Err(err).Msg("Skipping patch.")
+			skipped = append(skipped, Skipped{PatchIndex: i, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return prevVersions, skipped, err
 		}
+		prevVersions = append(prevVersions, versions...)
 	}
-	return nil
+	return prevVersions, skipped, nil
 }
 
-// Apply applies a single patch to the repository.
-func Apply(repoDir string, patch config.PackageRepoPatch, tmplCtx config.TemplateContext) error {
+// Apply applies a single patch to the repository. The versions found in the
+// file before patching are returned by the regex and YAML patches.
+func Apply(repoDir string, patch config.PackageRepoPatch, tmplCtx config.TemplateContext) ([]string, error) {
 	fstore := filestore.NewCached(repoDir)
 	defer fstore.Close()
+	var prevVersions []string
 	switch {
 	case patch.Regex != nil:
-		if err := patches.ApplyRegexPatch(fstore, tmplCtx, *patch.Regex); err != nil {
-			return fmt.Errorf("regex patch: %w", err)
+		versions, err := patches.ApplyRegexPatch(fstore, tmplCtx, *patch.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("regex patch: %w", err)
 		}
+		prevVersions = versions
 	case patch.YAML != nil:
-		if err := patches.ApplyYAMLPatch(fstore, tmplCtx, *patch.YAML); err != nil {
-			return fmt.Errorf("yaml patch: %w", err)
+		versions, err := patches.ApplyYAMLPatch(fstore, tmplCtx, *patch.YAML)
+		if err != nil {
+			return nil, fmt.Errorf("yaml patch: %w", err)
+		}
+		prevVersions = versions
+	case patch.HCL != nil:
+		if err := patches.ApplyHCLPatch(fstore, tmplCtx, *patch.HCL); err != nil {
+			return nil, fmt.Errorf("hcl patch: %w", err)
+		}
+	case patch.Changelog != nil:
+		if err := patches.ApplyChangelogPatch(fstore, tmplCtx, *patch.Changelog); err != nil {
+			return nil, fmt.Errorf("changelog patch: %w", err)
+		}
+	case patch.File != nil:
+		if err := patches.ApplyFilePatch(fstore, tmplCtx, *patch.File); err != nil {
+			return nil, fmt.Errorf("file patch: %w", err)
 		}
 	case patch.HelmDepUpdate != nil:
 		// Flush the store as we need the up-to-date changes on disk
 		if err := fstore.Flush(); err != nil {
-			return err
+			return nil, err
 		}
 		if err := patches.ApplyHelmDepUpdatePatch(repoDir, tmplCtx, *patch.HelmDepUpdate); err != nil {
-			return fmt.Errorf("exec patch: %w", err)
+			return nil, fmt.Errorf("exec patch: %w", err)
+		}
+	case len(patch.Custom) > 0:
+		if err := patches.DefaultRegistry.ApplyCustomPatch(fstore, tmplCtx, patch.Custom); err != nil {
+			return nil, err
 		}
 	default:
-		return errors.New("missing patch type config")
+		return nil, errors.New("missing patch type config")
 	}
 
-	return fstore.Close()
+	return prevVersions, fstore.Close()
 }
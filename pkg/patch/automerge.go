@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/version"
+	"github.com/rs/zerolog/log"
+)
+
+var versionChanges = []config.VersionChange{
+	config.VersionChangePatch,
+	config.VersionChangeMinor,
+	config.VersionChangeMajor,
+}
+
+// enableAutoMerge enables auto-merge on the pull request if configured, and
+// if the version change is within the allowed max change.
+// Failures are only logged, as the pull request is already created.
+func (p *Repo) enableAutoMerge(pr *forge.PullRequest) {
+	if !p.autoMerge.Enabled {
+		return
+	}
+	if err := checkAutoMergeVersionChange(p.autoMerge.MaxChange, p.prevVersions, p.tmplCtx.Version); err != nil {
+		log.Info().Err(err).Str("url", pr.URL).
+			Msg("Not enabling auto-merge on PR.")
+		return
+	}
+	if err := p.forge.EnableAutoMerge(context.TODO(), *pr, p.autoMerge.Method); err != nil {
+		log.Warn().Err(err).Str("url", pr.URL).
+			Msg("Failed to enable auto-merge on PR.")
+		return
+	}
+	pr.AutoMerge = true
+	log.Info().Str("url", pr.URL).Stringer("method", p.autoMerge.Method).
+		Msg("Enabled auto-merge on PR.")
+}
+
+// checkAutoMergeVersionChange returns an error if the change from any of the
+// previous versions to the new version is larger than the max change.
+// The previous versions are needed, so it also returns an error if the
+// patches found none.
+func checkAutoMergeVersionChange(maxChange config.VersionChange, prevVersions []string, next string) error {
+	if maxChange == "" {
+		maxChange = config.VersionChangePatch
+	}
+	maxIndex := slices.Index(versionChanges, maxChange)
+	if maxIndex == -1 {
+		return fmt.Errorf("unsupported version change: %q", maxChange)
+	}
+	change, prev, err := largestVersionChange(prevVersions, next)
+	if err != nil {
+		return err
+	}
+	if slices.Index(versionChanges, change) > maxIndex {
+		return fmt.Errorf("%s version change from %s to %s is larger than the allowed %s change", change, prev, next, maxChange)
+	}
+	return nil
+}
+
+// largestVersionChange returns the largest change from any of the previous
+// versions to the new version, and the previous version it was from.
+// Returns an error if there are no previous versions, or if any of the
+// versions can't be parsed.
+func largestVersionChange(prevVersions []string, next string) (config.VersionChange, string, error) {
+	if len(prevVersions) == 0 {
+		return "", "", errors.New("no previous version found by the patches, which is needed to check the version change")
+	}
+	nextVer, err := version.Parse(next)
+	if err != nil {
+		return "", "", fmt.Errorf("parse release version %q: %w", next, err)
+	}
+	var largest config.VersionChange
+	var largestPrev string
+	for _, prev := range prevVersions {
+		prevVer, err := version.Parse(prev)
+		if err != nil {
+			return "", "", fmt.Errorf("parse previous version %q: %w", prev, err)
+		}
+		change := versionChangeBetween(prevVer, nextVer)
+		if slices.Index(versionChanges, change) > slices.Index(versionChanges, largest) {
+			largest = change
+			largestPrev = prev
+		}
+	}
+	return largest, largestPrev, nil
+}
+
+// versionChangeBetween returns the first version segment that differs,
+// where anything after the minor version counts as a patch change.
+func versionChangeBetween(a, b version.Version) config.VersionChange {
+	switch {
+	case versionSegment(a, 0) != versionSegment(b, 0):
+		return config.VersionChangeMajor
+	case versionSegment(a, 1) != versionSegment(b, 1):
+		return config.VersionChangeMinor
+	default:
+		return config.VersionChangePatch
+	}
+}
+
+func versionSegment(v version.Version, index int) uint {
+	if index >= len(v.Segments) {
+		return 0
+	}
+	return v.Segments[index]
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+func TestCheckAutoMergeVersionChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxChange    config.VersionChange
+		prevVersions []string
+		next         string
+		wantErr      bool
+	}{
+		{name: "patch", prevVersions: []string{"v1.2.3"}, next: "v1.2.4"},
+		{name: "patch with more segments", prevVersions: []string{"1.2.3.4"}, next: "1.2.3.5"},
+		{name: "prerelease to release", prevVersions: []string{"v1.2.3-rc.1"}, next: "v1.2.3"},
+		{name: "minor with default max", prevVersions: []string{"v1.2.3"}, next: "v1.3.0", wantErr: true},
+		{name: "minor", maxChange: config.VersionChangeMinor, prevVersions: []string{"v1.2.3"}, next: "v1.3.0"},
+		{name: "major with minor max", maxChange: config.VersionChangeMinor, prevVersions: []string{"v1.2.3"}, next: "v2.0.0", wantErr: true},
+		{name: "major", maxChange: config.VersionChangeMajor, prevVersions: []string{"v1.2.3"}, next: "v2.0.0"},
+		{name: "largest of multiple", prevVersions: []string{"v1.2.3", "v1.1.0"}, next: "v1.2.4", wantErr: true},
+		{name: "no previous", next: "v1.2.4", wantErr: true},
+		{name: "unparsable previous", prevVersions: []string{"latest"}, next: "v1.2.4", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkAutoMergeVersionChange(tc.maxChange, tc.prevVersions, tc.next)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("want error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPublishChanges_autoMerge(t *testing.T) {
+	tests := []struct {
+		name          string
+		autoMerge     config.PackageAutoMerge
+		prevVersions  []string
+		wantAutoMerge bool
+	}{
+		{
+			name:          "patch",
+			autoMerge:     config.PackageAutoMerge{Enabled: true, Method: config.MergeMethodSquash},
+			prevVersions:  []string{"v1.2.3"},
+			wantAutoMerge: true,
+		},
+		{
+			name:         "minor",
+			autoMerge:    config.PackageAutoMerge{Enabled: true, Method: config.MergeMethodSquash},
+			prevVersions: []string{"v1.1.0"},
+		},
+		{
+			name:         "disabled",
+			prevVersions: []string{"v1.2.3"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeForge{}
+			cfg := &config.Config{}
+			cfg.GitHub.PR.Title = mustTemplate(t, "Update {{ .Package }}")
+			cfg.GitHub.PR.Description = mustTemplate(t, "Description")
+			repo := &Repo{
+				forge:        fake,
+				repo:         &fakeGitRepo{},
+				cfg:          cfg,
+				tmplCtx:      config.TemplateContext{Package: "pkg", Version: "v1.2.4"},
+				autoMerge:    tc.autoMerge,
+				prevVersions: tc.prevVersions,
+			}
+
+			pr, err := repo.PublishChanges(git.Commit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pr.AutoMerge != tc.wantAutoMerge {
+				t.Errorf("want PR auto-merge %t, got %t", tc.wantAutoMerge, pr.AutoMerge)
+			}
+			wantMethod := config.MergeMethod("")
+			if tc.wantAutoMerge {
+				wantMethod = tc.autoMerge.Method
+			}
+			if fake.autoMerged != wantMethod {
+				t.Errorf("want auto-merge method %q, got %q", wantMethod, fake.autoMerged)
+			}
+		})
+	}
+}
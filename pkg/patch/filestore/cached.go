@@ -18,11 +18,17 @@
 package filestore
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 )
 
+// ErrPathOutsideDir is returned when a path is absolute or uses ".."
+// to point outside of the file store's directory.
+var ErrPathOutsideDir = errors.New("path is outside of the directory")
+
 func NewCached(dir string) *Cached {
 	return &Cached{
 		Dir:   dir,
@@ -34,9 +40,13 @@ func NewCached(dir string) *Cached {
 // but adds in-memory caching in between.
 // The files are never written to disk until the [Cached.Flush]
 // or [FileStore.Close] method is called.
+//
+// All paths are resolved relative to Dir using [os.Root], so absolute paths,
+// ".." escapes, and symlinks pointing outside of Dir are refused.
 type Cached struct {
 	Dir   string
 	files map[string]*File
+	root  *os.Root
 }
 
 // ensure it implements the interface
@@ -45,30 +55,48 @@ var _ FileStore = &Cached{}
 type File struct {
 	Content []byte
 	Mode    fs.FileMode
+	// Created is set for files written by [Cached.CreateFile], which may
+	// need their parent directories and permissions set on flush.
+	Created bool
+	// Deleted is set for files removed by [Cached.DeleteFile].
+	Deleted bool
 }
 
+// defaultFileMode is used for new files when no permissions are given.
+const defaultFileMode fs.FileMode = 0o644
+
 func (s *Cached) ReadFile(path string) ([]byte, error) {
-	path = filepath.Clean(path)
+	path, err := cleanLocalPath(path)
+	if err != nil {
+		return nil, err
+	}
 	if file, ok := s.files[path]; ok {
+		if file.Deleted {
+			return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrNotExist}
+		}
 		return file.Content, nil
 	}
-	// TODO: Check that the patch path doesn't go outside the repo dir.
-	// For example, reject stuff like "../../../somefile.txt"
-	absPath := filepath.Join(s.Dir, path)
-	stat, err := os.Stat(absPath)
+	root, err := s.openRoot()
+	if err != nil {
+		return nil, err
+	}
+	stat, err := root.Stat(path)
 	if err != nil {
 		return nil, err
 	}
-	content, err := os.ReadFile(absPath)
+	content, err := root.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	s.files[path] = &File{content, stat.Mode()}
+	s.files[path] = &File{Content: content, Mode: stat.Mode()}
 	return content, nil
 }
 
 func (s *Cached) WriteFile(path string, content []byte) error {
-	path = filepath.Clean(path)
+	path, err := cleanLocalPath(path)
+	if err != nil {
+		return err
+	}
 	if _, ok := s.files[path]; !ok {
 		// Load it
 		_, err := s.ReadFile(path)
@@ -76,13 +104,53 @@ func (s *Cached) WriteFile(path string, content []byte) error {
 			return err
 		}
 	}
-	s.files[path].Content = content
+	file := s.files[path]
+	if file.Deleted {
+		return &fs.PathError{Op: "write", Path: path, Err: fs.ErrNotExist}
+	}
+	file.Content = content
+	return nil
+}
+
+func (s *Cached) CreateFile(path string, content []byte, perm fs.FileMode) error {
+	path, err := cleanLocalPath(path)
+	if err != nil {
+		return err
+	}
+	if perm == 0 {
+		perm = defaultFileMode
+		if _, err := s.ReadFile(path); err == nil {
+			perm = s.files[path].Mode
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	s.files[path] = &File{Content: content, Mode: perm, Created: true}
+	return nil
+}
+
+func (s *Cached) DeleteFile(path string) error {
+	path, err := cleanLocalPath(path)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ReadFile(path); err != nil {
+		return err
+	}
+	s.files[path] = &File{Deleted: true}
 	return nil
 }
 
 func (s *Cached) Flush() error {
+	if len(s.files) == 0 {
+		return nil
+	}
+	root, err := s.openRoot()
+	if err != nil {
+		return err
+	}
 	for path, file := range s.files {
-		if err := os.WriteFile(filepath.Join(s.Dir, path), file.Content, file.Mode); err != nil {
+		if err := flushFile(root, path, file); err != nil {
 			return err
 		}
 	}
@@ -90,6 +158,54 @@ func (s *Cached) Flush() error {
 	return nil
 }
 
+func flushFile(root *os.Root, path string, file *File) error {
+	if file.Deleted {
+		err := root.Remove(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if !file.Created {
+		return root.WriteFile(path, file.Content, file.Mode)
+	}
+	if err := root.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := root.WriteFile(path, file.Content, file.Mode); err != nil {
+		return err
+	}
+	// The mode is only used by WriteFile for new files, and is affected
+	// by the umask, so set it explicitly
+	return root.Chmod(path, file.Mode)
+}
+
 func (s *Cached) Close() error {
-	return s.Flush()
+	err := s.Flush()
+	if s.root != nil {
+		err = errors.Join(err, s.root.Close())
+		s.root = nil
+	}
+	return err
+}
+
+func (s *Cached) openRoot() (*os.Root, error) {
+	if s.root != nil {
+		return s.root, nil
+	}
+	root, err := os.OpenRoot(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	s.root = root
+	return root, nil
+}
+
+// cleanLocalPath cleans the path, and makes sure it's a relative path that
+// stays inside the directory. Symlinks are checked by [os.Root] later.
+func cleanLocalPath(path string) (string, error) {
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("%w: %q", ErrPathOutsideDir, path)
+	}
+	return filepath.Clean(path), nil
 }
@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package filestore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCached_readWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "sub", "file.txt"), "hello")
+
+	s := NewCached(dir)
+	if err := s.WriteFile("sub/../sub/file.txt", []byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	content, err := s.ReadFile("sub/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "world" {
+		t.Errorf("want cached %q, got %q", "world", content)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	onDisk, err := os.ReadFile(filepath.Join(dir, "sub", "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(onDisk) != "world" {
+		t.Errorf("want on disk %q, got %q", "world", onDisk)
+	}
+}
+
+func TestCached_symlinkInsideDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "target.txt"), "hello")
+	if err := os.Symlink("target.txt", filepath.Join(dir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewCached(dir)
+	defer s.Close()
+	content, err := s.ReadFile("link.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "hello" {
+		t.Errorf("want %q, got %q", "hello", content)
+	}
+}
+
+func TestCached_refusesEscapes(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "repo")
+	outsideFile := filepath.Join(parent, "secret.txt")
+	writeTestFile(t, outsideFile, "secret")
+	writeTestFile(t, filepath.Join(dir, "file.txt"), "hello")
+	if err := os.Symlink(outsideFile, filepath.Join(dir, "abs-link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../secret.txt", filepath.Join(dir, "rel-link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(parent, filepath.Join(dir, "dir-link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantLexical bool
+	}{
+		{
+			name:        "absolute path",
+			path:        outsideFile,
+			wantLexical: true,
+		},
+		{
+			name:        "dot-dot",
+			path:        "../secret.txt",
+			wantLexical: true,
+		},
+		{
+			name:        "dot-dot after subdir",
+			path:        "sub/../../secret.txt",
+			wantLexical: true,
+		},
+		{
+			name: "symlink with absolute target",
+			path: "abs-link.txt",
+		},
+		{
+			name: "symlink with relative target",
+			path: "rel-link.txt",
+		},
+		{
+			name: "symlinked dir",
+			path: "dir-link/secret.txt",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewCached(dir)
+			defer s.Close()
+
+			_, readErr := s.ReadFile(tc.path)
+			writeErr := s.WriteFile(tc.path, []byte("pwned"))
+			deleteErr := s.DeleteFile(tc.path)
+			for _, err := range []error{readErr, writeErr, deleteErr} {
+				if err == nil {
+					t.Fatalf("want error for %q, got nil", tc.path)
+				}
+				if !strings.Contains(err.Error(), filepath.Clean(tc.path)) && !strings.Contains(err.Error(), tc.path) {
+					t.Errorf("want error to name the path %q, got: %s", tc.path, err)
+				}
+				if tc.wantLexical && !errors.Is(err, ErrPathOutsideDir) {
+					t.Errorf("want ErrPathOutsideDir, got: %s", err)
+				}
+			}
+		})
+	}
+
+	content, err := os.ReadFile(outsideFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "secret" {
+		t.Errorf("file outside of dir was changed: %q", content)
+	}
+}
+
+func TestCached_createFile(t *testing.T) {
+	dir := t.TempDir()
+	s := NewCached(dir)
+	if err := s.CreateFile("docs/migrations/v2.md", []byte("# v2\n"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateFile("bin/run.sh", []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, wantMode := range map[string]os.FileMode{
+		"docs/migrations/v2.md": 0o644,
+		"bin/run.sh":            0o755,
+	} {
+		stat, err := os.Stat(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat.Mode().Perm() != wantMode {
+			t.Errorf("%s: want mode %o, got %o", path, wantMode, stat.Mode().Perm())
+		}
+	}
+}
+
+func TestCached_createFileKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.sh")
+	writeTestFile(t, path, "old")
+	if err := os.Chmod(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewCached(dir)
+	if err := s.CreateFile("run.sh", []byte("new"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != 0o700 {
+		t.Errorf("want mode %o, got %o", 0o700, stat.Mode().Perm())
+	}
+}
+
+func TestCached_deleteFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "old.yaml"), "foo: bar")
+
+	s := NewCached(dir)
+	if err := s.DeleteFile("old.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReadFile("old.yaml"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want cached file to be deleted, got: %v", err)
+	}
+	if err := s.DeleteFile("old.yaml"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want error on deleting twice, got: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want file to be deleted on disk, got: %v", err)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -19,6 +19,8 @@
 // OS operations for reading and writing to files.
 package filestore
 
+import "io/fs"
+
 // FileStore is a minimal abstraction over reading and writing files
 // that is used in the patching steps (e.g yaml patch, regex patch, etc).
 //
@@ -27,6 +29,14 @@ package filestore
 // multiple patches on the same file (see [Cached]).
 type FileStore interface {
 	ReadFile(path string) ([]byte, error)
+	// WriteFile changes the content of an existing file.
 	WriteFile(path string, content []byte) error
+	// CreateFile writes the file, creating it and its parent directories
+	// if it doesn't exist. A perm of 0 keeps the permissions of an existing
+	// file, or uses 0644 for new files.
+	CreateFile(path string, content []byte, perm fs.FileMode) error
+	// DeleteFile removes the file. It returns an error satisfying
+	// errors.Is(err, fs.ErrNotExist) if it's missing.
+	DeleteFile(path string) error
 	Close() error
 }
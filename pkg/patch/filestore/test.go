@@ -17,7 +17,10 @@
 
 package filestore
 
-import "os"
+import (
+	"io/fs"
+	"os"
+)
 
 func NewTestFileStore(files map[string]string) *TestFileStore {
 	return &TestFileStore{
@@ -47,6 +50,19 @@ func (s *TestFileStore) WriteFile(path string, content []byte) error {
 	return nil
 }
 
+func (s *TestFileStore) CreateFile(path string, content []byte, _ fs.FileMode) error {
+	s.files[path] = string(content)
+	return nil
+}
+
+func (s *TestFileStore) DeleteFile(path string) error {
+	if _, ok := s.files[path]; !ok {
+		return os.ErrNotExist
+	}
+	delete(s.files, path)
+	return nil
+}
+
 func (s *TestFileStore) Close() error {
 	return nil
 }
@@ -18,44 +18,81 @@
 package patch
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/RiskIdent/jelease/pkg/bitbucket"
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/git"
 	"github.com/RiskIdent/jelease/pkg/github"
+	"github.com/RiskIdent/jelease/pkg/gitlab"
 	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/rs/zerolog/log"
 )
 
 var (
-	ErrNoPatches = errors.New("no patches configured for repository")
+	ErrNoPatches    = errors.New("no patches configured for repository")
+	ErrBranchExists = errors.New("branch already exists in remote repository")
 )
 
 // Patcher is the manager for managing repositories and patching them,
-// as well as pushing the changes in form of GitHub pull requests.
+// as well as pushing the changes in form of GitHub pull requests,
+// GitLab merge requests, or Bitbucket pull requests.
 //
 // This is the main integration code between the other packages.
 type Patcher struct {
-	cfg *config.Config
-	gh  github.Client
+	cfg   *config.Config
+	forge forge.Client
 }
 
 // New creates a new [Patcher] using a base config.
 func New(cfg *config.Config) (Patcher, error) {
-	gh, err := github.New(&cfg.GitHub)
+	f, err := newForge(cfg)
 	if err != nil {
 		return Patcher{}, err
 	}
 	return Patcher{
-		cfg: cfg,
-		gh:  gh,
+		cfg:   cfg,
+		forge: f,
 	}, nil
 }
 
+// newForge creates a client that uses GitHub for all repositories, except
+// for those on the hosts of the GitLab and Bitbucket URLs, if configured.
+func newForge(cfg *config.Config) (forge.Client, error) {
+	gh, err := github.New(&cfg.GitHub)
+	if err != nil {
+		return nil, err
+	}
+	r := forge.NewRouter(gh)
+	if cfg.GitLab.URL != nil && *cfg.GitLab.URL != "" {
+		gl, err := gitlab.New(&cfg.GitLab)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.AddHost(*cfg.GitLab.URL, gl); err != nil {
+			return nil, fmt.Errorf("GitLab: %w", err)
+		}
+	}
+	if cfg.Bitbucket.URL != nil && *cfg.Bitbucket.URL != "" {
+		bb, err := bitbucket.New(&cfg.Bitbucket)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.AddHost(*cfg.Bitbucket.URL, bb); err != nil {
+			return nil, fmt.Errorf("Bitbucket: %w", err)
+		}
+	}
+	return r, nil
+}
+
 // CloneWithConfig creates a copy of this object, but with a new [config.Config]
 // applied. Useful if you want to change just a few fields, such as the dry-run
 // setting, while still reusing the GitHub App cache.
@@ -64,94 +101,290 @@ func (p Patcher) CloneWithConfig(cfg *config.Config) Patcher {
 	return p
 }
 
-// TestGitHubConnection is practically a ping towards GitHub, to ensure the
-// credentials from the config are working.
-func (p Patcher) TestGitHubConnection(ctx context.Context) error {
-	if err := p.gh.TestConnection(context.TODO()); err != nil {
-		return fmt.Errorf("test GitHub connection: %w", err)
+// Forge returns the client used by the patcher for GitHub, GitLab, etc.
+func (p Patcher) Forge() forge.Client {
+	return p.forge
+}
+
+// TestConnection is practically a ping towards GitHub, and GitLab and
+// Bitbucket if configured, to ensure the credentials from the config are working.
+func (p Patcher) TestConnection(ctx context.Context) error {
+	if err := p.forge.TestConnection(ctx); err != nil {
+		return fmt.Errorf("test forge connection: %w", err)
 	}
 	return nil
 }
 
-// CloneAndPublishAll will clone a list of Git repository, apply all the
-// configured patches, and then publish the changes in the form of GitHub
-// pull requests.
-func (p Patcher) CloneAndPublishAll(pkgRepos []config.PackageRepo, tmplCtx config.TemplateContext) ([]github.PullRequest, error) {
-	if len(pkgRepos) == 0 {
+// Result is the record of patching all repositories of a package.
+type Result struct {
+	PullRequests []forge.PullRequest
+	Skipped      []Skipped
+	// UpToDate is the URLs of the repositories where the patches
+	// produced no changes, meaning they're already up to date.
+	UpToDate []string
+}
+
+// Skipped is a repository or patch that was not applied.
+type Skipped struct {
+	RepoURL string
+	// PatchIndex is the zero-based index of the skipped patch,
+	// or -1 if the whole repository was skipped.
+	PatchIndex int
+	Reason     string
+}
+
+func (s Skipped) String() string {
+	if s.PatchIndex < 0 {
+		return fmt.Sprintf("repo %s: %s", s.RepoURL, s.Reason)
+	}
+	return fmt.Sprintf("repo %s, patch #%d: %s", s.RepoURL, s.PatchIndex+1, s.Reason)
+}
+
+// CloneAndPublishAll will clone the package's Git repositories, apply all the
+// configured patches, and then publish the changes in the form of pull
+// requests, with auto-merge enabled if configured for the package.
+// Repositories and patches whose `when` condition does not render "true",
+// or patches that would downgrade the version, are skipped and listed in
+// the result.
+//
+// Repositories with multiple base branches get one pull request per branch.
+func (p Patcher) CloneAndPublishAll(pkg config.Package, tmplCtx config.TemplateContext) (Result, error) {
+	if len(pkg.Repos) == 0 {
 		log.Warn().Str("package", tmplCtx.Package).Msg("No repos configured for package.")
-		return nil, nil
+		return Result{}, nil
 	}
 
-	var prs []github.PullRequest
-	for _, pkgRepo := range pkgRepos {
-		log.Info().Str("repo", pkgRepo.URL).Msg("Patching repo")
-		pr, err := p.CloneAndPublishRepo(pkgRepo, tmplCtx)
-		if errors.Is(err, ErrNoPatches) {
+	var result Result
+	for _, pkgRepo := range pkg.Repos {
+		pkgRepo, skipped, ok, err := filterRepoByWhen(pkgRepo, tmplCtx)
+		result.Skipped = append(result.Skipped, skipped...)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
 			continue
 		}
+		bases, branchesSkipped, err := filterBaseBranches(pkgRepo, tmplCtx)
+		result.Skipped = append(result.Skipped, branchesSkipped...)
+		if err != nil {
+			return result, err
+		}
+		suffixBase, err := p.prBranchesCollide(bases, tmplCtx)
 		if err != nil {
-			return prs, err
+			return result, err
+		}
+		for _, base := range bases {
+			baseCtx := tmplCtx
+			baseCtx.BaseBranch = base
+			branchSuffix := ""
+			if suffixBase {
+				branchSuffix = "-" + strings.ReplaceAll(base, "/", "-")
+			}
+			log.Info().Str("repo", pkgRepo.URL).Str("base", base).Msg("Patching repo")
+			pr, patchesSkipped, err := p.cloneAndPublishRepo(pkgRepo, baseCtx, branchSuffix, pkg.AutoMerge)
+			for _, s := range patchesSkipped {
+				s.PatchIndex = unfilteredPatchIndex(s.PatchIndex, skipped)
+				result.Skipped = append(result.Skipped, s)
+			}
+			if errors.Is(err, ErrNoPatches) {
+				continue
+			}
+			if errors.Is(err, git.ErrNoChanges) {
+				if len(patchesSkipped) == len(pkgRepo.Patches) {
+					log.Info().Str("repo", pkgRepo.URL).Str("base", base).Msg("All patches were skipped.")
+					continue
+				}
+				log.Info().Str("repo", pkgRepo.URL).Str("base", base).Msg("Patches produced no changes. Repo is already up to date.")
+				if !slices.Contains(result.UpToDate, pkgRepo.URL) {
+					result.UpToDate = append(result.UpToDate, pkgRepo.URL)
+				}
+				continue
+			}
+			if err != nil {
+				return result, err
+			}
+			result.PullRequests = append(result.PullRequests, pr)
 		}
-		prs = append(prs, pr)
 	}
 
 	log.Info().Str("package", tmplCtx.Package).Msg("Done applying patches")
-	return prs, nil
+	return result, nil
+}
+
+// prBranchesCollide reports whether the configured PR branch name renders
+// the same for multiple of the base branches, in which case the base branch
+// name has to be added to the PR branch name to keep them unique.
+func (p Patcher) prBranchesCollide(bases []string, tmplCtx config.TemplateContext) (bool, error) {
+	if len(bases) < 2 {
+		return false, nil
+	}
+	seen := make(map[string]bool, len(bases))
+	for _, base := range bases {
+		tmplCtx.BaseBranch = base
+		name, err := p.cfg.GitHub.PR.Branch.Render(tmplCtx)
+		if err != nil {
+			return false, fmt.Errorf("template branch name: %w", err)
+		}
+		if seen[name] {
+			return true, nil
+		}
+		seen[name] = true
+	}
+	return false, nil
 }
 
 // CloneAndPublishRepo will clone a Git repository, apply all the configured
-// patches, and then publish the changes in the form of a GitHub pull requests.
-func (p Patcher) CloneAndPublishRepo(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) (github.PullRequest, error) {
-	repo, err := p.CloneRepo(pkgRepo.URL, tmplCtx)
+// patches, and then publish the changes in the form of pull requests.
+// The pull request targets the [config.TemplateContext.BaseBranch] if set,
+// or else the repository's default branch.
+// The patches that were skipped by their version policy are returned,
+// even on error.
+func (p Patcher) CloneAndPublishRepo(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) (forge.PullRequest, []Skipped, error) {
+	return p.cloneAndPublishRepo(pkgRepo, tmplCtx, "", config.PackageAutoMerge{})
+}
+
+func (p Patcher) cloneAndPublishRepo(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext, branchSuffix string, autoMerge config.PackageAutoMerge) (forge.PullRequest, []Skipped, error) {
+	repo, err := p.CloneRepo(pkgRepo, tmplCtx)
 	if err != nil {
-		return github.PullRequest{}, err
+		return forge.PullRequest{}, nil, err
 	}
 	defer repo.Close()
+	repo.branchSuffix = branchSuffix
+	repo.autoMerge = autoMerge
 
-	commit, err := repo.ApplyManyAndCommit(pkgRepo.Patches)
+	commit, skipped, err := repo.ApplyManyAndCommit(pkgRepo.Patches)
 	if err != nil {
-		return github.PullRequest{}, err
+		return forge.PullRequest{}, skipped, err
 	}
 
-	return repo.PublishChangesUnlessDryRun(commit)
+	pr, err := repo.PublishChangesUnlessDryRun(commit)
+	return pr, skipped, err
 }
 
-// CloneRepo will download a Git repository from GitHub using the configured
-// credentials. It is cloned using HTTPS instead of SSH.
-func (p Patcher) CloneRepo(remote string, tmplCtx config.TemplateContext) (*Repo, error) {
+// CloneRepo will download a Git repository from GitHub, or from GitLab or
+// Bitbucket if the remote is on their host, using the configured credentials.
+// HTTPS remotes use the forge's credentials, while SSH remotes
+// use the configured SSH key.
+//
+// The [config.TemplateContext.BaseBranch] is cloned if set, or else the
+// repository's default branch, which is then set as the base branch.
+// With sparse checkout enabled, only the paths needed by the patches
+// are checked out.
+func (p Patcher) CloneRepo(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) (*Repo, error) {
+	remote := pkgRepo.URL
 	// Check this early so we don't fail right on the finish line
-	ghRef, err := github.ParseRepoRef(remote)
+	repoRef, err := p.forge.ParseRepoRef(remote)
 	if err != nil {
 		return nil, err
 	}
-	gitCred, err := p.gh.GitCredentialsForRepo(context.TODO(), ghRef)
+	gitCred, err := p.gitCredentials(remote, repoRef, pkgRepo.SSHKeyPath)
 	if err != nil {
 		return nil, err
 	}
-	g := git.Cmd{
-		Credentials: gitCred,
-		Committer: git.Committer{
-			Name:  util.Deref(p.cfg.GitHub.PR.Committer.Name, ""),
-			Email: util.Deref(p.cfg.GitHub.PR.Committer.Email, ""),
-		},
+	signer, err := newCommitSigner(p.cfg.GitHub.PR.Committer.Signing)
+	if err != nil {
+		return nil, err
 	}
-	repo, err := cloneRepoTemp(g, util.Deref(p.cfg.GitHub.TempDir, os.TempDir()), remote)
+	g, err := newGit(p.cfg.GitHub, gitCred, git.Committer{
+		Name:   util.Deref(p.cfg.GitHub.PR.Committer.Name, ""),
+		Email:  util.Deref(p.cfg.GitHub.PR.Committer.Email, ""),
+		Signer: signer,
+	})
 	if err != nil {
 		return nil, err
 	}
+	sparse, err := sparsePaths(pkgRepo, tmplCtx)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := cloneRepoTemp(g, util.Deref(p.cfg.GitHub.TempDir, os.TempDir()), remote, git.CloneOptions{
+		Branch:      tmplCtx.BaseBranch,
+		SparsePaths: sparse,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tmplCtx.BaseBranch = repo.MainBranch()
 	return &Repo{
-		gh:      p.gh,
-		ghRef:   ghRef,
-		remote:  remote,
-		repo:    repo,
-		cfg:     p.cfg,
-		tmplCtx: tmplCtx,
+		forge:      p.forge,
+		repoRef:    repoRef,
+		remote:     remote,
+		repo:       repo,
+		cfg:        p.cfg,
+		tmplCtx:    tmplCtx,
+		prMetadata: pkgRepo.PR,
 	}, nil
 }
 
-func cloneRepoTemp(g git.Git, tempDir, remote string) (git.Repo, error) {
+// gitCredentials returns the credentials used for cloning and pushing.
+// The forge's credentials are still used for its API
+// when the remote is an SSH remote.
+func (p Patcher) gitCredentials(remote string, repoRef forge.RepoRef, sshKeyPath *string) (git.Credentials, error) {
+	if git.IsSSHRemote(remote) {
+		return git.Credentials{SSH: &git.SSHCredentials{
+			PrivateKeyPath: util.Deref(cmp.Or(sshKeyPath, p.cfg.GitHub.SSH.PrivateKeyPath), ""),
+			KnownHostsPath: util.Deref(p.cfg.GitHub.SSH.KnownHostsPath, ""),
+		}}, nil
+	}
+	return p.forge.GitCredentialsForRepo(context.TODO(), repoRef)
+}
+
+func newGit(cfg config.GitHub, cred git.Credentials, committer git.Committer) (git.Git, error) {
+	mirror := newMirrorCache(cfg.MirrorCache)
+	switch cfg.GitBackend {
+	case "", config.GitBackendCmd:
+		return git.Cmd{Credentials: cred, Committer: committer, Mirror: mirror}, nil
+	case config.GitBackendGoGit:
+		if mirror != nil {
+			log.Warn().Msg("The Git mirror cache is only supported by the cmd Git backend. Cloning without it.")
+		}
+		return git.GoGit{Credentials: cred, Committer: committer}, nil
+	default:
+		return nil, fmt.Errorf("unsupported Git backend: %q", cfg.GitBackend)
+	}
+}
+
+func newCommitSigner(cfg config.GitHubCommitSigning) (git.Signer, error) {
+	if cfg.Format == "" || cfg.Format == config.CommitSigningFormatNone {
+		return nil, nil
+	}
+	var key []byte
+	switch {
+	case cfg.PrivateKeyPEM != nil && *cfg.PrivateKeyPEM != "":
+		key = []byte(*cfg.PrivateKeyPEM)
+	case cfg.PrivateKeyPath != nil && *cfg.PrivateKeyPath != "":
+		b, err := os.ReadFile(*cfg.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("read commit signing config privateKeyPath: %w", err)
+		}
+		key = b
+	default:
+		return nil, errors.New("must set commit signing config privateKeyPem or privateKeyPath")
+	}
+	switch cfg.Format {
+	case config.CommitSigningFormatSSH:
+		return git.NewSSHSigner(key)
+	case config.CommitSigningFormatGPG:
+		return git.NewGPGSigner(key)
+	default:
+		return nil, fmt.Errorf("unsupported commit signing format: %q", cfg.Format)
+	}
+}
+
+func newMirrorCache(cfg config.GitMirrorCache) *git.MirrorCache {
+	if cfg.Dir == nil || *cfg.Dir == "" {
+		return nil
+	}
+	return &git.MirrorCache{
+		Dir:     *cfg.Dir,
+		MaxAge:  cfg.MaxAge.Duration(),
+		MaxSize: cfg.MaxSizeMiB * 1024 * 1024,
+	}
+}
+
+func cloneRepoTemp(g git.Git, tempDir, remote string, opts git.CloneOptions) (git.Repo, error) {
 	targetDir := filepath.Join(tempDir, "jelease", "cloned-repos", "repo-*")
-	repo, err := git.CloneTemp(g, targetDir, remote)
+	repo, err := git.CloneTemp(g, targetDir, remote, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	defaultChangelogSection      = regexp.MustCompile(`(?i)^##\s+\[?unreleased\]?\s*$`)
+	defaultChangelogSectionTitle = "## [Unreleased]"
+)
+
+// ApplyChangelogPatch adds an entry to a section in a Markdown changelog,
+// creating the section and subsection if needed. Applying the same
+// patch twice only adds the entry once.
+func ApplyChangelogPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchChangelog) error {
+	log.Debug().Str("file", patch.File).Str("subsection", patch.Subsection).Msg("Patching changelog.")
+
+	if patch.File == "" {
+		return fmt.Errorf("missing required field 'file'")
+	}
+	if patch.Entry == nil {
+		return fmt.Errorf("missing required field 'entry'")
+	}
+
+	sectionRegex := defaultChangelogSection
+	if patch.Section != nil {
+		sectionRegex = patch.Section.Regexp()
+	}
+	sectionTitle := defaultChangelogSectionTitle
+	if patch.SectionTitle != "" {
+		sectionTitle = patch.SectionTitle
+	}
+
+	entry, err := patch.Entry.Render(tmplCtx)
+	if err != nil {
+		return fmt.Errorf("execute entry template: %w", err)
+	}
+	entryLines := strings.Split(strings.TrimRight(entry, "\n"), "\n")
+	if strings.TrimSpace(entryLines[0]) == "" {
+		return fmt.Errorf("entry template rendered an empty string")
+	}
+
+	content, err := fstore.ReadFile(patch.File)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	sectionStart := slices.IndexFunc(lines, func(line string) bool {
+		return sectionRegex.MatchString(line)
+	})
+	if sectionStart == -1 {
+		log.Debug().Str("file", patch.File).Str("title", sectionTitle).Msg("Changelog section not found. Adding it.")
+		// Add the new section before the first release, which is below
+		// the changelog's title and description.
+		sectionStart = slices.IndexFunc(lines, func(line string) bool {
+			return markdownHeadingLevel(line) == 2
+		})
+		if sectionStart == -1 {
+			sectionStart = len(lines)
+			if sectionStart > 0 && lines[sectionStart-1] == "" {
+				sectionStart--
+			}
+			if sectionStart > 0 {
+				lines = slices.Insert(lines, sectionStart, "")
+				sectionStart++
+			}
+			lines = slices.Insert(lines, sectionStart, sectionTitle)
+		} else {
+			lines = slices.Insert(lines, sectionStart, sectionTitle, "")
+		}
+	}
+	sectionEnd := findMarkdownSectionEnd(lines, sectionStart)
+
+	for _, line := range lines[sectionStart+1 : sectionEnd] {
+		if strings.TrimSpace(line) == strings.TrimSpace(entryLines[0]) {
+			log.Debug().Str("file", patch.File).Str("entry", entryLines[0]).Msg("Changelog already contains entry. Skipping.")
+			return nil
+		}
+	}
+
+	if patch.Subsection == "" {
+		// Add it before any subsections
+		blockEnd := sectionEnd
+		for i := sectionStart + 1; i < sectionEnd; i++ {
+			if markdownHeadingLevel(lines[i]) != 0 {
+				blockEnd = i
+				break
+			}
+		}
+		lines = insertMarkdownBlockEntry(lines, sectionStart, blockEnd, entryLines)
+	} else {
+		subsectionStart := -1
+		for i := sectionStart + 1; i < sectionEnd; i++ {
+			if markdownHeadingLevel(lines[i]) == 3 &&
+				strings.EqualFold(markdownHeadingText(lines[i]), patch.Subsection) {
+				subsectionStart = i
+				break
+			}
+		}
+		if subsectionStart == -1 {
+			log.Debug().Str("file", patch.File).Str("subsection", patch.Subsection).Msg("Changelog subsection not found. Adding it.")
+			subsectionStart = lastNonBlankLine(lines, sectionStart, sectionEnd) + 1
+			lines = slices.Insert(lines, subsectionStart, "", "### "+patch.Subsection)
+			subsectionStart++
+		}
+		lines = insertMarkdownBlockEntry(lines, subsectionStart, findMarkdownSectionEnd(lines, subsectionStart), entryLines)
+	}
+
+	return fstore.WriteFile(patch.File, []byte(strings.Join(lines, "\n")))
+}
+
+// insertMarkdownBlockEntry inserts the entry after the last non-blank line
+// between the heading at index start and the index end, while making sure
+// the entry is separated from surrounding headings by blank lines.
+func insertMarkdownBlockEntry(lines []string, start, end int, entryLines []string) []string {
+	last := lastNonBlankLine(lines, start, end)
+	if last != start {
+		return slices.Insert(lines, last+1, entryLines...)
+	}
+	insert := append([]string{""}, entryLines...)
+	if start+1 >= len(lines) || lines[start+1] != "" {
+		insert = append(insert, "")
+	}
+	return slices.Insert(lines, start+1, insert...)
+}
+
+// lastNonBlankLine returns the index of the last non-blank line between
+// start and end, or start if all lines are blank.
+func lastNonBlankLine(lines []string, start, end int) int {
+	for i := end - 1; i > start; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			return i
+		}
+	}
+	return start
+}
+
+// findMarkdownSectionEnd returns the index of the next heading of the same
+// or lower level as the heading at index start, or len(lines) if none.
+func findMarkdownSectionEnd(lines []string, start int) int {
+	level := markdownHeadingLevel(lines[start])
+	if level == 0 {
+		level = 2
+	}
+	for i := start + 1; i < len(lines); i++ {
+		if l := markdownHeadingLevel(lines[i]); l != 0 && l <= level {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// markdownHeadingLevel returns the number of leading hashes of an
+// ATX-style heading, such as 2 for "## Foo", or 0 if it's not a heading.
+func markdownHeadingLevel(line string) int {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return 0
+	}
+	if len(line) > level && line[level] != ' ' && line[level] != '\t' {
+		return 0
+	}
+	return level
+}
+
+func markdownHeadingText(line string) string {
+	return strings.TrimSpace(strings.TrimLeft(line, "#"))
+}
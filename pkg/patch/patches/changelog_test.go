@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+)
+
+func TestApplyChangelogPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		subsection string
+		want       string
+	}{
+		{
+			name: "existing subsection",
+			content: `# Changelog
+
+## [Unreleased]
+
+### Changed
+
+- Some other change
+
+## [v1.0.0] - 2026-01-01
+
+- Initial release
+`,
+			subsection: "Changed",
+			want: `# Changelog
+
+## [Unreleased]
+
+### Changed
+
+- Some other change
+- Updated foo to v2.0.0
+
+## [v1.0.0] - 2026-01-01
+
+- Initial release
+`,
+		},
+		{
+			name: "missing subsection",
+			content: `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- Some feature
+
+## [v1.0.0] - 2026-01-01
+`,
+			subsection: "Changed",
+			want: `# Changelog
+
+## [Unreleased]
+
+### Added
+
+- Some feature
+
+### Changed
+
+- Updated foo to v2.0.0
+
+## [v1.0.0] - 2026-01-01
+`,
+		},
+		{
+			name: "missing section",
+			content: `# Changelog
+
+## [v1.0.0] - 2026-01-01
+`,
+			subsection: "Changed",
+			want: `# Changelog
+
+## [Unreleased]
+
+### Changed
+
+- Updated foo to v2.0.0
+
+## [v1.0.0] - 2026-01-01
+`,
+		},
+		{
+			name: "missing section without releases",
+			content: `# Changelog
+
+All notable changes to this project will be documented in this file.
+`,
+			want: `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+
+- Updated foo to v2.0.0
+`,
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want: `## [Unreleased]
+
+- Updated foo to v2.0.0
+`,
+		},
+		{
+			name: "no subsection",
+			content: `## Unreleased
+- Some other change
+## v1.0.0
+`,
+			want: `## Unreleased
+- Some other change
+- Updated foo to v2.0.0
+## v1.0.0
+`,
+		},
+		{
+			name: "already added",
+			content: `## [Unreleased]
+
+### Changed
+
+- Updated foo to v2.0.0
+`,
+			subsection: "Changed",
+			want: `## [Unreleased]
+
+### Changed
+
+- Updated foo to v2.0.0
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"CHANGELOG.md": tc.content,
+			})
+			patch := config.PatchChangelog{
+				File:       "CHANGELOG.md",
+				Subsection: tc.subsection,
+				Entry:      newTemplate(t, `- Updated {{ .Package }} to {{ .Version }}`),
+			}
+			tmplCtx := config.TemplateContext{Package: "foo", Version: "v2.0.0"}
+			if err := ApplyChangelogPatch(fstore, tmplCtx, patch); err != nil {
+				t.Fatal(err)
+			}
+			got, err := fstore.ReadFile("CHANGELOG.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+
+			// Applying it again should not add another entry
+			if err := ApplyChangelogPatch(fstore, tmplCtx, patch); err != nil {
+				t.Fatal(err)
+			}
+			got, err = fstore.ReadFile("CHANGELOG.md")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result on second run\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestApplyChangelogPatch_customSection(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"CHANGES.md": "# Changes\n\n## Next release\n\n## 1.0.0\n",
+	})
+	patch := config.PatchChangelog{
+		File:    "CHANGES.md",
+		Section: newRegex(t, `^## Next release$`),
+		Entry:   newTemplate(t, `- {{ .Package }} {{ .Version }}`),
+	}
+	if err := ApplyChangelogPatch(fstore, config.TemplateContext{Package: "foo", Version: "v2.0.0"}, patch); err != nil {
+		t.Fatal(err)
+	}
+	got, err := fstore.ReadFile("CHANGES.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Changes\n\n## Next release\n\n- foo v2.0.0\n\n## 1.0.0\n"
+	if string(got) != want {
+		t.Errorf("wrong result\nwant: %q\ngot:  %q", want, string(got))
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/rs/zerolog/log"
+)
+
+// ApplyFilePatch writes a whole file rendered from a template, creating it
+// if needed, or deletes the file.
+func ApplyFilePatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchFile) error {
+	log.Debug().Str("file", patch.File).Stringer("mode", patch.Mode).Bool("delete", patch.Delete).Msg("Patching file.")
+
+	if patch.File == "" {
+		return fmt.Errorf("missing required field 'file'")
+	}
+
+	if patch.Delete {
+		if patch.Content != nil {
+			return fmt.Errorf("cannot set both 'delete' and 'content'")
+		}
+		err := fstore.DeleteFile(patch.File)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Debug().Str("file", patch.File).Msg("File to delete does not exist.")
+			return nil
+		}
+		return err
+	}
+
+	if patch.Content == nil {
+		return fmt.Errorf("missing required field 'content'")
+	}
+
+	_, err := fstore.ReadFile(patch.File)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch patch.Mode {
+	case "", config.FileWriteModeCreate:
+	case config.FileWriteModeOverwrite:
+		if !exists {
+			return fmt.Errorf("file does not exist, and mode is %s: %s", patch.Mode, patch.File)
+		}
+	case config.FileWriteModeCreateOnly:
+		if exists {
+			log.Debug().Str("file", patch.File).Msg("File already exists. Leaving it as-is.")
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported mode: %q", patch.Mode)
+	}
+
+	content, err := patch.Content.Render(tmplCtx)
+	if err != nil {
+		return fmt.Errorf("execute content template: %w", err)
+	}
+	return fstore.CreateFile(patch.File, []byte(content), patch.Permissions.FileMode())
+}
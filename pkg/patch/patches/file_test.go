@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/RiskIdent/jelease/pkg/util"
+)
+
+func TestApplyFilePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing *string
+		mode     config.FileWriteMode
+		want     *string
+		wantErr  bool
+	}{
+		{
+			name: "create new",
+			want: util.Ref("my-pkg: v1.2.3\n"),
+		},
+		{
+			name:     "create overwrites existing",
+			existing: util.Ref("my-pkg: v1.0.0\n"),
+			want:     util.Ref("my-pkg: v1.2.3\n"),
+		},
+		{
+			name:     "overwrite existing",
+			existing: util.Ref("my-pkg: v1.0.0\n"),
+			mode:     config.FileWriteModeOverwrite,
+			want:     util.Ref("my-pkg: v1.2.3\n"),
+		},
+		{
+			name:    "overwrite missing",
+			mode:    config.FileWriteModeOverwrite,
+			wantErr: true,
+		},
+		{
+			name: "createOnly new",
+			mode: config.FileWriteModeCreateOnly,
+			want: util.Ref("my-pkg: v1.2.3\n"),
+		},
+		{
+			name:     "createOnly existing",
+			existing: util.Ref("my-pkg: v1.0.0\n"),
+			mode:     config.FileWriteModeCreateOnly,
+			want:     util.Ref("my-pkg: v1.0.0\n"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files := map[string]string{}
+			if tc.existing != nil {
+				files["versions.lock"] = *tc.existing
+			}
+			fstore := filestore.NewTestFileStore(files)
+			patch := config.PatchFile{
+				File:    "versions.lock",
+				Content: newTemplate(t, "{{ .Package }}: {{ .Version }}\n"),
+				Mode:    tc.mode,
+			}
+
+			err := ApplyFilePatch(fstore, config.TemplateContext{Package: "my-pkg", Version: "v1.2.3"}, patch)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := fstore.ReadFile("versions.lock")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != *tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", *tc.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyFilePatch_delete(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"values-v1.yaml": "foo: bar\n",
+	})
+	patch := config.PatchFile{
+		File:   "values-v1.yaml",
+		Delete: true,
+	}
+
+	if err := ApplyFilePatch(fstore, config.TemplateContext{}, patch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fstore.ReadFile("values-v1.yaml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want file to be deleted, got: %v", err)
+	}
+
+	// Deleting a file that's already gone is not an error
+	if err := ApplyFilePatch(fstore, config.TemplateContext{}, patch); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
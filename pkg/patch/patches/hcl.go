@@ -0,0 +1,282 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/rs/zerolog/log"
+	"github.com/zclconf/go-cty/cty"
+)
+
+type TemplateContextHCL struct {
+	config.TemplateContext
+	// Value is the attribute's current value.
+	Value string
+}
+
+// hclEdit is a replacement of a byte range in the HCL file.
+type hclEdit struct {
+	rng   hcl.Range
+	value []byte
+}
+
+// ApplyHCLPatch sets an attribute value in a HCL file.
+//
+// The HCL is parsed with [hclsyntax] to find the attribute's position, and
+// then only the attribute value's bytes are replaced, with the new value
+// formatted by [hclwrite]. The [hclwrite.Body.SetAttributeValue] API is not
+// used, as it can only replace whole attributes, so it can't reach keys
+// inside object values such as "required_providers { aws = { version } }".
+// It also only exposes the attribute's raw tokens, but the current value is
+// needed to render the template and to keep the value's type.
+func ApplyHCLPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchHCL) error {
+	log.Debug().Str("file", patch.File).Str("block", patch.Block).Str("attribute", patch.Attribute).Msg("Patching HCL.")
+
+	if patch.File == "" {
+		return fmt.Errorf("missing required field 'file'")
+	}
+	if patch.Block == "" {
+		return fmt.Errorf("missing required field 'block'")
+	}
+	if patch.Attribute == "" {
+		return fmt.Errorf("missing required field 'attribute'")
+	}
+	if patch.Replace == nil {
+		return fmt.Errorf("missing required field 'replace'")
+	}
+
+	content, err := fstore.ReadFile(patch.File)
+	if err != nil {
+		return err
+	}
+	file, diags := hclsyntax.ParseConfig(content, patch.File, hcl.InitialPos)
+	if diags.HasErrors() {
+		return fmt.Errorf("parse HCL: %w", diags)
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fmt.Errorf("parse HCL: unexpected body type: %T", file.Body)
+	}
+
+	blocks := findHCLBlocks(body, strings.Split(patch.Block, "."), patch.Labels)
+	if len(blocks) == 0 {
+		return fmt.Errorf("no block found of type %q with labels %q", patch.Block, patch.Labels)
+	}
+
+	attrPath := strings.Split(patch.Attribute, ".")
+	var edits []hclEdit
+	for _, block := range blocks {
+		expr, err := findHCLAttributeExpr(block.Body, attrPath)
+		if err != nil {
+			return fmt.Errorf("line %d: attribute %q: %w", block.TypeRange.Start.Line, patch.Attribute, err)
+		}
+		if expr == nil {
+			continue
+		}
+		edit, err := newHCLEdit(expr, patch.Replace, tmplCtx)
+		if err != nil {
+			return fmt.Errorf("line %d: attribute %q: %w", expr.Range().Start.Line, patch.Attribute, err)
+		}
+		edits = append(edits, edit)
+	}
+	if len(edits) == 0 {
+		return fmt.Errorf("attribute %q not found in any block of type %q with labels %q", patch.Attribute, patch.Block, patch.Labels)
+	}
+
+	// Apply from the end, so the byte offsets of the remaining edits are unaffected
+	slices.SortFunc(edits, func(a, b hclEdit) int {
+		return b.rng.Start.Byte - a.rng.Start.Byte
+	})
+	newContent := slices.Clone(content)
+	for _, edit := range edits {
+		newContent = slices.Concat(newContent[:edit.rng.Start.Byte], edit.value, newContent[edit.rng.End.Byte:])
+	}
+
+	return fstore.WriteFile(patch.File, newContent)
+}
+
+func newHCLEdit(expr hclsyntax.Expression, replace *config.Template, tmplCtx config.TemplateContext) (hclEdit, error) {
+	oldValue, err := hclLiteralValue(expr)
+	if err != nil {
+		return hclEdit{}, err
+	}
+	var buf bytes.Buffer
+	if err := replace.Template().Execute(&buf, TemplateContextHCL{
+		TemplateContext: tmplCtx,
+		Value:           hclValueString(oldValue),
+	}); err != nil {
+		return hclEdit{}, fmt.Errorf("execute replace template: %w", err)
+	}
+	newValue, err := hclValueOfSameType(oldValue.Type(), buf.String())
+	if err != nil {
+		return hclEdit{}, err
+	}
+	return hclEdit{
+		rng:   expr.Range(),
+		value: hclwrite.TokensForValue(newValue).Bytes(),
+	}, nil
+}
+
+func findHCLBlocks(body *hclsyntax.Body, blockTypes []string, labels []string) []*hclsyntax.Block {
+	var blocks []*hclsyntax.Block
+	for _, block := range body.Blocks {
+		if block.Type != blockTypes[0] {
+			continue
+		}
+		if len(blockTypes) > 1 {
+			blocks = append(blocks, findHCLBlocks(block.Body, blockTypes[1:], labels)...)
+			continue
+		}
+		if len(labels) > 0 && !slices.Equal(block.Labels, labels) {
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// findHCLAttributeExpr returns the expression of an attribute, or
+// nil if the attribute was not found. The path is more than one element when
+// looking inside object attributes, such as:
+//
+//	required_providers {
+//	  aws = {
+//	    version = "~> 5.0"
+//	  }
+//	}
+func findHCLAttributeExpr(body *hclsyntax.Body, path []string) (hclsyntax.Expression, error) {
+	attr, ok := body.Attributes[path[0]]
+	if !ok {
+		return nil, nil
+	}
+	expr := attr.Expr
+	for _, key := range path[1:] {
+		obj, ok := expr.(*hclsyntax.ObjectConsExpr)
+		if !ok {
+			return nil, fmt.Errorf("expected object to look up key %q in, but got %s", key, hclExprName(expr))
+		}
+		expr = nil
+		for _, item := range obj.Items {
+			if hclObjectKey(item.KeyExpr) == key {
+				expr = item.ValueExpr
+				break
+			}
+		}
+		if expr == nil {
+			return nil, nil
+		}
+	}
+	return expr, nil
+}
+
+func hclObjectKey(keyExpr hclsyntax.Expression) string {
+	if keyword := hcl.ExprAsKeyword(keyExpr); keyword != "" {
+		return keyword
+	}
+	if wrapper, ok := keyExpr.(*hclsyntax.ObjectConsKeyExpr); ok {
+		keyExpr = wrapper.Wrapped
+	}
+	value, err := hclLiteralValue(keyExpr)
+	if err != nil || value.Type() != cty.String {
+		return ""
+	}
+	return value.AsString()
+}
+
+// hclLiteralValue returns the value of a literal string, number, or bool.
+// Expressions that refers to variables, functions, or uses string
+// interpolation are not supported.
+func hclLiteralValue(expr hclsyntax.Expression) (cty.Value, error) {
+	switch expr := expr.(type) {
+	case *hclsyntax.TemplateExpr:
+		if !expr.IsStringLiteral() {
+			return cty.NilVal, errors.New("only supports literal strings, but found string with interpolation")
+		}
+	case *hclsyntax.LiteralValueExpr:
+	default:
+		return cty.NilVal, fmt.Errorf("only supports literal values, but found %s", hclExprName(expr))
+	}
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	switch value.Type() {
+	case cty.String, cty.Number, cty.Bool:
+		return value, nil
+	default:
+		return cty.NilVal, fmt.Errorf("only supports strings, numbers, and bools, but found %s", value.Type().FriendlyName())
+	}
+}
+
+func hclValueString(value cty.Value) string {
+	switch value.Type() {
+	case cty.Number:
+		return value.AsBigFloat().Text('f', -1)
+	case cty.Bool:
+		return strconv.FormatBool(value.True())
+	default:
+		return value.AsString()
+	}
+}
+
+func hclValueOfSameType(ty cty.Type, value string) (cty.Value, error) {
+	switch ty {
+	case cty.Number:
+		v, err := cty.ParseNumberVal(value)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("attribute is a number, but new value is not: %q", value)
+		}
+		return v, nil
+	case cty.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return cty.NilVal, fmt.Errorf("attribute is a bool, but new value is not: %q", value)
+		}
+		return cty.BoolVal(b), nil
+	default:
+		return cty.StringVal(value), nil
+	}
+}
+
+func hclExprName(expr hclsyntax.Expression) string {
+	switch expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		return "object"
+	case *hclsyntax.TupleConsExpr:
+		return "list"
+	case *hclsyntax.ScopeTraversalExpr:
+		return "reference"
+	case *hclsyntax.FunctionCallExpr:
+		return "function call"
+	case *hclsyntax.TemplateWrapExpr:
+		return "interpolation"
+	default:
+		return "expression"
+	}
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+)
+
+func TestApplyHCLPatch(t *testing.T) {
+	content := `module "vpc" {
+  source  =   "git::https://example.com/vpc.git?ref=v1.2.3"   # keep this
+  version = "1.0.0"
+}
+
+module "other" {
+	version = "1.0.0"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version =   "~> 5.0" // keep this
+    }
+  }
+}
+`
+	tests := []struct {
+		name      string
+		block     string
+		labels    []string
+		attribute string
+		replace   string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "module source",
+			block:     "module",
+			labels:    []string{"vpc"},
+			attribute: "source",
+			replace:   `{{ .Value | regexReplaceAll "ref=.*" (printf "ref=%s" .Version) }}`,
+			want: `module "vpc" {
+  source  =   "git::https://example.com/vpc.git?ref=v2.0.0"   # keep this
+  version = "1.0.0"
+}
+
+module "other" {
+	version = "1.0.0"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version =   "~> 5.0" // keep this
+    }
+  }
+}
+`,
+		},
+		{
+			name:      "all modules",
+			block:     "module",
+			attribute: "version",
+			replace:   `{{ .Version | trimPrefix "v" }}`,
+			want: `module "vpc" {
+  source  =   "git::https://example.com/vpc.git?ref=v1.2.3"   # keep this
+  version = "2.0.0"
+}
+
+module "other" {
+	version = "2.0.0"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version =   "~> 5.0" // keep this
+    }
+  }
+}
+`,
+		},
+		{
+			name:      "required providers",
+			block:     "terraform.required_providers",
+			attribute: "aws.version",
+			replace:   `~> {{ .Version | trimPrefix "v" }}`,
+			want: `module "vpc" {
+  source  =   "git::https://example.com/vpc.git?ref=v1.2.3"   # keep this
+  version = "1.0.0"
+}
+
+module "other" {
+	version = "1.0.0"
+}
+
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version =   "~> 2.0.0" // keep this
+    }
+  }
+}
+`,
+		},
+		{
+			name:      "no such labels",
+			block:     "module",
+			labels:    []string{"nope"},
+			attribute: "version",
+			wantErr:   true,
+		},
+		{
+			name:      "no such attribute",
+			block:     "module",
+			attribute: "nope",
+			wantErr:   true,
+		},
+		{
+			name:      "not an object",
+			block:     "module",
+			attribute: "version.nope",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"main.tf": content,
+			})
+			patch := config.PatchHCL{
+				File:      "main.tf",
+				Block:     tc.block,
+				Labels:    tc.labels,
+				Attribute: tc.attribute,
+				Replace:   newTemplate(t, tc.replace),
+			}
+			err := ApplyHCLPatch(fstore, config.TemplateContext{Version: "v2.0.0"}, patch)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := fstore.ReadFile("main.tf")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestApplyHCLPatch_rejectsInterpolation(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"main.tf": "module \"x\" {\n  version = \"${var.version}\"\n}\n",
+	})
+	patch := config.PatchHCL{
+		File:      "main.tf",
+		Block:     "module",
+		Attribute: "version",
+		Replace:   newTemplate(t, `{{ .Version }}`),
+	}
+	if err := ApplyHCLPatch(fstore, config.TemplateContext{Version: "v2.0.0"}, patch); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
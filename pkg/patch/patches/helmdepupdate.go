@@ -19,10 +19,12 @@ package patches
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
 	"github.com/rs/zerolog/log"
 )
 
@@ -36,6 +38,20 @@ func ApplyHelmDepUpdatePatch(repoDir string, tmplCtx config.TemplateContext, pat
 		return fmt.Errorf("execute chart dir template: %w", err)
 	}
 
+	// The command is run inside the chart dir, so make sure it doesn't
+	// point outside of the repository, also via symlinks.
+	if !filepath.IsLocal(chart) {
+		return fmt.Errorf("%w: %q", filestore.ErrPathOutsideDir, chart)
+	}
+	root, err := os.OpenRoot(repoDir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	if _, err := root.Stat(chart); err != nil {
+		return err
+	}
+
 	log.Info().Str("chart", chart).Msg("Executing `helm dependency update`")
 	cmd := exec.Command("helm", "dependency", "update")
 	cmd.Dir = filepath.Join(repoDir, chart)
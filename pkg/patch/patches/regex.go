@@ -20,31 +20,42 @@ package patches
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"slices"
+	"strconv"
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/patch/filestore"
 	"github.com/rs/zerolog/log"
 )
 
-func ApplyRegexPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchRegex) error {
+// ApplyRegexPatch replaces the first line matching the regex.
+// The previous version is returned if the regex has a version group.
+func ApplyRegexPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchRegex) ([]string, error) {
 	log.Debug().Str("file", patch.File).Stringer("match", patch.Match).Msg("Patching regex.")
 
 	if patch.File == "" {
-		return fmt.Errorf("missing required field 'file'")
+		return nil, fmt.Errorf("missing required field 'file'")
 	}
 	if patch.Match == nil {
-		return fmt.Errorf("missing required field 'match'")
+		return nil, fmt.Errorf("missing required field 'match'")
 	}
 	if patch.Replace == nil {
-		return fmt.Errorf("missing required field 'replace'")
+		return nil, fmt.Errorf("missing required field 'replace'")
 	}
 
 	content, err := fstore.ReadFile(patch.File)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	regex := patch.Match.Regexp()
+	versionGroup, err := regexVersionGroup(regex, patch.VersionGroup)
+	if err != nil {
+		return nil, err
+	}
+	if versionGroup == -1 && patch.VersionPolicy == config.VersionPolicyUpgradeOnly {
+		return nil, fmt.Errorf("version policy %s requires a regex group named \"version\", or the 'versionGroup' field", patch.VersionPolicy)
+	}
 	lines := bytes.Split(content, []byte("\n"))
 
 	for i, line := range lines {
@@ -60,20 +71,49 @@ func ApplyRegexPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext,
 		everythingBefore := line[:fullMatchStart]
 		everythingAfter := line[fullMatchEnd:]
 
+		groups := regexSubmatchIndicesToStrings(line, groupIndices)
+		var prevVersions []string
+		if versionGroup != -1 {
+			tmplCtx.PreviousVersion = groups[versionGroup]
+			if err := checkVersionPolicy(patch.VersionPolicy, tmplCtx.PreviousVersion, tmplCtx.Version); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			prevVersions = []string{tmplCtx.PreviousVersion}
+		}
+
 		var buf bytes.Buffer
 		if err := patch.Replace.Template().Execute(&buf, TemplateContextRegex{
 			TemplateContext: tmplCtx,
-			Groups:          regexSubmatchIndicesToStrings(line, groupIndices),
+			Groups:          groups,
 		}); err != nil {
-			return fmt.Errorf("line %d: execute replace template: %w", i+1, err)
+			return nil, fmt.Errorf("line %d: execute replace template: %w", i+1, err)
 		}
 		lines[i] = slices.Concat(everythingBefore, buf.Bytes(), everythingAfter)
 		newContent := bytes.Join(lines, []byte("\n"))
 
-		return fstore.WriteFile(patch.File, newContent)
+		return prevVersions, fstore.WriteFile(patch.File, newContent)
 	}
 
-	return fmt.Errorf("regex did not match any line: %s", patch.Match)
+	return nil, fmt.Errorf("regex did not match any line: %s", patch.Match)
+}
+
+// regexVersionGroup returns the index of the regex group that contains the
+// previous version, or -1 if there is none.
+func regexVersionGroup(regex *regexp.Regexp, group string) (int, error) {
+	if group == "" {
+		return regex.SubexpIndex("version"), nil
+	}
+	if index, err := strconv.Atoi(group); err == nil {
+		if index < 0 || index > regex.NumSubexp() {
+			return 0, fmt.Errorf("version group %d out of range, regex only has %d groups", index, regex.NumSubexp())
+		}
+		return index, nil
+	}
+	index := regex.SubexpIndex(group)
+	if index == -1 {
+		return 0, fmt.Errorf("version group %q not found in regex", group)
+	}
+	return index, nil
 }
 
 func regexSubmatchIndicesToStrings(line []byte, indices []int) []string {
@@ -18,6 +18,8 @@
 package patches
 
 import (
+	"errors"
+	"slices"
 	"testing"
 
 	"github.com/RiskIdent/jelease/pkg/config"
@@ -39,7 +41,7 @@ func TestApplyRegexPatch(t *testing.T) {
 		Version: "v1.2.3",
 	}
 
-	err := ApplyRegexPatch(fstore, tmplCtx, patch)
+	_, err := ApplyRegexPatch(fstore, tmplCtx, patch)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -55,3 +57,92 @@ func TestApplyRegexPatch(t *testing.T) {
 		t.Errorf("want %q, got %q", want, got)
 	}
 }
+
+func TestApplyRegexPatch_versionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		policy      config.VersionPolicy
+		group       string
+		want        string
+		wantSkipped bool
+	}{
+		{
+			name:    "upgrade",
+			version: "1.10.0",
+			policy:  config.VersionPolicyUpgradeOnly,
+			want:    "image: my-app:1.10.0 # from 1.9.0",
+		},
+		{
+			name:        "downgrade skipped",
+			version:     "1.8.5",
+			policy:      config.VersionPolicyUpgradeOnly,
+			wantSkipped: true,
+		},
+		{
+			name:    "downgrade with any",
+			version: "1.8.5",
+			policy:  config.VersionPolicyAny,
+			want:    "image: my-app:1.8.5 # from 1.9.0",
+		},
+		{
+			name:        "numbered group",
+			version:     "1.8.5",
+			policy:      config.VersionPolicyUpgradeOnly,
+			group:       "2",
+			wantSkipped: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			const original = "image: my-app:1.9.0"
+			wantPrev := []string{"1.9.0"}
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"file.txt": original,
+			})
+			patch := config.PatchRegex{
+				File:          "file.txt",
+				Match:         newRegex(t, `(my-app):(?P<version>[0-9.]+)`),
+				Replace:       newTemplate(t, `{{ index .Groups 1 }}:{{ .Version }} # from {{ .PreviousVersion }}`),
+				VersionGroup:  tc.group,
+				VersionPolicy: tc.policy,
+			}
+
+			prevVersions, err := ApplyRegexPatch(fstore, config.TemplateContext{Version: tc.version}, patch)
+			if tc.wantSkipped {
+				if !errors.Is(err, ErrDowngrade) {
+					t.Fatalf("want ErrDowngrade, got: %v", err)
+				}
+				tc.want = original
+			} else if err != nil {
+				t.Fatal(err)
+			} else if !slices.Equal(prevVersions, wantPrev) {
+				t.Errorf("want previous versions %q, got %q", wantPrev, prevVersions)
+			}
+
+			gotBytes, err := fstore.ReadFile("file.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(gotBytes); got != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestApplyRegexPatch_versionPolicyRequiresGroup(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"file.txt": "my-app:1.9.0",
+	})
+	patch := config.PatchRegex{
+		File:          "file.txt",
+		Match:         newRegex(t, `my-app:[0-9.]+`),
+		Replace:       newTemplate(t, `my-app:{{ .Version }}`),
+		VersionPolicy: config.VersionPolicyUpgradeOnly,
+	}
+	if _, err := ApplyRegexPatch(fstore, config.TemplateContext{Version: "1.8.5"}, patch); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
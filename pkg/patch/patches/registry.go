@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/invopop/jsonschema"
+	"github.com/mitchellh/mapstructure"
+)
+
+// PatchType is a custom patch type, which can be used by its name inside
+// a repo's list of patches in the config, next to the built-in patch types.
+type PatchType struct {
+	// Name is the key used in the config, such as "myPatch".
+	Name string
+	// Decode converts the patch's config, as decoded from the YAML config
+	// file into maps, slices, and scalars, to the value passed to Apply.
+	Decode func(raw any) (any, error)
+	// Schema is the JSON schema of the patch's config, added to
+	// the config file's schema.
+	Schema *jsonschema.Schema
+	// Apply applies the patch, using the value returned by Decode.
+	Apply func(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch any) error
+}
+
+// NewPatchType creates a [PatchType] for a config struct, the same way as
+// the built-in patch types are configured. The config is decoded using
+// mapstructure, so config types such as [config.Template] work as fields,
+// and the JSON schema is reflected from the struct.
+func NewPatchType[T any](name string, apply func(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch T) error) PatchType {
+	return PatchType{
+		Name: name,
+		Decode: func(raw any) (any, error) {
+			var patch T
+			dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook: config.DecodeHook(),
+				Result:     &patch,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := dec.Decode(raw); err != nil {
+				return nil, err
+			}
+			return patch, nil
+		},
+		Schema: config.ReflectSchema(new(T)),
+		Apply: func(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch any) error {
+			return apply(fstore, tmplCtx, patch.(T))
+		},
+	}
+}
+
+// Registry is a set of custom patch types.
+type Registry struct {
+	mu    sync.RWMutex
+	types map[string]PatchType
+}
+
+// DefaultRegistry is the registry used when applying patches.
+// Use [Register] to add patch types to it.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates a new empty registry.
+func NewRegistry() *Registry {
+	return &Registry{types: map[string]PatchType{}}
+}
+
+// Register adds a patch type to the [DefaultRegistry], and adds its schema
+// to the config file's schema. It's meant to be called from an init
+// function, before the config is loaded.
+func Register(patchType PatchType) error {
+	if err := DefaultRegistry.Register(patchType); err != nil {
+		return err
+	}
+	if patchType.Schema != nil {
+		return config.RegisterPatchSchema(patchType.Name, patchType.Schema)
+	}
+	return nil
+}
+
+// Register adds a patch type to the registry. The name must be unique, and
+// must not be the name of a built-in patch type.
+func (r *Registry) Register(patchType PatchType) error {
+	if patchType.Name == "" {
+		return fmt.Errorf("missing patch type name")
+	}
+	if patchType.Decode == nil || patchType.Apply == nil {
+		return fmt.Errorf("patch type %q: missing decode or apply function", patchType.Name)
+	}
+	if config.IsBuiltinPatchType(patchType.Name) {
+		return fmt.Errorf("patch type %q is a built-in patch type", patchType.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[patchType.Name]; ok {
+		return fmt.Errorf("patch type %q is already registered", patchType.Name)
+	}
+	r.types[patchType.Name] = patchType
+	return nil
+}
+
+// Lookup returns the patch type with the given name.
+func (r *Registry) Lookup(name string) (PatchType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	patchType, ok := r.types[name]
+	return patchType, ok
+}
+
+// ApplyCustomPatch applies a patch from [config.PackageRepoPatch.Custom],
+// which must contain exactly one patch type that's registered.
+func (r *Registry) ApplyCustomPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, custom map[string]any) error {
+	if len(custom) != 1 {
+		return fmt.Errorf("expected a single patch type, but got %d", len(custom))
+	}
+	for name, raw := range custom {
+		patchType, ok := r.Lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown patch type: %q", name)
+		}
+		patch, err := patchType.Decode(raw)
+		if err != nil {
+			return fmt.Errorf("%s patch: decode config: %w", name, err)
+		}
+		if err := patchType.Apply(fstore, tmplCtx, patch); err != nil {
+			return fmt.Errorf("%s patch: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"gopkg.in/yaml.v3"
+)
+
+type testAppendPatch struct {
+	File string
+	Line *config.Template
+}
+
+func applyTestAppendPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch testAppendPatch) error {
+	content, err := fstore.ReadFile(patch.File)
+	if err != nil {
+		return err
+	}
+	line, err := patch.Line.Render(tmplCtx)
+	if err != nil {
+		return err
+	}
+	return fstore.WriteFile(patch.File, append(content, line+"\n"...))
+}
+
+func TestRegistry_applyCustomPatch(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(NewPatchType("append", applyTestAppendPatch)); err != nil {
+		t.Fatal(err)
+	}
+
+	var patch config.PackageRepoPatch
+	if err := yaml.Unmarshal([]byte(`
+append:
+  file: versions.txt
+  line: "{{ .Package }}={{ .Version }}"
+`), &patch); err != nil {
+		t.Fatal(err)
+	}
+
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"versions.txt": "other=v1.0.0\n",
+	})
+	tmplCtx := config.TemplateContext{Package: "my-pkg", Version: "v1.2.3"}
+	if err := r.ApplyCustomPatch(fstore, tmplCtx, patch.Custom); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "other=v1.0.0\nmy-pkg=v1.2.3\n"
+	got, err := fstore.ReadFile("versions.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("wrong result\nwant: %q\ngot:  %q", want, got)
+	}
+}
+
+func TestRegistry_applyCustomPatchUnknown(t *testing.T) {
+	r := NewRegistry()
+	fstore := filestore.NewTestFileStore(map[string]string{})
+	custom := map[string]any{"unknown": map[string]any{}}
+	if err := r.ApplyCustomPatch(fstore, config.TemplateContext{}, custom); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+func TestRegistry_registerErrors(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(NewPatchType("append", applyTestAppendPatch)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(NewPatchType("append", applyTestAppendPatch)); err == nil {
+		t.Error("want error on duplicate name, got nil")
+	}
+	if err := r.Register(NewPatchType("regex", applyTestAppendPatch)); err == nil {
+		t.Error("want error on built-in name, got nil")
+	}
+}
+
+func TestNewPatchType_schema(t *testing.T) {
+	patchType := NewPatchType("append", applyTestAppendPatch)
+	if patchType.Schema == nil || patchType.Schema.Properties == nil {
+		t.Fatal("want schema with properties, got nil")
+	}
+	for _, key := range []string{"file", "line"} {
+		if _, ok := patchType.Schema.Properties.Get(key); !ok {
+			t.Errorf("want schema property %q", key)
+		}
+	}
+}
@@ -20,7 +20,6 @@ package patches
 import (
 	"regexp"
 	"testing"
-	"text/template"
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/vmware-labs/yaml-jsonpath/pkg/yamlpath"
@@ -38,11 +37,11 @@ func newRegex(t *testing.T, text string) *config.RegexPattern {
 }
 
 func newTemplate(t *testing.T, text string) *config.Template {
-	tmpl, err := template.New("").Parse(text)
+	tmpl, err := config.NewTemplate(text)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return (*config.Template)(tmpl)
+	return tmpl
 }
 
 func newYAMLPath(t *testing.T, text string) *config.YAMLPathPattern {
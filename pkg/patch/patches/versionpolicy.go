@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/version"
+)
+
+// ErrDowngrade is returned by patches with the "upgradeOnly" version policy
+// when the release version is lower than the version already in the file.
+var ErrDowngrade = errors.New("would downgrade version")
+
+// checkVersionPolicy returns [ErrDowngrade] if the policy only allows
+// upgrades and the new version is lower than the previous version.
+func checkVersionPolicy(policy config.VersionPolicy, previous, next string) error {
+	switch policy {
+	case "", config.VersionPolicyAny:
+		return nil
+	case config.VersionPolicyUpgradeOnly:
+	default:
+		return fmt.Errorf("unsupported version policy: %q", policy)
+	}
+	prevVer, err := version.Parse(previous)
+	if err != nil {
+		return fmt.Errorf("version policy %s: parse previous version %q: %w", policy, previous, err)
+	}
+	nextVer, err := version.Parse(next)
+	if err != nil {
+		return fmt.Errorf("version policy %s: parse release version %q: %w", policy, next, err)
+	}
+	if nextVer.Compare(prevVer) < 0 {
+		return fmt.Errorf("%w from %s to %s", ErrDowngrade, previous, next)
+	}
+	return nil
+}
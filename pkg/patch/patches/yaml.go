@@ -20,10 +20,11 @@ package patches
 import (
 	"bytes"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/RiskIdent/jelease/pkg/config"
 	"github.com/RiskIdent/jelease/pkg/patch/filestore"
-	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
@@ -33,104 +34,364 @@ type TemplateContextRegex struct {
 	Groups []string
 }
 
-func ApplyYAMLPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchYAML) error {
+// ApplyYAMLPatch replaces the values matching the YAML path.
+// The previous values of the matched nodes are returned.
+func ApplyYAMLPatch(fstore filestore.FileStore, tmplCtx config.TemplateContext, patch config.PatchYAML) ([]string, error) {
 	log.Debug().Str("file", patch.File).Stringer("yamlpath", patch.YAMLPath).Msg("Patching YAML.")
 
 	if patch.File == "" {
-		return fmt.Errorf("missing required field 'file'")
+		return nil, fmt.Errorf("missing required field 'file'")
 	}
 	if patch.YAMLPath == nil {
-		return fmt.Errorf("missing required field 'yamlPath'")
+		return nil, fmt.Errorf("missing required field 'yamlPath'")
 	}
 	if patch.Replace == nil {
-		return fmt.Errorf("missing required field 'replace'")
+		return nil, fmt.Errorf("missing required field 'replace'")
 	}
 
 	content, err := fstore.ReadFile(patch.File)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var node yaml.Node
-	if err := yaml.Unmarshal(content, &node); err != nil {
-		return err
+	docs, err := splitYAMLDocuments(content)
+	if err != nil {
+		return nil, err
 	}
+	src := newYAMLSource(content, docs)
 
-	matches, err := patch.YAMLPath.YAMLPath.Find(&node)
+	matches, err := findYAMLPathMatches(docs, patch.YAMLPath)
 	if err != nil {
-		return fmt.Errorf("yamlpath %q: eval: %w", patch.YAMLPath, err)
+		return nil, err
 	}
 
+	var edits []yamlEdit
+	var values []string
+	var prevVersions []string
 	if len(matches) == 0 {
-		return fmt.Errorf("yamlpath %q: no matches found", patch.YAMLPath)
+		if !patch.CreateMissing {
+			return nil, fmt.Errorf("yamlpath %q: no matches found", patch.YAMLPath)
+		}
+		value, err := patch.Replace.Render(tmplCtx)
+		if err != nil {
+			return nil, fmt.Errorf("yamlpath %q: execute replace template: %w", patch.YAMLPath, err)
+		}
+		tag, err := yamlValueTag(value, patch.Type)
+		if err != nil {
+			return nil, fmt.Errorf("yamlpath %q: %w", patch.YAMLPath, err)
+		}
+		created, err := createYAMLPath(src, docs, patch.YAMLPath.Source, value, tag, patch.Indent)
+		if err != nil {
+			return nil, fmt.Errorf("yamlpath %q: create missing: %w", patch.YAMLPath, err)
+		}
+		log.Debug().
+			Str("file", patch.File).
+			Stringer("yamlpath", patch.YAMLPath).
+			Msg("Created missing YAML keys.")
+		edits = append(edits, created...)
+		values = append(values, value)
 	}
 
 	if patch.MaxMatches > 0 && len(matches) > patch.MaxMatches {
-		return fmt.Errorf("yamlpath %q: matched too many times: %d, max = %d", patch.YAMLPath, len(matches), patch.MaxMatches)
+		return nil, fmt.Errorf("yamlpath %q: matched too many times: %d, max = %d", patch.YAMLPath, len(matches), patch.MaxMatches)
 	}
 
 	for _, match := range matches {
-		if match.ShortTag() != "!!str" {
-			return fmt.Errorf("yamlpath %q: line %d: only supports matching strings, but instead matched %q", patch.YAMLPath, match.Line, match.ShortTag())
+		tmplCtx.PreviousVersion = yamlNodeValue(match)
+		if err := checkVersionPolicy(patch.VersionPolicy, tmplCtx.PreviousVersion, tmplCtx.Version); err != nil {
+			return nil, fmt.Errorf("yamlpath %q: line %d: %w", patch.YAMLPath, match.Line, err)
+		}
+		value, err := patch.Replace.Render(tmplCtx)
+		if err != nil {
+			return nil, fmt.Errorf("yamlpath %q: line %d: execute replace template: %w", patch.YAMLPath, match.Line, err)
 		}
-		var buf bytes.Buffer
-		if err := patch.Replace.Template().Execute(&buf, tmplCtx); err != nil {
-			return fmt.Errorf("yamlpath %q: line %d: execute replace template: %w", patch.YAMLPath, match.Line, err)
+		edit, err := yamlScalarEdit(src, match, value, patch.Type)
+		if err != nil {
+			// The error already contains the line number
+			return nil, fmt.Errorf("yamlpath %q: %w", patch.YAMLPath, err)
 		}
-		setYAMLNodeRecursive(match, buf.String())
+		edits = append(edits, edit)
+		values = append(values, value)
+		prevVersions = append(prevVersions, tmplCtx.PreviousVersion)
 	}
 
-	newContent, err := yamlEncode(&node, patch.Indent)
+	newContent := applyYAMLEdits(content, edits)
+	if err := verifyYAMLEdits(newContent, patch.YAMLPath, values); err != nil {
+		return nil, fmt.Errorf("yamlpath %q: verify changes: %w", patch.YAMLPath, err)
+	}
+	return prevVersions, fstore.WriteFile(patch.File, newContent)
+}
+
+// yamlDocument is a single document from a YAML stream, as split up
+// by [splitYAMLDocuments].
+type yamlDocument struct {
+	// separator is the raw "---" line, including the line break,
+	// that came before this document. Empty for the first document.
+	separator []byte
+	content   []byte
+	// start is the byte offset of the content in the whole file.
+	start int
+	// node is the decoded document, or nil if the document only consisted
+	// of whitespace and comments.
+	node *yaml.Node
+}
+
+var yamlDocumentSeparatorRegex = regexp.MustCompile(`^(---|\.\.\.)([ \t]+#.*)?[ \t]*\r?$`)
+
+// splitYAMLDocuments splits a multi-document YAML stream on its "---"
+// separator lines, so that each document can be written back individually
+// while leaving the separators and untouched documents as-is.
+//
+// YAML does not allow document markers at the start of a line within
+// scalars, so splitting on the raw lines is safe.
+func splitYAMLDocuments(content []byte) ([]*yamlDocument, error) {
+	var docs []*yamlDocument
+	doc := &yamlDocument{}
+	docStartLine := 0
+	offset := 0
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	for i, line := range lines {
+		offset += len(line)
+		if !yamlDocumentSeparatorRegex.Match(bytes.TrimSuffix(line, []byte("\n"))) {
+			doc.content = append(doc.content, line...)
+			continue
+		}
+		if err := decodeYAMLDocument(doc, docStartLine); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+		doc = &yamlDocument{separator: line, start: offset}
+		docStartLine = i + 1
+	}
+	if err := decodeYAMLDocument(doc, docStartLine); err != nil {
+		return nil, err
+	}
+	return append(docs, doc), nil
+}
+
+func decodeYAMLDocument(doc *yamlDocument, lineOffset int) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(doc.content, &node); err != nil {
+		return fmt.Errorf("document starting at line %d: %w", lineOffset+1, err)
+	}
+	if node.Kind == 0 {
+		// empty document, e.g only comments
+		return nil
+	}
+	offsetYAMLNodeLines(&node, lineOffset)
+	doc.node = &node
+	return nil
+}
+
+// offsetYAMLNodeLines makes the line numbers relative to the whole file
+// instead of relative to the document, so error messages make sense.
+func offsetYAMLNodeLines(node *yaml.Node, offset int) {
+	node.Line += offset
+	for _, child := range node.Content {
+		offsetYAMLNodeLines(child, offset)
+	}
+}
+
+func findYAMLPathMatches(docs []*yamlDocument, path *config.YAMLPathPattern) ([]*yaml.Node, error) {
+	var matches []*yaml.Node
+	for _, doc := range docs {
+		if doc.node == nil {
+			continue
+		}
+		docMatches, err := path.YAMLPath.Find(doc.node)
+		if err != nil {
+			return nil, fmt.Errorf("yamlpath %q: eval: %w", path, err)
+		}
+		matches = append(matches, docMatches...)
+	}
+	return matches, nil
+}
+
+// verifyYAMLEdits parses the edited YAML to make sure the edits resulted
+// in valid YAML, where the YAML-Path matches the new values.
+func verifyYAMLEdits(content []byte, path *config.YAMLPathPattern, values []string) error {
+	docs, err := splitYAMLDocuments(content)
 	if err != nil {
 		return err
 	}
+	matches, err := findYAMLPathMatches(docs, path)
+	if err != nil {
+		return err
+	}
+	if len(matches) != len(values) {
+		return fmt.Errorf("expected %d matches, but found %d", len(values), len(matches))
+	}
+	for i, match := range matches {
+		for match.Alias != nil {
+			match = match.Alias
+		}
+		if match.Value != values[i] {
+			return fmt.Errorf("line %d: expected value %q, but got %q", match.Line, values[i], match.Value)
+		}
+	}
+	return nil
+}
+
+// createYAMLPath returns edits that add the mapping keys from a simple
+// YAML-Path, such as ".foo.bar", to the first non-empty document, with the
+// value at the end of the path.
+func createYAMLPath(src *yamlSource, docs []*yamlDocument, path, value, tag string, indentStep int) ([]yamlEdit, error) {
+	keys, err := parseSimpleYAMLPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if indentStep <= 0 {
+		indentStep = 2
+	}
+	doc := docs[0]
+	for _, d := range docs {
+		if d.node != nil {
+			doc = d
+			break
+		}
+	}
+	if doc.node == nil || len(doc.node.Content) == 0 {
+		// Empty document, so add the keys to its end
+		end := doc.start + len(doc.content)
+		snippet := formatYAMLBlockMapping(keys, value, tag, 0, indentStep) + "\n"
+		if end > 0 && src.content[end-1] != '\n' {
+			snippet = "\n" + snippet
+		}
+		return []yamlEdit{{end, end, []byte(snippet)}}, nil
+	}
+
+	node := doc.node.Content[0]
+	for len(keys) > 0 && node.Kind == yaml.MappingNode {
+		value := findYAMLMappingValue(node, keys[0])
+		if value == nil {
+			break
+		}
+		node = value
+		keys = keys[1:]
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("line %d: path already exists", node.Line)
+	}
 
-	fixedContent, err := util.PatchKeepWhitespace(content, newContent)
 	switch {
-	case err != nil:
-		log.Debug().
-			Err(err).
-			Str("file", patch.File).
-			Stringer("yamlpath", patch.YAMLPath).
-			Msg("Failed to perform whitespace preserving patch. Skipping that step.")
-	case bytes.Equal(fixedContent, newContent):
-		log.Debug().
-			Str("file", patch.File).
-			Stringer("yamlpath", patch.YAMLPath).
-			Msg("No changes applied via whitespace preserving patch.")
+	case node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle != 0:
+		return src.flowMappingInsert(node, keys, value, tag)
+	case node.Kind == yaml.MappingNode:
+		return src.blockMappingInsert(node, keys, value, tag, indentStep)
+	case node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null":
+		// Empty values, such as "foo:", can be turned into a mapping
+		return src.nullToMappingEdits(node, keys, value, tag, indentStep)
 	default:
-		log.Debug().
-			Str("file", patch.File).
-			Stringer("yamlpath", patch.YAMLPath).
-			Msg("Applied whitespace preserving patch.")
-		newContent = fixedContent
+		return nil, fmt.Errorf("line %d: cannot add key %q to non-mapping node", node.Line, keys[0])
 	}
+}
 
-	return fstore.WriteFile(patch.File, newContent)
+func findYAMLMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
 }
 
-func setYAMLNodeRecursive(node *yaml.Node, value string) {
-	if node.Alias != nil {
-		setYAMLNodeRecursive(node.Alias, value)
-		return
+// parseSimpleYAMLPath parses a YAML-Path that only consists of mapping keys,
+// such as ".foo.bar", "$.foo.bar", or "$['foo.bar'].moo".
+func parseSimpleYAMLPath(path string) ([]string, error) {
+	rest := strings.TrimPrefix(path, "$")
+	var keys []string
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "['"), strings.HasPrefix(rest, `["`):
+			quote := rest[1:2]
+			end := strings.Index(rest[2:], quote+"]")
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated bracket in path: %q", path)
+			}
+			keys = append(keys, rest[2:2+end])
+			rest = rest[2+end+2:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			if key == "" || key == "*" {
+				return nil, fmt.Errorf("only simple paths of mapping keys are supported, such as .foo.bar, but got: %q", path)
+			}
+			keys = append(keys, key)
+			rest = rest[end:]
+		default:
+			return nil, fmt.Errorf("only simple paths of mapping keys are supported, such as .foo.bar, but got: %q", path)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("path must contain at least one key: %q", path)
 	}
-	node.SetString(value)
+	return keys, nil
 }
 
-func yamlEncode(obj any, indent int) ([]byte, error) {
-	var buf bytes.Buffer
-	enc := yaml.NewEncoder(&buf)
-	if indent > 0 {
-		enc.SetIndent(indent)
-	} else {
-		enc.SetIndent(2)
+// yamlNodeValue returns the value of a scalar node, or of the anchored node
+// if it's an alias.
+func yamlNodeValue(node *yaml.Node) string {
+	for node.Alias != nil {
+		node = node.Alias
 	}
+	return node.Value
+}
 
-	if err := enc.Encode(obj); err != nil {
-		return nil, fmt.Errorf("encode: %w", err)
+// yamlScalarEdit returns an edit that sets the value of a scalar node,
+// or of the anchored node if it's an alias.
+func yamlScalarEdit(src *yamlSource, node *yaml.Node, value string, valueType config.YAMLValueType) (yamlEdit, error) {
+	if node.Alias != nil {
+		return yamlScalarEdit(src, node.Alias, value, valueType)
 	}
-	if err := enc.Close(); err != nil {
-		return nil, err
+	if node.Kind != yaml.ScalarNode {
+		return yamlEdit{}, fmt.Errorf("line %d: only supports matching scalar values, but instead matched %q", node.Line, node.ShortTag())
 	}
+	tag, err := yamlValueTag(value, valueType)
+	if err != nil {
+		return yamlEdit{}, fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	return src.scalarEdit(node, value, tag)
+}
 
-	return buf.Bytes(), nil
+// yamlValueTag returns the tag to write the value as.
+func yamlValueTag(value string, valueType config.YAMLValueType) (string, error) {
+	tag := "!!str"
+	switch valueType {
+	case "", config.YAMLValueTypeString:
+		return tag, nil
+	case config.YAMLValueTypeInt:
+		tag = "!!int"
+	case config.YAMLValueTypeFloat:
+		tag = "!!float"
+	case config.YAMLValueTypeBool:
+		tag = "!!bool"
+	case config.YAMLValueTypeAuto:
+		return resolveYAMLTag(value), nil
+	default:
+		return "", fmt.Errorf("unsupported value type: %q", valueType)
+	}
+	if resolved := resolveYAMLTag(value); resolved != tag {
+		return "", fmt.Errorf("value %q is not a valid %s, but resolves to %s", value, valueType, resolved)
+	}
+	return tag, nil
+}
+
+// resolveYAMLTag returns the tag that the value would resolve to if it was
+// written as an unquoted (plain) scalar in a YAML file.
+func resolveYAMLTag(value string) string {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &node); err != nil {
+		return "!!str"
+	}
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 {
+		return "!!str"
+	}
+	scalar := node.Content[0]
+	if scalar.Kind != yaml.ScalarNode || scalar.Style != 0 || scalar.Value != value {
+		return "!!str"
+	}
+	return scalar.ShortTag()
 }
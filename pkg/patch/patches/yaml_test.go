@@ -18,6 +18,9 @@
 package patches
 
 import (
+	"errors"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/RiskIdent/jelease/pkg/config"
@@ -39,7 +42,7 @@ func TestApplYAMLPatch(t *testing.T) {
 		Version: "v1.2.3",
 	}
 
-	err := ApplyYAMLPatch(fstore, tmplCtx, patch)
+	_, err := ApplyYAMLPatch(fstore, tmplCtx, patch)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -55,3 +58,471 @@ func TestApplYAMLPatch(t *testing.T) {
 		t.Errorf("want %q, got %q", want, got)
 	}
 }
+
+func TestApplyYAMLPatch_types(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		valueType config.YAMLValueType
+		replace   string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:    "default is string",
+			content: "foo: 1\n",
+			replace: "2",
+			want:    "foo: \"2\"\n",
+		},
+		{
+			name:      "int",
+			content:   "foo: 1\n",
+			valueType: config.YAMLValueTypeInt,
+			replace:   "2",
+			want:      "foo: 2\n",
+		},
+		{
+			name:      "int from string",
+			content:   "foo: \"1\"\n",
+			valueType: config.YAMLValueTypeInt,
+			replace:   "2",
+			want:      "foo: 2\n",
+		},
+		{
+			name:      "invalid int",
+			content:   "foo: 1\n",
+			valueType: config.YAMLValueTypeInt,
+			replace:   "v2",
+			wantErr:   true,
+		},
+		{
+			name:      "float",
+			content:   "foo: 1.5\n",
+			valueType: config.YAMLValueTypeFloat,
+			replace:   "2.5",
+			want:      "foo: 2.5\n",
+		},
+		{
+			name:      "bool",
+			content:   "foo: false\n",
+			valueType: config.YAMLValueTypeBool,
+			replace:   "true",
+			want:      "foo: true\n",
+		},
+		{
+			name:      "auto int",
+			content:   "foo: bar\n",
+			valueType: config.YAMLValueTypeAuto,
+			replace:   "123",
+			want:      "foo: 123\n",
+		},
+		{
+			name:      "auto string",
+			content:   "foo: 123\n",
+			valueType: config.YAMLValueTypeAuto,
+			replace:   "v1.2.3",
+			want:      "foo: v1.2.3\n",
+		},
+		{
+			name:    "rejects mappings",
+			content: "foo: {bar: moo}\n",
+			replace: "2",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"file.yaml": tc.content,
+			})
+			patch := config.PatchYAML{
+				File:     "file.yaml",
+				YAMLPath: newYAMLPath(t, `.foo`),
+				Replace:  newTemplate(t, tc.replace),
+				Type:     tc.valueType,
+			}
+			_, err := ApplyYAMLPatch(fstore, config.TemplateContext{}, patch)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := fstore.ReadFile("file.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestApplyYAMLPatch_createMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		want    string
+	}{
+		{
+			name:    "empty file",
+			content: "",
+			path:    ".image.tag",
+			want:    "image:\n  tag: v1.2.3\n",
+		},
+		{
+			name:    "partially existing",
+			content: "image:\n  repository: nginx\n",
+			path:    ".image.tag",
+			want:    "image:\n  repository: nginx\n  tag: v1.2.3\n",
+		},
+		{
+			name:    "null value",
+			content: "image:\n",
+			path:    "$.image.tag",
+			want:    "image:\n  tag: v1.2.3\n",
+		},
+		{
+			name:    "bracket notation",
+			content: "annotations: {}\n",
+			path:    "$.annotations['example.com/version']",
+			want:    "annotations: {example.com/version: v1.2.3}\n",
+		},
+		{
+			name:    "keeps comments and blank lines",
+			content: "image:\n    repository: nginx # comment\n    # trailing comment\n\nother: 1\n",
+			path:    ".image.tag",
+			want:    "image:\n    repository: nginx # comment\n    tag: v1.2.3\n    # trailing comment\n\nother: 1\n",
+		},
+		{
+			name:    "nested in existing",
+			content: "a:\n  b: 1\nc: 2\n",
+			path:    ".a.d.e",
+			want:    "a:\n  b: 1\n  d:\n    e: v1.2.3\nc: 2\n",
+		},
+		{
+			name:    "tilde null value",
+			content: "image: ~ # comment\n",
+			path:    ".image.tag",
+			want:    "image: # comment\n  tag: v1.2.3\n",
+		},
+		{
+			name:    "null keyword value",
+			content: "image: null\n",
+			path:    ".image.tag",
+			want:    "image:\n  tag: v1.2.3\n",
+		},
+		{
+			name:    "non-empty flow mapping",
+			content: "image: {repository: nginx}\n",
+			path:    ".image.tag",
+			want:    "image: {repository: nginx, tag: v1.2.3}\n",
+		},
+		{
+			name:    "existing is updated",
+			content: "image:\n  tag: v1.0.0\n",
+			path:    ".image.tag",
+			want:    "image:\n  tag: v1.2.3\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"file.yaml": tc.content,
+			})
+			patch := config.PatchYAML{
+				File:          "file.yaml",
+				YAMLPath:      newYAMLPath(t, tc.path),
+				Replace:       newTemplate(t, `{{ .Version }}`),
+				CreateMissing: true,
+			}
+			_, err := ApplyYAMLPatch(fstore, config.TemplateContext{Version: "v1.2.3"}, patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := fstore.ReadFile("file.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestApplyYAMLPatch_keepsFormatting(t *testing.T) {
+	longLine := "description: " + strings.Repeat("this line is longer than what the YAML encoder would allow ", 3) + "\n"
+	tests := []struct {
+		name    string
+		content string
+		path    string
+		replace string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "plain with comment and indentation",
+			content: "image:\n    repository: nginx   # the repo\n    tag: v1.0.0    # the tag\n" + longLine,
+			path:    ".image.tag",
+			want:    "image:\n    repository: nginx   # the repo\n    tag: v2.0.0    # the tag\n" + longLine,
+		},
+		{
+			name:    "single-quoted",
+			content: "tag: 'v1.0.0' # comment\n",
+			path:    ".tag",
+			want:    "tag: 'v2.0.0' # comment\n",
+		},
+		{
+			name:    "single-quoted with quote",
+			content: "tag: 'v1.0.0'\n",
+			path:    ".tag",
+			replace: "it's {{ .Version }}",
+			want:    "tag: 'it''s v2.0.0'\n",
+		},
+		{
+			name:    "double-quoted with escapes",
+			content: "tag: \"v1.0.0 \\\"quoted\\\"\"\nnext: 1\n",
+			path:    ".tag",
+			replace: `"{{ .Version }}"`,
+			want:    "tag: \"\\\"v2.0.0\\\"\"\nnext: 1\n",
+		},
+		{
+			name:    "plain that needs quoting",
+			content: "tag: v1.0.0\n",
+			path:    ".tag",
+			replace: "2.0",
+			want:    "tag: \"2.0\"\n",
+		},
+		{
+			name:    "plain with colon needs quoting",
+			content: "tag: v1.0.0\n",
+			path:    ".tag",
+			replace: "a: b",
+			want:    "tag: \"a: b\"\n",
+		},
+		{
+			name:    "flow mapping",
+			content: "image: {repository: nginx,   tag: v1.0.0 }\n",
+			path:    ".image.tag",
+			want:    "image: {repository: nginx,   tag: v2.0.0 }\n",
+		},
+		{
+			name:    "flow sequence needs quoting",
+			content: "tags: [a, v1.0.0, c]\n",
+			path:    ".tags[1]",
+			replace: "x,y",
+			want:    "tags: [a, \"x,y\", c]\n",
+		},
+		{
+			name:    "multi-byte characters before value",
+			content: "name: {ä: ö, tag: v1.0.0}\n",
+			path:    ".name.tag",
+			want:    "name: {ä: ö, tag: v2.0.0}\n",
+		},
+		{
+			name:    "alias",
+			content: "base: &version v1.0.0\nimage:\n  tag: *version\n",
+			path:    ".image.tag",
+			want:    "base: &version v2.0.0\nimage:\n  tag: *version\n",
+		},
+		{
+			name:    "tagged",
+			content: "tag: !!str v1.0.0\n",
+			path:    ".tag",
+			want:    "tag: !!str v2.0.0\n",
+		},
+		{
+			name:    "empty value",
+			content: "tag:\nnext: 1\n",
+			path:    ".tag",
+			want:    "tag: v2.0.0\nnext: 1\n",
+		},
+		{
+			name:    "literal block scalar",
+			content: "script: |- # comment\n    echo v1.0.0\n    echo done\n\nnext: 1\n",
+			path:    ".script",
+			replace: "echo {{ .Version }}",
+			want:    "script: |- # comment\n    echo v2.0.0\n\nnext: 1\n",
+		},
+		{
+			name:    "folded block scalar",
+			content: "notes: >\n  v1.0.0\nnext: 1\n",
+			path:    ".notes",
+			replace: "{{ .Version }}\n",
+			want:    "notes: >\n  v2.0.0\nnext: 1\n",
+		},
+		{
+			name:    "multi-line plain scalar",
+			content: "tag: v1.0.0\n  continued\n",
+			path:    ".tag",
+			wantErr: `yamlpath ".tag": line 1: only single-line plain scalars can be edited, but found "v1.0.0"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"file.yaml": tc.content,
+			})
+			replace := tc.replace
+			if replace == "" {
+				replace = "{{ .Version }}"
+			}
+			patch := config.PatchYAML{
+				File:     "file.yaml",
+				YAMLPath: newYAMLPath(t, tc.path),
+				Replace:  newTemplate(t, replace),
+			}
+			_, err := ApplyYAMLPatch(fstore, config.TemplateContext{Version: "v2.0.0"}, patch)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("want error %q, got: %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := fstore.ReadFile("file.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, string(got))
+			}
+		})
+	}
+}
+
+func TestApplyYAMLPatch_createMissingRejectsComplexPath(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"file.yaml": "foo: []\n",
+	})
+	patch := config.PatchYAML{
+		File:          "file.yaml",
+		YAMLPath:      newYAMLPath(t, `$.foo[*].bar`),
+		Replace:       newTemplate(t, `{{ .Version }}`),
+		CreateMissing: true,
+	}
+	if _, err := ApplyYAMLPatch(fstore, config.TemplateContext{}, patch); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+func TestApplyYAMLPatch_multiDocument(t *testing.T) {
+	fstore := filestore.NewTestFileStore(map[string]string{
+		"file.yaml": `# leading comment
+---
+kind: Deployment
+spec:
+  image: nginx:1.0.0
+---   # untouched document
+kind:   Service
+
+---
+kind: Deployment
+spec:
+  image: nginx:1.0.0
+...
+`,
+	})
+	patch := config.PatchYAML{
+		File:     "file.yaml",
+		YAMLPath: newYAMLPath(t, `.spec.image`),
+		Replace:  newTemplate(t, `nginx:{{ .Version }}`),
+	}
+	_, err := ApplyYAMLPatch(fstore, config.TemplateContext{Version: "1.2.3"}, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# leading comment
+---
+kind: Deployment
+spec:
+  image: nginx:1.2.3
+---   # untouched document
+kind:   Service
+
+---
+kind: Deployment
+spec:
+  image: nginx:1.2.3
+...
+`
+	got, err := fstore.ReadFile("file.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("wrong result\nwant: %q\ngot:  %q", want, string(got))
+	}
+}
+
+func TestApplyYAMLPatch_versionPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		want        string
+		wantSkipped bool
+	}{
+		{
+			name:    "upgrade",
+			version: "v1.10.0",
+			want:    "image:\n  tag: v1.9.0..v1.10.0\n",
+		},
+		{
+			name:        "downgrade",
+			version:     "v1.8.5",
+			wantSkipped: true,
+		},
+		{
+			name:        "pre-release of same version",
+			version:     "v1.9.0-rc.1",
+			wantSkipped: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			const original = "image:\n  tag: v1.9.0\n"
+			wantPrev := []string{"v1.9.0"}
+			fstore := filestore.NewTestFileStore(map[string]string{
+				"values.yaml": original,
+			})
+			patch := config.PatchYAML{
+				File:          "values.yaml",
+				YAMLPath:      newYAMLPath(t, `.image.tag`),
+				Replace:       newTemplate(t, `{{ .PreviousVersion }}..{{ .Version }}`),
+				VersionPolicy: config.VersionPolicyUpgradeOnly,
+			}
+
+			prevVersions, err := ApplyYAMLPatch(fstore, config.TemplateContext{Version: tc.version}, patch)
+			if tc.wantSkipped {
+				if !errors.Is(err, ErrDowngrade) {
+					t.Fatalf("want ErrDowngrade, got: %v", err)
+				}
+				tc.want = original
+			} else if err != nil {
+				t.Fatal(err)
+			} else if !slices.Equal(prevVersions, wantPrev) {
+				t.Errorf("want previous versions %q, got %q", wantPrev, prevVersions)
+			}
+
+			gotBytes, err := fstore.ReadFile("values.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(gotBytes); got != tc.want {
+				t.Errorf("wrong result\nwant: %q\ngot:  %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patches
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEdit is a replacement of a byte range in the YAML file.
+type yamlEdit struct {
+	start int
+	end   int
+	value []byte
+}
+
+// applyYAMLEdits splices the edits into the content. Edits of the same
+// range, such as from multiple aliases of the same anchor, are only
+// applied once.
+func applyYAMLEdits(content []byte, edits []yamlEdit) []byte {
+	slices.SortFunc(edits, func(a, b yamlEdit) int {
+		return b.start - a.start
+	})
+	edits = slices.CompactFunc(edits, func(a, b yamlEdit) bool {
+		return a.start == b.start && a.end == b.end
+	})
+	result := slices.Clone(content)
+	for _, edit := range edits {
+		result = slices.Concat(result[:edit.start], edit.value, result[edit.end:])
+	}
+	return result
+}
+
+// yamlSource is the raw content of a YAML file, used to find the byte
+// offsets of the decoded [yaml.Node] values.
+type yamlSource struct {
+	content    []byte
+	lineStarts []int
+	// parents maps nodes to their parent node, for all documents.
+	parents map[*yaml.Node]*yaml.Node
+}
+
+func newYAMLSource(content []byte, docs []*yamlDocument) *yamlSource {
+	src := &yamlSource{
+		content:    content,
+		lineStarts: []int{0},
+		parents:    map[*yaml.Node]*yaml.Node{},
+	}
+	for i, b := range content {
+		if b == '\n' {
+			src.lineStarts = append(src.lineStarts, i+1)
+		}
+	}
+	for _, doc := range docs {
+		if doc.node != nil {
+			src.addParents(doc.node)
+		}
+	}
+	return src
+}
+
+func (src *yamlSource) addParents(node *yaml.Node) {
+	for _, child := range node.Content {
+		src.parents[child] = node
+		src.addParents(child)
+	}
+}
+
+// offset returns the byte offset of the node, after skipping any anchor
+// or tag, such as "&anchor" or "!!str".
+func (src *yamlSource) offset(node *yaml.Node) (int, error) {
+	if node.Line < 1 || node.Line > len(src.lineStarts) {
+		return 0, fmt.Errorf("line %d: position out of range", node.Line)
+	}
+	offset := src.lineStarts[node.Line-1]
+	// Column counts runes, not bytes
+	for col := 1; col < node.Column; col++ {
+		_, size := utf8.DecodeRune(src.content[offset:])
+		if size == 0 {
+			return 0, fmt.Errorf("line %d: column %d out of range", node.Line, node.Column)
+		}
+		offset += size
+	}
+	if node.Anchor == "" && node.Style&yaml.TaggedStyle == 0 {
+		return offset, nil
+	}
+	for offset < len(src.content) && (src.content[offset] == '&' || src.content[offset] == '!') {
+		for offset < len(src.content) && !isYAMLWhitespace(src.content[offset]) {
+			offset++
+		}
+		for offset < len(src.content) && isYAMLWhitespace(src.content[offset]) {
+			offset++
+		}
+	}
+	return offset, nil
+}
+
+// lineEnd returns the offset of the end of the line, excluding the line break.
+func (src *yamlSource) lineEnd(offset int) int {
+	end := bytes.IndexByte(src.content[offset:], '\n')
+	if end == -1 {
+		return len(src.content)
+	}
+	end += offset
+	if end > offset && src.content[end-1] == '\r' {
+		end--
+	}
+	return end
+}
+
+// lineIndent returns the number of leading spaces of the line.
+func (src *yamlSource) lineIndent(line int) int {
+	rest := src.content[src.lineStarts[line]:]
+	return len(rest) - len(bytes.TrimLeft(rest, " "))
+}
+
+// isInFlow reports if the node is inside a flow collection,
+// such as "[a, b]" or "{a: b}".
+func (src *yamlSource) isInFlow(node *yaml.Node) bool {
+	for parent := src.parents[node]; parent != nil; parent = src.parents[parent] {
+		if parent.Style&yaml.FlowStyle != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mappingKey returns the key of a mapping value, or nil if the node is not
+// a mapping value.
+func (src *yamlSource) mappingKey(node *yaml.Node) *yaml.Node {
+	parent := src.parents[node]
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 1; i < len(parent.Content); i += 2 {
+		if parent.Content[i] == node {
+			return parent.Content[i-1]
+		}
+	}
+	return nil
+}
+
+// scalarEdit returns an edit that replaces the scalar's value in-place,
+// while keeping its style when possible.
+func (src *yamlSource) scalarEdit(node *yaml.Node, value, tag string) (yamlEdit, error) {
+	start, err := src.offset(node)
+	if err != nil {
+		return yamlEdit{}, err
+	}
+	inFlow := src.isInFlow(node)
+	switch {
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return src.blockScalarEdit(node, start, value, tag)
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		end, err := src.doubleQuotedEnd(start)
+		if err != nil {
+			return yamlEdit{}, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return yamlEdit{start, end, []byte(formatYAMLScalar(value, tag, node.Style, inFlow))}, nil
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		end, err := src.singleQuotedEnd(start)
+		if err != nil {
+			return yamlEdit{}, fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		return yamlEdit{start, end, []byte(formatYAMLScalar(value, tag, node.Style, inFlow))}, nil
+	}
+
+	if node.Value == "" {
+		// Empty value, such as "foo:". The position is right after the colon.
+		return yamlEdit{start, start, []byte(" " + formatYAMLScalar(value, tag, node.Style, inFlow))}, nil
+	}
+	end := src.plainEnd(start, inFlow)
+	if string(src.content[start:end]) != node.Value {
+		return yamlEdit{}, fmt.Errorf("line %d: only single-line plain scalars can be edited, but found %q", node.Line, src.content[start:end])
+	}
+	return yamlEdit{start, end, []byte(formatYAMLScalar(value, tag, node.Style, inFlow))}, nil
+}
+
+func (src *yamlSource) doubleQuotedEnd(start int) (int, error) {
+	for i := start + 1; i < len(src.content); i++ {
+		switch src.content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated double-quoted scalar")
+}
+
+func (src *yamlSource) singleQuotedEnd(start int) (int, error) {
+	for i := start + 1; i < len(src.content); i++ {
+		if src.content[i] != '\'' {
+			continue
+		}
+		if i+1 < len(src.content) && src.content[i+1] == '\'' {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, fmt.Errorf("unterminated single-quoted scalar")
+}
+
+func (src *yamlSource) plainEnd(start int, inFlow bool) int {
+	end := start
+	lineEnd := src.lineEnd(start)
+	for ; end < lineEnd; end++ {
+		c := src.content[end]
+		if c == '#' && end > start && isYAMLWhitespace(src.content[end-1]) {
+			break
+		}
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+	}
+	for end > start && isYAMLWhitespace(src.content[end-1]) {
+		end--
+	}
+	return end
+}
+
+// blockScalarEdit replaces the header and content of a literal ("|") or
+// folded (">") block scalar, while keeping any comment after the header.
+func (src *yamlSource) blockScalarEdit(node *yaml.Node, start int, value, tag string) (yamlEdit, error) {
+	headerEnd := start + 1
+	for headerEnd < len(src.content) && (src.content[headerEnd] == '-' || src.content[headerEnd] == '+') {
+		headerEnd++
+	}
+	if headerEnd < len(src.content) && src.content[headerEnd] >= '0' && src.content[headerEnd] <= '9' {
+		return yamlEdit{}, fmt.Errorf("line %d: block scalars with indentation indicators are not supported", node.Line)
+	}
+	headerLineEnd := src.lineEnd(start)
+	headerRest := src.content[headerEnd:headerLineEnd]
+
+	// The content is all following lines that are either blank or indented
+	// at least as much as the first non-blank line.
+	headerIndent := src.lineIndent(node.Line - 1)
+	contentIndent := -1
+	end := headerLineEnd
+	for line := node.Line; line < len(src.lineStarts); line++ {
+		lineStart := src.lineStarts[line]
+		lineEnd := src.lineEnd(lineStart)
+		if len(bytes.TrimSpace(src.content[lineStart:lineEnd])) == 0 {
+			continue
+		}
+		indent := src.lineIndent(line)
+		if contentIndent == -1 {
+			if indent <= headerIndent {
+				break
+			}
+			contentIndent = indent
+		}
+		if indent < contentIndent {
+			break
+		}
+		end = lineEnd
+	}
+	if contentIndent == -1 {
+		contentIndent = headerIndent + 2
+	}
+
+	formatted, ok := formatYAMLBlockScalar(value, tag, node.Style, contentIndent)
+	if !ok {
+		// Cannot be written as a block scalar, so fall back to a quoted value
+		formatted = formatYAMLScalar(value, tag, yaml.DoubleQuotedStyle, false)
+		return yamlEdit{start, end, slices.Concat([]byte(formatted), headerRest)}, nil
+	}
+	header, body, _ := strings.Cut(formatted, "\n")
+	return yamlEdit{start, end, slices.Concat([]byte(header), headerRest, []byte("\n"+body))}, nil
+}
+
+// formatYAMLScalar formats the value to be written as a scalar. Strings are
+// written in the given style if possible, and otherwise quoted if needed.
+func formatYAMLScalar(value, tag string, style yaml.Style, inFlow bool) string {
+	if tag != "!!str" {
+		// Non-string values must not be quoted, or they would turn into strings
+		return value
+	}
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case style&yaml.SingleQuotedStyle != 0 && canSingleQuoteYAML(value):
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) == 0 && canPlainYAML(value, inFlow):
+		return value
+	default:
+		return strconv.Quote(value)
+	}
+}
+
+// formatYAMLBlockScalar formats the value as a block scalar, with the
+// header on the first line. Returns false if the value cannot be written
+// as a block scalar.
+func formatYAMLBlockScalar(value, tag string, style yaml.Style, indent int) (string, bool) {
+	if tag != "!!str" || value == "" || strings.ContainsRune(value, '\r') {
+		return "", false
+	}
+	trimmed := strings.TrimRight(value, "\n")
+	if trimmed == "" || trimmed[0] == ' ' || trimmed[0] == '\t' {
+		// Would need an indentation indicator
+		return "", false
+	}
+	for _, r := range trimmed {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	var header string
+	switch len(value) - len(trimmed) {
+	case 0:
+		header = "-"
+	case 1:
+		header = ""
+	default:
+		return "", false
+	}
+	lines := strings.Split(trimmed, "\n")
+	if style&yaml.FoldedStyle != 0 && len(lines) == 1 {
+		header = ">" + header
+	} else {
+		header = "|" + header
+	}
+	var sb strings.Builder
+	sb.WriteString(header)
+	for _, line := range lines {
+		sb.WriteByte('\n')
+		if line != "" {
+			sb.WriteString(strings.Repeat(" ", indent))
+			sb.WriteString(line)
+		}
+	}
+	return sb.String(), true
+}
+
+func canSingleQuoteYAML(value string) bool {
+	for _, r := range value {
+		if r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func canPlainYAML(value string, inFlow bool) bool {
+	if value == "" || resolveYAMLTag(value) != "!!str" {
+		return false
+	}
+	if inFlow && strings.ContainsAny(value, ",[]{}") {
+		return false
+	}
+	// Let the encoder decide, as the rules for plain scalars are quite complex
+	out, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+	return err == nil && string(out) == value+"\n"
+}
+
+func isYAMLWhitespace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// blockMappingInsert adds the keys after the last entry of the block mapping.
+func (src *yamlSource) blockMappingInsert(node *yaml.Node, keys []string, value, tag string, indentStep int) ([]yamlEdit, error) {
+	first := node.Content[0]
+	indent := first.Column - 1
+	last := first.Line - 1
+	for line := first.Line; line < len(src.lineStarts); line++ {
+		lineStart := src.lineStarts[line]
+		text := src.content[lineStart:src.lineEnd(lineStart)]
+		if yamlDocumentSeparatorRegex.Match(text) {
+			break
+		}
+		trimmed := bytes.TrimSpace(text)
+		if len(trimmed) == 0 || trimmed[0] == '#' {
+			continue
+		}
+		if src.lineIndent(line) < indent {
+			break
+		}
+		last = line
+	}
+	pos := src.lineEnd(src.lineStarts[last])
+	snippet := "\n" + formatYAMLBlockMapping(keys, value, tag, indent, indentStep)
+	return []yamlEdit{{pos, pos, []byte(snippet)}}, nil
+}
+
+// nullToMappingEdits replaces a null mapping value, such as "foo:" or
+// "foo: ~", with a block mapping of the keys.
+func (src *yamlSource) nullToMappingEdits(node *yaml.Node, keys []string, value, tag string, indentStep int) ([]yamlEdit, error) {
+	key := src.mappingKey(node)
+	if key == nil || src.isInFlow(node) {
+		return nil, fmt.Errorf("line %d: cannot add key %q to null value", node.Line, keys[0])
+	}
+	var edits []yamlEdit
+	if node.Value != "" {
+		start, err := src.offset(node)
+		if err != nil {
+			return nil, err
+		}
+		end := start + len(node.Value)
+		lineEnd := src.lineEnd(end)
+		if len(bytes.TrimSpace(src.content[end:lineEnd])) == 0 {
+			for start > 0 && src.content[start-1] == ' ' {
+				start--
+			}
+			end = lineEnd
+		} else {
+			for end < lineEnd && src.content[end] == ' ' {
+				end++
+			}
+		}
+		edits = append(edits, yamlEdit{start, end, nil})
+	}
+	pos := src.lineEnd(src.lineStarts[node.Line-1])
+	snippet := "\n" + formatYAMLBlockMapping(keys, value, tag, key.Column-1+indentStep, indentStep)
+	return append(edits, yamlEdit{pos, pos, []byte(snippet)}), nil
+}
+
+// flowMappingInsert adds the keys as the last entry of the flow mapping.
+func (src *yamlSource) flowMappingInsert(node *yaml.Node, keys []string, value, tag string) ([]yamlEdit, error) {
+	start, err := src.offset(node)
+	if err != nil {
+		return nil, err
+	}
+	end, err := src.flowCollectionEnd(start)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	entry := formatYAMLFlowMapping(keys, value, tag)
+	pos := end
+	for pos > start+1 && isYAMLWhitespace(src.content[pos-1]) {
+		pos--
+	}
+	switch {
+	case pos == start+1:
+		return []yamlEdit{{start + 1, end, []byte(entry)}}, nil
+	case src.content[pos-1] == ',':
+		return []yamlEdit{{pos, pos, []byte(" " + entry)}}, nil
+	default:
+		return []yamlEdit{{pos, pos, []byte(", " + entry)}}, nil
+	}
+}
+
+// flowCollectionEnd returns the offset of the closing bracket of the flow
+// collection starting at the given offset.
+func (src *yamlSource) flowCollectionEnd(start int) (int, error) {
+	depth := 0
+	for i := start; i < len(src.content); i++ {
+		switch src.content[i] {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		case '"':
+			end, err := src.doubleQuotedEnd(i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		case '\'':
+			end, err := src.singleQuotedEnd(i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		case '#':
+			if i > start && isYAMLWhitespace(src.content[i-1]) {
+				i = src.lineEnd(i)
+			}
+		}
+	}
+	return 0, fmt.Errorf("unterminated flow collection")
+}
+
+// formatYAMLBlockMapping formats nested block mappings of the keys, without
+// a trailing line break, such as:
+//
+//	foo:
+//	  bar: value
+func formatYAMLBlockMapping(keys []string, value, tag string, indent, indentStep int) string {
+	var sb strings.Builder
+	for i, key := range keys {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(strings.Repeat(" ", indent+i*indentStep))
+		sb.WriteString(formatYAMLScalar(key, "!!str", 0, false))
+		sb.WriteByte(':')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(formatYAMLScalar(value, tag, 0, false))
+	return sb.String()
+}
+
+// formatYAMLFlowMapping formats nested flow mapping entries of the keys,
+// such as "foo: {bar: value}".
+func formatYAMLFlowMapping(keys []string, value, tag string) string {
+	formatted := formatYAMLScalar(value, tag, 0, true)
+	for i := len(keys) - 1; i >= 0; i-- {
+		if i < len(keys)-1 {
+			formatted = "{" + formatted + "}"
+		}
+		formatted = formatYAMLScalar(keys[i], "!!str", 0, true) + ": " + formatted
+	}
+	return formatted
+}
@@ -19,11 +19,15 @@ package patch
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/git"
-	"github.com/RiskIdent/jelease/pkg/github"
+	"github.com/RiskIdent/jelease/pkg/util"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -31,12 +35,22 @@ import (
 // Repo is an object to manage patches for a single repository.
 // It is obtained from the [Patcher] object.
 type Repo struct {
-	gh      github.Client
-	ghRef   github.RepoRef
+	forge   forge.Client
+	repoRef forge.RepoRef
 	remote  string
 	repo    git.Repo
 	cfg     *config.Config
 	tmplCtx config.TemplateContext
+	// branchSuffix is added to the PR branch name, to keep it unique when
+	// creating pull requests against multiple base branches.
+	branchSuffix string
+	// prMetadata is the repository's PR metadata config, which is merged
+	// with the global config.
+	prMetadata config.GitHubPRMetadata
+	// autoMerge is the package's auto-merge config.
+	autoMerge config.PackageAutoMerge
+	// prevVersions are the versions found by the patches before patching.
+	prevVersions []string
 }
 
 // Close cleans up the Git repository by removing the entire directory.
@@ -54,59 +68,71 @@ func (p *Repo) Close() error {
 
 // ApplyManyAndCommit uses [ApplyManyInNewBranch] to apply a series of patches
 // in a new Git branch, followed by creating a Git commit.
-func (p *Repo) ApplyManyAndCommit(patches []config.PackageRepoPatch) (git.Commit, error) {
+// The patches that were skipped are returned, even on error.
+func (p *Repo) ApplyManyAndCommit(patches []config.PackageRepoPatch) (git.Commit, []Skipped, error) {
 	if len(patches) == 0 {
 		log.Warn().
 			Str("package", p.tmplCtx.Package).
 			Str("repo", p.remote).
 			Msg("No patches configured for repository.")
-		return git.Commit{}, ErrNoPatches
+		return git.Commit{}, nil, ErrNoPatches
 	}
 
-	if err := p.ApplyManyInNewBranch(patches); err != nil {
-		return git.Commit{}, fmt.Errorf("template branch name: %w", err)
+	skipped, err := p.ApplyManyInNewBranch(patches)
+	if err != nil {
+		return git.Commit{}, skipped, err
 	}
 
 	if err := p.repo.StageChanges(); err != nil {
-		return git.Commit{}, err
+		return git.Commit{}, skipped, err
 	}
 	log.Debug().Msg("Staged changes.")
 
-	commitMsg, err := p.cfg.GitHub.PR.Commit.Render(p.tmplCtx)
+	commitMsg, err := p.commitMessage()
 	if err != nil {
-		return git.Commit{}, fmt.Errorf("template commit message: %w", err)
+		return git.Commit{}, skipped, err
 	}
 	commit, err := p.repo.CreateCommit(commitMsg)
 	if err != nil {
-		return git.Commit{}, err
+		return git.Commit{}, skipped, err
 	}
 	log.Debug().
 		Str("hash", commit.AbbrHash).
 		Str("subject", commit.Subject).
 		Msg("Created commit.")
 	p.logDiff(commit.Diff)
-	return commit, nil
+	return commit, skipped, nil
+}
+
+func (p *Repo) commitMessage() (string, error) {
+	commitMsg, err := p.cfg.GitHub.PR.Commit.Render(p.pullRequestTemplateContext())
+	if err != nil {
+		return "", fmt.Errorf("template commit message: %w", err)
+	}
+	return commitMsg, nil
 }
 
 // ApplyManyInNewBranch creates a new Git branch and then applies multiple
 // patches in series using [ApplyMany].
-func (p *Repo) ApplyManyInNewBranch(patches []config.PackageRepoPatch) error {
+func (p *Repo) ApplyManyInNewBranch(patches []config.PackageRepoPatch) ([]Skipped, error) {
 	branchName, err := p.cfg.GitHub.PR.Branch.Render(p.tmplCtx)
 	if err != nil {
-		return fmt.Errorf("template branch name: %w", err)
+		return nil, fmt.Errorf("template branch name: %w", err)
 	}
+	branchName += p.branchSuffix
 	if err := p.repo.CheckoutNewBranch(branchName); err != nil {
-		return err
+		return nil, err
 	}
 	log.Debug().
 		Str("branch", p.repo.CurrentBranch()).
 		Str("base", p.repo.MainBranch()).
 		Msg("Checked out new branch.")
-	if err := ApplyMany(p.repo.Directory(), patches, p.tmplCtx); err != nil {
-		return err
+	prevVersions, skipped, err := ApplyMany(p.repo.Directory(), patches, p.tmplCtx)
+	p.prevVersions = prevVersions
+	for i := range skipped {
+		skipped[i].RepoURL = p.remote
 	}
-
-	return nil
+	return skipped, err
 }
 
 // PublishChangesUnlessDryRun calls [PublishChanges], unless dry-run is set
@@ -115,76 +141,304 @@ func (p *Repo) ApplyManyInNewBranch(patches []config.PackageRepoPatch) error {
 // If dry-run is enabled, then this function templates all PR fields
 // (title, description, etc), and then returns it as-is without pushing anything
 // to the Git remote.
-func (p *Repo) PublishChangesUnlessDryRun(commit git.Commit) (github.PullRequest, error) {
+func (p *Repo) PublishChangesUnlessDryRun(commit git.Commit) (forge.PullRequest, error) {
 	if p.cfg.DryRun {
 		log.Info().Msg("Dry run: skipping publishing changes.")
 		newPR, err := p.TemplateNewPullRequest(commit)
 		if err != nil {
-			return github.PullRequest{}, err
+			return forge.PullRequest{}, err
 		}
-		return github.PullRequest{
-			RepoRef:     newPR.RepoRef,
-			Title:       newPR.Title,
-			Description: newPR.Description,
-			Head:        newPR.Head,
-			Base:        newPR.Base,
-			Commit:      newPR.Commit,
+		return forge.PullRequest{
+			RepoRef:       newPR.RepoRef,
+			Title:         newPR.Title,
+			Description:   newPR.Description,
+			Head:          newPR.Head,
+			Base:          newPR.Base,
+			Labels:        newPR.Labels,
+			Reviewers:     newPR.Reviewers,
+			TeamReviewers: newPR.TeamReviewers,
+			Assignees:     newPR.Assignees,
+			Draft:         newPR.Draft,
+			Milestone:     newPR.Milestone,
+			Commit:        newPR.Commit,
 		}, nil
 	}
 	pr, err := p.PublishChanges(commit)
 	if err != nil {
-		return github.PullRequest{}, err
+		return forge.PullRequest{}, err
 	}
 	log.Info().Msg("Pushed changes to remote repository.")
 	return pr, nil
 }
 
 // PublishChanges will push the current Git branch to the remote, and then
-// create a GitHub pull request.
-func (p *Repo) PublishChanges(commit git.Commit) (github.PullRequest, error) {
-	if err := p.repo.PushChanges(); err != nil {
-		return github.PullRequest{}, err
+// create a pull request.
+//
+// If the branch already exists in the remote, then it's handled according to
+// the configured strategy, and an open pull request for the branch
+// is updated instead of creating a new one.
+//
+// If enabled, auto-merge is then enabled on the pull request, and the
+// pull requests of older versions are closed
+// using [Repo.SupersedePullRequests].
+func (p *Repo) PublishChanges(commit git.Commit) (forge.PullRequest, error) {
+	pr, err := p.publishPullRequest(commit)
+	if err != nil {
+		return forge.PullRequest{}, err
+	}
+	p.enableAutoMerge(&pr)
+	if p.cfg.GitHub.PR.Supersede.Enabled {
+		// The new PR is already created, so don't fail on this
+		if _, err := p.SupersedePullRequests(pr); err != nil {
+			log.Warn().Err(err).Str("url", pr.URL).
+				Msg("Failed to close superseded PRs.")
+		}
+	}
+	return pr, nil
+}
+
+func (p *Repo) publishPullRequest(commit git.Commit) (forge.PullRequest, error) {
+	branchExisted, err := p.pushBranch(&commit)
+	if err != nil {
+		return forge.PullRequest{}, err
 	}
-	log.Info().Str("branch", p.repo.CurrentBranch()).
-		Msg("Pushed changes to remote repository.")
 
 	newPR, err := p.TemplateNewPullRequest(commit)
 	if err != nil {
-		return github.PullRequest{}, err
+		return forge.PullRequest{}, err
+	}
+
+	if branchExisted {
+		pr, err := p.updateExistingPullRequest(newPR)
+		if err == nil {
+			return pr, nil
+		}
+		if !errors.Is(err, forge.ErrPullRequestNotFound) {
+			return forge.PullRequest{}, err
+		}
+		log.Debug().Str("branch", newPR.Head).
+			Msg("No open PR found for existing branch. Creating a new one.")
 	}
 
-	pr, err := p.gh.CreatePullRequest(context.TODO(), newPR)
+	pr, err := p.forge.CreatePullRequest(context.TODO(), newPR)
 	if err != nil {
-		return github.PullRequest{}, fmt.Errorf("create GitHub PR: %w", err)
+		return forge.PullRequest{}, fmt.Errorf("create PR: %w", err)
 	}
 	log.Info().
 		Str("url", pr.URL).
-		Msg("GitHub PR created.")
+		Msg("PR created.")
 	return pr, nil
 }
 
+// pushBranch pushes the current Git branch to the remote. If the branch
+// already exists in the remote, then it's handled according to the
+// configured strategy and true is returned.
+//
+// The commit is updated if it was recreated when publishing it, or replaced
+// by the remote branch's commit if the branch was kept as-is.
+func (p *Repo) pushBranch(commit *git.Commit) (bool, error) {
+	branch := p.repo.CurrentBranch()
+	remoteHash, err := p.repo.RemoteBranchHash(branch)
+	if errors.Is(err, git.ErrRemoteBranchNotFound) {
+		if err := p.pushCommit(commit, ""); err != nil {
+			return false, err
+		}
+		log.Info().Str("branch", branch).
+			Msg("Pushed changes to remote repository.")
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch p.cfg.GitHub.PR.ExistingBranch {
+	case "", config.ExistingBranchForcePush:
+		if err := p.pushCommit(commit, remoteHash); err != nil {
+			return true, err
+		}
+		log.Info().Str("branch", branch).Str("previous", remoteHash).
+			Msg("Force pushed changes to existing branch in remote repository.")
+	case config.ExistingBranchKeep:
+		// The local commit is not pushed, so report the one on the branch
+		*commit = git.Commit{Hash: remoteHash, AbbrHash: remoteHash[:min(len(remoteHash), 7)]}
+		log.Info().Str("branch", branch).Str("hash", remoteHash).
+			Msg("Branch already exists in remote repository. Keeping it as-is.")
+	case config.ExistingBranchFail:
+		return true, fmt.Errorf("%w: %s", ErrBranchExists, branch)
+	default:
+		return true, fmt.Errorf("unsupported existing branch strategy: %q", p.cfg.GitHub.PR.ExistingBranch)
+	}
+	return true, nil
+}
+
+// pushCommit publishes the commit using the configured publish mode.
+// If existingHash is set, then the existing remote branch is overwritten.
+func (p *Repo) pushCommit(commit *git.Commit, existingHash string) error {
+	switch p.cfg.GitHub.PR.PublishMode {
+	case "", config.PublishModePush:
+		if existingHash == "" {
+			return p.repo.PushChanges()
+		}
+		return p.repo.ForcePushChanges(existingHash)
+	case config.PublishModeAPI:
+		return p.pushCommitViaAPI(commit, existingHash != "")
+	default:
+		return fmt.Errorf("unsupported publish mode: %q", p.cfg.GitHub.PR.PublishMode)
+	}
+}
+
+// pushCommitViaAPI recreates the commit using the GitHub Git Data API,
+// as commits created that way are signed by GitHub.
+//
+// The GitHub API has no way of only updating the branch if it still points
+// at a given commit, so unlike [git.Repo.ForcePushChanges] this overwrites
+// the existing branch unconditionally.
+func (p *Repo) pushCommitViaAPI(commit *git.Commit, branchExists bool) error {
+	files, err := p.repo.ChangedFiles(*commit)
+	if err != nil {
+		return err
+	}
+	message, err := p.commitMessage()
+	if err != nil {
+		return err
+	}
+	hash, err := p.forge.CreateCommit(context.TODO(), forge.NewCommit{
+		RepoRef:    p.repoRef,
+		ParentHash: commit.ParentHash,
+		Message:    message,
+		Files:      files,
+	})
+	if err != nil {
+		return fmt.Errorf("create commit via GitHub API: %w", err)
+	}
+	branch := p.repo.CurrentBranch()
+	if branchExists {
+		err = p.forge.UpdateBranch(context.TODO(), p.repoRef, branch, hash)
+	} else {
+		err = p.forge.CreateBranch(context.TODO(), p.repoRef, branch, hash)
+	}
+	if err != nil {
+		return err
+	}
+	log.Debug().
+		Str("local", commit.AbbrHash).
+		Str("hash", hash).
+		Int("files", len(files)).
+		Msg("Recreated commit via GitHub API.")
+	commit.Hash = hash
+	commit.AbbrHash = hash[:min(len(hash), 7)]
+	return nil
+}
+
+func (p *Repo) updateExistingPullRequest(newPR forge.NewPullRequest) (forge.PullRequest, error) {
+	existing, err := p.forge.FindPullRequest(context.TODO(), newPR.RepoRef, newPR.Head, newPR.Base)
+	if err != nil {
+		if errors.Is(err, forge.ErrPullRequestNotFound) {
+			return forge.PullRequest{}, err
+		}
+		return forge.PullRequest{}, fmt.Errorf("find existing PR: %w", err)
+	}
+	if p.cfg.GitHub.PR.ExistingBranch == config.ExistingBranchKeep {
+		// The branch was left as-is, so its PR still describes it
+		existing.Commit = newPR.Commit
+		log.Info().
+			Str("url", existing.URL).
+			Msg("Keeping existing PR as-is.")
+		return existing, nil
+	}
+	pr, err := p.forge.UpdatePullRequest(context.TODO(), existing.Number, newPR)
+	if err != nil {
+		return forge.PullRequest{}, fmt.Errorf("update existing PR: %w", err)
+	}
+	log.Info().
+		Str("url", pr.URL).
+		Msg("PR updated.")
+	return pr, nil
+}
+
+// TemplateContextPullRequest is the data of the commit message, PR title,
+// PR description, and PR label templates, which are rendered after patching.
+type TemplateContextPullRequest struct {
+	config.TemplateContext
+	// PreviousVersions are the versions found by the patches before
+	// patching, without duplicates.
+	PreviousVersions []string
+	// VersionChange is the largest change from the previous versions to the
+	// new version: "patch", "minor", or "major". Empty if the patches found
+	// no previous version, or if any of the versions can't be parsed.
+	VersionChange config.VersionChange
+}
+
+func (p *Repo) pullRequestTemplateContext() TemplateContextPullRequest {
+	tmplCtx := TemplateContextPullRequest{TemplateContext: p.tmplCtx}
+	for _, prev := range p.prevVersions {
+		if !slices.Contains(tmplCtx.PreviousVersions, prev) {
+			tmplCtx.PreviousVersions = append(tmplCtx.PreviousVersions, prev)
+		}
+	}
+	if len(tmplCtx.PreviousVersions) == 1 {
+		tmplCtx.PreviousVersion = tmplCtx.PreviousVersions[0]
+	}
+	if change, _, err := largestVersionChange(p.prevVersions, p.tmplCtx.Version); err == nil {
+		tmplCtx.VersionChange = change
+	}
+	return tmplCtx
+}
+
 // TemplateNewPullRequest will template using [text/template] the pull request
 // fields (title, description, etc), based on what's set in the config.
-func (p *Repo) TemplateNewPullRequest(commit git.Commit) (github.NewPullRequest, error) {
-	title, err := p.cfg.GitHub.PR.Title.Render(p.tmplCtx)
+func (p *Repo) TemplateNewPullRequest(commit git.Commit) (forge.NewPullRequest, error) {
+	tmplCtx := p.pullRequestTemplateContext()
+	title, err := p.cfg.GitHub.PR.Title.Render(tmplCtx)
+	if err != nil {
+		return forge.NewPullRequest{}, fmt.Errorf("template PR title: %w", err)
+	}
+	description, err := p.cfg.GitHub.PR.Description.Render(tmplCtx)
 	if err != nil {
-		return github.NewPullRequest{}, fmt.Errorf("template PR title: %w", err)
+		return forge.NewPullRequest{}, fmt.Errorf("template PR description: %w", err)
 	}
-	description, err := p.cfg.GitHub.PR.Description.Render(p.tmplCtx)
+	metadata := p.cfg.GitHub.PR.GitHubPRMetadata.Merge(p.prMetadata)
+	labels, err := templateLabels(metadata.Labels, tmplCtx)
 	if err != nil {
-		return github.NewPullRequest{}, fmt.Errorf("template PR description: %w", err)
+		return forge.NewPullRequest{}, err
 	}
 
-	return github.NewPullRequest{
-		RepoRef:     p.ghRef,
-		Title:       title,
-		Description: description,
-		Head:        p.repo.CurrentBranch(),
-		Base:        p.repo.MainBranch(),
-		Commit:      commit,
+	return forge.NewPullRequest{
+		RepoRef:       p.repoRef,
+		Title:         title,
+		Description:   description,
+		Head:          p.repo.CurrentBranch(),
+		Base:          p.repo.MainBranch(),
+		Labels:        labels,
+		Reviewers:     metadata.Reviewers,
+		TeamReviewers: metadata.TeamReviewers,
+		Assignees:     metadata.Assignees,
+		Draft:         util.Deref(metadata.Draft, false),
+		Milestone:     util.Deref(metadata.Milestone, ""),
+		Commit:        commit,
 	}, nil
 }
 
+// templateLabels renders the PR labels, skipping the ones that render
+// empty so labels can be added conditionally.
+func templateLabels(templates []*config.Template, tmplCtx TemplateContextPullRequest) ([]string, error) {
+	var labels []string
+	for i, tmpl := range templates {
+		if tmpl == nil {
+			continue
+		}
+		label, err := tmpl.Render(tmplCtx)
+		if err != nil {
+			return nil, fmt.Errorf("template PR label #%d: %w", i+1, err)
+		}
+		label = strings.TrimSpace(label)
+		if label != "" && !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
 // logDiff sends a log message with a commit diff. Will optionally colorize it
 // if log format is set to "pretty".
 func (p *Repo) logDiff(diff string) {
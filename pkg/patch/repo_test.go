@@ -0,0 +1,382 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+	"github.com/RiskIdent/jelease/pkg/util"
+)
+
+type fakeGitRepo struct {
+	git.Repo
+	remoteHash  string
+	pushed      bool
+	forcePushed string
+}
+
+func (r *fakeGitRepo) CurrentBranch() string { return "jelease/pkg-v2.0.0" }
+func (r *fakeGitRepo) MainBranch() string    { return "main" }
+
+func (r *fakeGitRepo) RemoteBranchHash(string) (string, error) {
+	if r.remoteHash == "" {
+		return "", git.ErrRemoteBranchNotFound
+	}
+	return r.remoteHash, nil
+}
+
+func (r *fakeGitRepo) PushChanges() error {
+	if r.remoteHash != "" {
+		return errors.New("non-fast-forward")
+	}
+	r.pushed = true
+	return nil
+}
+
+func (r *fakeGitRepo) ForcePushChanges(expectedHash string) error {
+	r.forcePushed = expectedHash
+	return nil
+}
+
+func (r *fakeGitRepo) ChangedFiles(git.Commit) ([]git.ChangedFile, error) {
+	return []git.ChangedFile{{Path: "file.txt", Mode: "100644", Content: []byte("v2.0.0\n")}}, nil
+}
+
+type fakeForge struct {
+	forge.Client
+	existing        *forge.PullRequest
+	created         bool
+	updated         int
+	commit          forge.NewCommit
+	createdBranch   string
+	updatedBranch   string
+	open            []forge.PullRequest
+	closed          map[int]string
+	deletedBranches []string
+	autoMerged      config.MergeMethod
+}
+
+func (c *fakeForge) CreatePullRequest(_ context.Context, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	c.created = true
+	return forge.PullRequest{Number: 2, Title: pr.Title, Commit: pr.Commit}, nil
+}
+
+func (c *fakeForge) CreateCommit(_ context.Context, commit forge.NewCommit) (string, error) {
+	c.commit = commit
+	return "0123456789abcdef", nil
+}
+
+func (c *fakeForge) CreateBranch(_ context.Context, _ forge.RepoRef, branch, hash string) error {
+	c.createdBranch = branch + "@" + hash
+	return nil
+}
+
+func (c *fakeForge) UpdateBranch(_ context.Context, _ forge.RepoRef, branch, hash string) error {
+	c.updatedBranch = branch + "@" + hash
+	return nil
+}
+
+func (c *fakeForge) FindPullRequest(context.Context, forge.RepoRef, string, string) (forge.PullRequest, error) {
+	if c.existing == nil {
+		return forge.PullRequest{}, forge.ErrPullRequestNotFound
+	}
+	return *c.existing, nil
+}
+
+func (c *fakeForge) UpdatePullRequest(_ context.Context, number int, pr forge.NewPullRequest) (forge.PullRequest, error) {
+	c.updated = number
+	return forge.PullRequest{Number: number, Title: pr.Title, Updated: true}, nil
+}
+
+func (c *fakeForge) ListPullRequests(context.Context, forge.RepoRef, string) ([]forge.PullRequest, error) {
+	return c.open, nil
+}
+
+func (c *fakeForge) ClosePullRequest(_ context.Context, _ forge.RepoRef, number int, comment string) error {
+	if c.closed == nil {
+		c.closed = map[int]string{}
+	}
+	c.closed[number] = comment
+	return nil
+}
+
+func (c *fakeForge) DeleteBranch(_ context.Context, _ forge.RepoRef, branch string) error {
+	c.deletedBranches = append(c.deletedBranches, branch)
+	return nil
+}
+
+func (c *fakeForge) EnableAutoMerge(_ context.Context, _ forge.PullRequest, method config.MergeMethod) error {
+	c.autoMerged = method
+	return nil
+}
+
+func TestPublishChanges_existingBranch(t *testing.T) {
+	tests := []struct {
+		name            string
+		strategy        config.ExistingBranchStrategy
+		remoteHash      string
+		existingPR      *forge.PullRequest
+		wantErr         error
+		wantPushed      bool
+		wantForcePushed string
+		wantCreated     bool
+		wantUpdated     int
+		wantTitle       string
+		wantCommitHash  string
+	}{
+		{
+			name:        "new branch",
+			wantPushed:  true,
+			wantCreated: true,
+		},
+		{
+			name:            "force push and update PR",
+			strategy:        config.ExistingBranchForcePush,
+			remoteHash:      "abc123",
+			existingPR:      &forge.PullRequest{Number: 1},
+			wantForcePushed: "abc123",
+			wantUpdated:     1,
+		},
+		{
+			name:            "force push by default and create missing PR",
+			remoteHash:      "abc123",
+			wantForcePushed: "abc123",
+			wantCreated:     true,
+		},
+		{
+			name:           "keep branch and PR",
+			strategy:       config.ExistingBranchKeep,
+			remoteHash:     "abc123",
+			existingPR:     &forge.PullRequest{Number: 1, Title: "Old title"},
+			wantTitle:      "Old title",
+			wantCommitHash: "abc123",
+		},
+		{
+			name:           "keep branch and create missing PR",
+			strategy:       config.ExistingBranchKeep,
+			remoteHash:     "abc123",
+			wantCreated:    true,
+			wantCommitHash: "abc123",
+		},
+		{
+			name:       "fail",
+			strategy:   config.ExistingBranchFail,
+			remoteHash: "abc123",
+			existingPR: &forge.PullRequest{Number: 1},
+			wantErr:    ErrBranchExists,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gitRepo := &fakeGitRepo{remoteHash: tc.remoteHash}
+			fake := &fakeForge{existing: tc.existingPR}
+			cfg := &config.Config{}
+			cfg.GitHub.PR.Title = mustTemplate(t, "Update {{ .Package }}")
+			cfg.GitHub.PR.Description = mustTemplate(t, "Description")
+			cfg.GitHub.PR.ExistingBranch = tc.strategy
+			repo := &Repo{
+				forge:   fake,
+				repo:    gitRepo,
+				cfg:     cfg,
+				tmplCtx: config.TemplateContext{Package: "pkg", Version: "v2.0.0"},
+			}
+
+			pr, err := repo.PublishChanges(git.Commit{Hash: "local123"})
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("want error %v, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gitRepo.pushed != tc.wantPushed {
+				t.Errorf("want pushed %t, got %t", tc.wantPushed, gitRepo.pushed)
+			}
+			if gitRepo.forcePushed != tc.wantForcePushed {
+				t.Errorf("want force pushed with lease %q, got %q", tc.wantForcePushed, gitRepo.forcePushed)
+			}
+			if fake.created != tc.wantCreated {
+				t.Errorf("want PR created %t, got %t", tc.wantCreated, fake.created)
+			}
+			if fake.updated != tc.wantUpdated {
+				t.Errorf("want PR #%d updated, got #%d", tc.wantUpdated, fake.updated)
+			}
+			if pr.Updated != (tc.wantUpdated != 0) {
+				t.Errorf("want returned PR updated %t, got %t", tc.wantUpdated != 0, pr.Updated)
+			}
+			if want := cmp.Or(tc.wantTitle, "Update pkg"); pr.Title != want {
+				t.Errorf("want returned PR title %q, got %q", want, pr.Title)
+			}
+			if tc.wantCommitHash != "" && pr.Commit.Hash != tc.wantCommitHash {
+				t.Errorf("want returned PR commit %q, got %q", tc.wantCommitHash, pr.Commit.Hash)
+			}
+		})
+	}
+}
+
+func TestPublishChanges_apiMode(t *testing.T) {
+	tests := []struct {
+		name              string
+		remoteHash        string
+		wantCreatedBranch string
+		wantUpdatedBranch string
+	}{
+		{
+			name:              "new branch",
+			wantCreatedBranch: "jelease/pkg-v2.0.0@0123456789abcdef",
+		},
+		{
+			name:              "existing branch",
+			remoteHash:        "abc123",
+			wantUpdatedBranch: "jelease/pkg-v2.0.0@0123456789abcdef",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gitRepo := &fakeGitRepo{remoteHash: tc.remoteHash}
+			fake := &fakeForge{}
+			cfg := &config.Config{}
+			cfg.GitHub.PR.Title = mustTemplate(t, "Update {{ .Package }}")
+			cfg.GitHub.PR.Description = mustTemplate(t, "Description")
+			cfg.GitHub.PR.Commit = mustTemplate(t, "Update {{ .Package }} to {{ .Version }}\n\nBody")
+			cfg.GitHub.PR.PublishMode = config.PublishModeAPI
+			repo := &Repo{
+				forge:   fake,
+				repo:    gitRepo,
+				cfg:     cfg,
+				tmplCtx: config.TemplateContext{Package: "pkg", Version: "v2.0.0"},
+			}
+
+			pr, err := repo.PublishChanges(git.Commit{Hash: "local", ParentHash: "parent123"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gitRepo.pushed || gitRepo.forcePushed != "" {
+				t.Error("want no Git push in API mode")
+			}
+			if fake.commit.ParentHash != "parent123" || len(fake.commit.Files) != 1 {
+				t.Errorf("want API commit on parent %q with 1 file, got %q with %d files", "parent123", fake.commit.ParentHash, len(fake.commit.Files))
+			}
+			if want := "Update pkg to v2.0.0\n\nBody"; fake.commit.Message != want {
+				t.Errorf("want commit message %q, got %q", want, fake.commit.Message)
+			}
+			if fake.createdBranch != tc.wantCreatedBranch {
+				t.Errorf("want created branch %q, got %q", tc.wantCreatedBranch, fake.createdBranch)
+			}
+			if fake.updatedBranch != tc.wantUpdatedBranch {
+				t.Errorf("want updated branch %q, got %q", tc.wantUpdatedBranch, fake.updatedBranch)
+			}
+			if pr.Commit.Hash != "0123456789abcdef" || pr.Commit.AbbrHash != "0123456" {
+				t.Errorf("want PR commit to be the API commit, got %q (%q)", pr.Commit.Hash, pr.Commit.AbbrHash)
+			}
+		})
+	}
+}
+
+func TestTemplateNewPullRequest_metadata(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GitHub.PR.Title = mustTemplate(t, "Update {{ .Package }}{{ with .PreviousVersion }} from {{ . }}{{ end }} to {{ .Version }}")
+	cfg.GitHub.PR.Description = mustTemplate(t, "Description")
+	cfg.GitHub.PR.GitHubPRMetadata = config.GitHubPRMetadata{
+		Labels: []*config.Template{
+			mustTemplate(t, "dependencies"),
+			mustTemplate(t, `{{ if eq .VersionChange "major" }}major-update{{ end }}`),
+		},
+		Reviewers: []string{"alice"},
+		Draft:     util.Ref(true),
+	}
+
+	tests := []struct {
+		name         string
+		prevVersions []string
+		version      string
+		wantTitle    string
+		wantLabels   []string
+	}{
+		{
+			name:         "major",
+			prevVersions: []string{"v1.9.0"},
+			version:      "v2.1.0",
+			wantTitle:    "Update pkg from v1.9.0 to v2.1.0",
+			wantLabels:   []string{"dependencies", "major-update", "team-a"},
+		},
+		{
+			name:         "minor",
+			prevVersions: []string{"v2.0.0", "v2.0.0"},
+			version:      "v2.1.0",
+			wantTitle:    "Update pkg from v2.0.0 to v2.1.0",
+			wantLabels:   []string{"dependencies", "team-a"},
+		},
+		{
+			name:         "largest of different previous versions",
+			prevVersions: []string{"v2.0.0", "v1.9.0"},
+			version:      "v2.1.0",
+			wantTitle:    "Update pkg to v2.1.0",
+			wantLabels:   []string{"dependencies", "major-update", "team-a"},
+		},
+		{
+			name:       "no previous version",
+			version:    "v1.0.0",
+			wantTitle:  "Update pkg to v1.0.0",
+			wantLabels: []string{"dependencies", "team-a"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &Repo{
+				repo:         &fakeGitRepo{},
+				cfg:          cfg,
+				tmplCtx:      config.TemplateContext{Package: "pkg", Version: tc.version},
+				prevVersions: tc.prevVersions,
+				prMetadata: config.GitHubPRMetadata{
+					Labels:        []*config.Template{mustTemplate(t, "team-a")},
+					TeamReviewers: []string{"team-a"},
+					Milestone:     util.Ref("Q3"),
+				},
+			}
+			newPR, err := repo.TemplateNewPullRequest(git.Commit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if newPR.Title != tc.wantTitle {
+				t.Errorf("want title %q, got %q", tc.wantTitle, newPR.Title)
+			}
+			if !slices.Equal(newPR.Labels, tc.wantLabels) {
+				t.Errorf("want labels %q, got %q", tc.wantLabels, newPR.Labels)
+			}
+			if !slices.Equal(newPR.Reviewers, []string{"alice"}) || !slices.Equal(newPR.TeamReviewers, []string{"team-a"}) {
+				t.Errorf("want reviewers [alice] and teams [team-a], got %q and %q", newPR.Reviewers, newPR.TeamReviewers)
+			}
+			if !newPR.Draft || newPR.Milestone != "Q3" {
+				t.Errorf("want draft in milestone Q3, got draft=%t milestone=%q", newPR.Draft, newPR.Milestone)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+	"github.com/rs/zerolog/log"
+)
+
+// sparsePaths returns the directories to check out for the repository,
+// which are the configured sparse paths plus the directories of the files
+// that the patches touch. Returns nil if sparse checkout is disabled,
+// meaning the whole repository is checked out, and an empty slice if the
+// patches only touch files in the root directory, which are always
+// checked out.
+func sparsePaths(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) ([]string, error) {
+	if !pkgRepo.SparseCheckout && len(pkgRepo.SparsePaths) == 0 {
+		return nil, nil
+	}
+	dirs := []string{}
+	add := func(dir string) error {
+		dir = path.Clean(dir)
+		if !filepath.IsLocal(dir) {
+			return fmt.Errorf("%w: %q", filestore.ErrPathOutsideDir, dir)
+		}
+		// The root directory is always checked out
+		if dir != "." && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+		return nil
+	}
+	for _, p := range pkgRepo.SparsePaths {
+		if err := add(p); err != nil {
+			return nil, fmt.Errorf("repo %s: sparse path: %w", pkgRepo.URL, err)
+		}
+	}
+	for i, patch := range pkgRepo.Patches {
+		var files []string
+		if patch.Regex != nil {
+			files = append(files, patch.Regex.File)
+		}
+		if patch.YAML != nil {
+			files = append(files, patch.YAML.File)
+		}
+		if patch.HCL != nil {
+			files = append(files, patch.HCL.File)
+		}
+		if patch.Changelog != nil {
+			files = append(files, patch.Changelog.File)
+		}
+		if patch.File != nil {
+			files = append(files, patch.File.File)
+		}
+		for _, file := range files {
+			if err := add(path.Dir(file)); err != nil {
+				return nil, fmt.Errorf("repo %s, patch #%d: %w", pkgRepo.URL, i+1, err)
+			}
+		}
+		if patch.HelmDepUpdate != nil && patch.HelmDepUpdate.Chart != nil {
+			// The whole chart dir, as Helm needs more than just Chart.yaml
+			chart, err := patch.HelmDepUpdate.Chart.Render(tmplCtx)
+			if err != nil {
+				return nil, fmt.Errorf("repo %s, patch #%d: execute chart dir template: %w", pkgRepo.URL, i+1, err)
+			}
+			if err := add(chart); err != nil {
+				return nil, fmt.Errorf("repo %s, patch #%d: %w", pkgRepo.URL, i+1, err)
+			}
+		}
+		if len(patch.Custom) > 0 {
+			log.Warn().Str("repo", pkgRepo.URL).Int("patch", i+1).
+				Msg("Cannot know which files custom patches touch. Add them to sparsePaths if they're missing in the sparse checkout.")
+		}
+	}
+	return dirs, nil
+}
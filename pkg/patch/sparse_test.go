@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/patch/filestore"
+)
+
+func TestSparsePaths(t *testing.T) {
+	patches := []config.PackageRepoPatch{
+		{Regex: &config.PatchRegex{File: "deploy/helm/Chart.yaml"}},
+		{YAML: &config.PatchYAML{File: "deploy/helm/values.yaml"}},
+		{Changelog: &config.PatchChangelog{File: "CHANGELOG.md"}},
+		{HelmDepUpdate: &config.PatchHelmDepUpdate{Chart: mustTemplate(t, "charts/{{ .Package }}")}},
+	}
+
+	tests := []struct {
+		name    string
+		pkgRepo config.PackageRepo
+		want    []string
+	}{
+		{
+			name:    "disabled",
+			pkgRepo: config.PackageRepo{Patches: patches},
+			want:    nil,
+		},
+		{
+			name:    "derived from patches",
+			pkgRepo: config.PackageRepo{SparseCheckout: true, Patches: patches},
+			want:    []string{"deploy/helm", "charts/jelease"},
+		},
+		{
+			name:    "sparse paths enables it",
+			pkgRepo: config.PackageRepo{SparsePaths: []string{"charts/common/"}, Patches: patches},
+			want:    []string{"charts/common", "deploy/helm", "charts/jelease"},
+		},
+		{
+			name: "only root files",
+			pkgRepo: config.PackageRepo{SparseCheckout: true, Patches: []config.PackageRepoPatch{
+				{File: &config.PatchFile{File: "versions.lock"}},
+				{Regex: &config.PatchRegex{File: "./Chart.lock"}},
+			}},
+			want: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sparsePaths(tc.pkgRepo, config.TemplateContext{Package: "jelease"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// nil means a full clone, so it must not be mixed up with empty
+			if (got == nil) != (tc.want == nil) || !slices.Equal(got, tc.want) {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSparsePaths_outsideRepo(t *testing.T) {
+	pkgRepo := config.PackageRepo{SparsePaths: []string{"../other-repo"}}
+	if _, err := sparsePaths(pkgRepo, config.TemplateContext{}); !errors.Is(err, filestore.ErrPathOutsideDir) {
+		t.Fatalf("want ErrPathOutsideDir, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/version"
+	"github.com/rs/zerolog/log"
+)
+
+// versionMarker is rendered in place of the version in the PR branch name,
+// to find where the version is in the branch names of older pull requests.
+// It is lowercase, as templates commonly sanitize the branch name.
+const versionMarker = "jeleaseversionmarker"
+
+// TemplateContextSupersede is the data of the comment template posted on
+// superseded pull requests.
+type TemplateContextSupersede struct {
+	config.TemplateContext
+	// PullRequest is the new pull request.
+	PullRequest forge.PullRequest
+	// Superseded is the pull request that is closed.
+	Superseded forge.PullRequest
+}
+
+// prBranchPattern returns a regexp matching the PR branch name of any
+// version of the package, with the version in the first group.
+func (p *Repo) prBranchPattern() (*regexp.Regexp, error) {
+	tmplCtx := p.tmplCtx
+	tmplCtx.Version = versionMarker
+	branchName, err := p.cfg.GitHub.PR.Branch.Render(tmplCtx)
+	if err != nil {
+		return nil, fmt.Errorf("template branch name: %w", err)
+	}
+	before, after, ok := strings.Cut(branchName+p.branchSuffix, versionMarker)
+	if !ok {
+		return nil, fmt.Errorf("branch name template must contain the version as-is, but got: %q", branchName)
+	}
+	// Requiring a digit avoids matching the branches of other packages,
+	// such as "jelease/foo-bar-v1.0.0" when looking for "jelease/foo-v1.0.0"
+	return regexp.Compile("^" + regexp.QuoteMeta(before) + `([vV]?[0-9][^/]*)` + regexp.QuoteMeta(after) + "$")
+}
+
+// SupersedePullRequests closes the open pull requests of older versions of
+// the package into the same base branch, and deletes their branches.
+// Pull requests with the configured keep label are left open.
+func (p *Repo) SupersedePullRequests(pr forge.PullRequest) ([]forge.PullRequest, error) {
+	cfg := p.cfg.GitHub.PR.Supersede
+	pattern, err := p.prBranchPattern()
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := version.Parse(p.tmplCtx.Version)
+	if err != nil {
+		return nil, fmt.Errorf("parse version %q: %w", p.tmplCtx.Version, err)
+	}
+	prs, err := p.forge.ListPullRequests(context.TODO(), p.repoRef, p.repo.MainBranch())
+	if err != nil {
+		return nil, fmt.Errorf("list PRs: %w", err)
+	}
+
+	var closed []forge.PullRequest
+	for _, old := range prs {
+		if old.Number == pr.Number {
+			continue
+		}
+		// Skip PRs from forks, which have the fork's owner in the label
+		if old.Head != p.repoRef.Owner+":"+old.HeadBranch {
+			continue
+		}
+		match := pattern.FindStringSubmatch(old.HeadBranch)
+		if match == nil {
+			continue
+		}
+		oldVersion, err := version.Parse(match[1])
+		if err != nil {
+			log.Debug().Err(err).Str("branch", old.HeadBranch).
+				Msg("Skipping PR, as its branch has no valid version.")
+			continue
+		}
+		if oldVersion.Compare(newVersion) >= 0 {
+			continue
+		}
+		if cfg.KeepLabel != "" && slices.Contains(old.Labels, cfg.KeepLabel) {
+			log.Info().Str("url", old.URL).Str("label", cfg.KeepLabel).
+				Msg("Not closing superseded PR, as it has the keep label.")
+			continue
+		}
+		if err := p.closeSupersededPullRequest(pr, old); err != nil {
+			return closed, err
+		}
+		closed = append(closed, old)
+	}
+	return closed, nil
+}
+
+func (p *Repo) closeSupersededPullRequest(pr, old forge.PullRequest) error {
+	var comment string
+	if tmpl := p.cfg.GitHub.PR.Supersede.Comment; tmpl != nil {
+		var err error
+		comment, err = tmpl.Render(TemplateContextSupersede{
+			TemplateContext: p.tmplCtx,
+			PullRequest:     pr,
+			Superseded:      old,
+		})
+		if err != nil {
+			return fmt.Errorf("template supersede comment: %w", err)
+		}
+	}
+	if err := p.forge.ClosePullRequest(context.TODO(), p.repoRef, old.Number, comment); err != nil {
+		return fmt.Errorf("close superseded PR #%d: %w", old.Number, err)
+	}
+	log.Info().Str("url", old.URL).Str("supersededBy", pr.URL).
+		Msg("Closed superseded PR.")
+	if err := p.forge.DeleteBranch(context.TODO(), p.repoRef, old.HeadBranch); err != nil {
+		return fmt.Errorf("delete branch of superseded PR #%d: %w", old.Number, err)
+	}
+	log.Debug().Str("branch", old.HeadBranch).Msg("Deleted branch of superseded PR.")
+	return nil
+}
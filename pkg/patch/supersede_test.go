@@ -0,0 +1,95 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/git"
+)
+
+func TestPublishChanges_supersede(t *testing.T) {
+	openPR := func(number int, branch string, labels ...string) forge.PullRequest {
+		return forge.PullRequest{
+			Number:     number,
+			Head:       "RiskIdent:" + branch,
+			HeadBranch: branch,
+			Labels:     labels,
+		}
+	}
+	fake := &fakeForge{open: []forge.PullRequest{
+		openPR(1, "jelease/pkg-v1.2.0"),
+		openPR(2, "jelease/pkg-v1.3.0", "do-not-close"),
+		openPR(3, "jelease/pkg-v1.9.0"),
+		openPR(4, "jelease/pkg-v3.0.0"),
+		openPR(5, "jelease/pkg-extra-v1.0.0"),
+		openPR(6, "some-feature"),
+		{Number: 7, Head: "some-fork:jelease/pkg-v1.0.0", HeadBranch: "jelease/pkg-v1.0.0"},
+		// The newly created PR, see [fakeForge.CreatePullRequest]
+		openPR(2, "jelease/pkg-v2.0.0"),
+	}}
+	cfg := &config.Config{}
+	cfg.GitHub.PR.Title = mustTemplate(t, "Update {{ .Package }}")
+	cfg.GitHub.PR.Description = mustTemplate(t, "Description")
+	cfg.GitHub.PR.Branch = mustTemplate(t, "jelease/{{ .Package | sanitizePathSegment }}-{{ .Version | sanitizePathSegment }}")
+	cfg.GitHub.PR.Supersede = config.GitHubPRSupersede{
+		Enabled:   true,
+		Comment:   mustTemplate(t, "Superseded by #{{ .PullRequest.Number }} ({{ .Version }}), closing #{{ .Superseded.Number }}"),
+		KeepLabel: "do-not-close",
+	}
+	repo := &Repo{
+		forge:   fake,
+		repoRef: forge.RepoRef{Owner: "RiskIdent", Repo: "jelease"},
+		repo:    &fakeGitRepo{},
+		cfg:     cfg,
+		tmplCtx: config.TemplateContext{Package: "pkg", Version: "v2.0.0"},
+	}
+
+	if _, err := repo.PublishChanges(git.Commit{}); err != nil {
+		t.Fatal(err)
+	}
+
+	wantClosed := map[int]string{
+		1: "Superseded by #2 (v2.0.0), closing #1",
+		3: "Superseded by #2 (v2.0.0), closing #3",
+	}
+	if len(fake.closed) != len(wantClosed) {
+		t.Errorf("want closed PRs %v, got %v", wantClosed, fake.closed)
+	}
+	for number, want := range wantClosed {
+		if got := fake.closed[number]; got != want {
+			t.Errorf("PR #%d: want closed with comment %q, got %q", number, want, got)
+		}
+	}
+	wantDeleted := []string{"jelease/pkg-v1.2.0", "jelease/pkg-v1.9.0"}
+	if !slices.Equal(fake.deletedBranches, wantDeleted) {
+		t.Errorf("want deleted branches %q, got %q", wantDeleted, fake.deletedBranches)
+	}
+}
+
+func TestPrBranchPattern_missingVersion(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.GitHub.PR.Branch = mustTemplate(t, "jelease/{{ .Package }}")
+	repo := &Repo{cfg: cfg, tmplCtx: config.TemplateContext{Package: "pkg", Version: "v2.0.0"}}
+	if _, err := repo.prBranchPattern(); err == nil {
+		t.Fatal("want error when the branch name has no version, got nil")
+	}
+}
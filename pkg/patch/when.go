@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/rs/zerolog/log"
+)
+
+// filterRepoByWhen evaluates the `when` conditions of the repository and its
+// patches, and returns the repository with only the patches that should be
+// applied. The returned bool is false if the whole repository is skipped.
+func filterRepoByWhen(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) (config.PackageRepo, []Skipped, bool, error) {
+	ok, reason, err := evaluateWhen(pkgRepo.When, tmplCtx)
+	if err != nil {
+		return pkgRepo, nil, false, fmt.Errorf("repo %s: %w", pkgRepo.URL, err)
+	}
+	if !ok {
+		log.Info().Str("repo", pkgRepo.URL).Str("reason", reason).Msg("Skipping repo.")
+		return pkgRepo, []Skipped{{RepoURL: pkgRepo.URL, PatchIndex: -1, Reason: reason}}, false, nil
+	}
+	if len(pkgRepo.Patches) == 0 {
+		return pkgRepo, nil, true, nil
+	}
+
+	var skipped []Skipped
+	patches := make([]config.PackageRepoPatch, 0, len(pkgRepo.Patches))
+	for i, patch := range pkgRepo.Patches {
+		ok, reason, err := evaluateWhen(patch.When, tmplCtx)
+		if err != nil {
+			return pkgRepo, nil, false, fmt.Errorf("repo %s, patch #%d: %w", pkgRepo.URL, i+1, err)
+		}
+		if !ok {
+			log.Info().Str("repo", pkgRepo.URL).Int("patch", i+1).Str("reason", reason).Msg("Skipping patch.")
+			skipped = append(skipped, Skipped{RepoURL: pkgRepo.URL, PatchIndex: i, Reason: reason})
+			continue
+		}
+		patches = append(patches, patch)
+	}
+	if len(patches) == 0 {
+		log.Info().Str("repo", pkgRepo.URL).Msg("Skipping repo, as all its patches were skipped.")
+		return pkgRepo, skipped, false, nil
+	}
+	pkgRepo.Patches = patches
+	return pkgRepo, skipped, true, nil
+}
+
+// filterBaseBranches renders the configured base branches of the repository,
+// skipping the ones whose `when` condition does not render "true".
+// If the repository has no base branches configured, then a single empty
+// string is returned, meaning the repository's default branch.
+func filterBaseBranches(pkgRepo config.PackageRepo, tmplCtx config.TemplateContext) ([]string, []Skipped, error) {
+	if len(pkgRepo.Branches) == 0 {
+		return []string{""}, nil, nil
+	}
+	var bases []string
+	var skipped []Skipped
+	for i, branch := range pkgRepo.Branches {
+		if branch.Name == nil {
+			return nil, nil, fmt.Errorf("repo %s, branch #%d: missing branch name", pkgRepo.URL, i+1)
+		}
+		name, err := branch.Name.Render(tmplCtx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("repo %s, branch #%d: template branch name: %w", pkgRepo.URL, i+1, err)
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, nil, fmt.Errorf("repo %s, branch #%d: branch name rendered empty", pkgRepo.URL, i+1)
+		}
+		branchCtx := tmplCtx
+		branchCtx.BaseBranch = name
+		ok, reason, err := evaluateWhen(branch.When, branchCtx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("repo %s, branch %s: %w", pkgRepo.URL, name, err)
+		}
+		if !ok {
+			log.Info().Str("repo", pkgRepo.URL).Str("branch", name).Str("reason", reason).Msg("Skipping branch.")
+			skipped = append(skipped, Skipped{RepoURL: pkgRepo.URL, PatchIndex: -1, Reason: fmt.Sprintf("branch %s: %s", name, reason)})
+			continue
+		}
+		if slices.Contains(bases, name) {
+			continue
+		}
+		bases = append(bases, name)
+	}
+	return bases, skipped, nil
+}
+
+// unfilteredPatchIndex converts the index of a patch returned by
+// [filterRepoByWhen] back to its index in the configured list of patches,
+// using the patches that were skipped by the filter.
+func unfilteredPatchIndex(index int, whenSkipped []Skipped) int {
+	for _, s := range whenSkipped {
+		if s.PatchIndex >= 0 && s.PatchIndex <= index {
+			index++
+		}
+	}
+	return index
+}
+
+// evaluateWhen renders a `when` condition. A nil condition always passes.
+// On false, the returned string is the reason for skipping.
+func evaluateWhen(when *config.Template, tmplCtx config.TemplateContext) (bool, string, error) {
+	if when == nil {
+		return true, "", nil
+	}
+	out, err := when.Render(tmplCtx)
+	if err != nil {
+		return false, "", fmt.Errorf("template when condition: %w", err)
+	}
+	out = strings.TrimSpace(out)
+	if out != "true" {
+		return false, fmt.Sprintf("when condition %q rendered %q", when.String(), out), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package patch
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+)
+
+func TestFilterRepoByWhen(t *testing.T) {
+	majorOnly := mustTemplate(t, `{{ regexMatch "^v?[0-9]+\\.0\\.0$" .Version }}`)
+	noPrerelease := mustTemplate(t, `{{ not (regexMatch "-" .Version) }}`)
+
+	pkgRepo := config.PackageRepo{
+		URL:  "https://github.com/RiskIdent/jelease",
+		When: noPrerelease,
+		Patches: []config.PackageRepoPatch{
+			{Regex: &config.PatchRegex{File: "always.txt"}},
+			{Regex: &config.PatchRegex{File: "major.txt"}, When: majorOnly},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		wantOK      bool
+		wantFiles   []string
+		wantSkipped []int
+	}{
+		{
+			name:      "major",
+			version:   "v2.0.0",
+			wantOK:    true,
+			wantFiles: []string{"always.txt", "major.txt"},
+		},
+		{
+			name:        "patch",
+			version:     "v2.0.1",
+			wantOK:      true,
+			wantFiles:   []string{"always.txt"},
+			wantSkipped: []int{1},
+		},
+		{
+			name:        "prerelease",
+			version:     "v2.0.0-rc.1",
+			wantOK:      false,
+			wantSkipped: []int{-1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, skipped, ok, err := filterRepoByWhen(pkgRepo, config.TemplateContext{Version: tc.version})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.wantOK {
+				t.Fatalf("want ok=%t, got %t", tc.wantOK, ok)
+			}
+			if len(skipped) != len(tc.wantSkipped) {
+				t.Fatalf("want %d skipped, got %d: %v", len(tc.wantSkipped), len(skipped), skipped)
+			}
+			for i, s := range skipped {
+				if s.PatchIndex != tc.wantSkipped[i] {
+					t.Errorf("skipped[%d]: want patch index %d, got %d", i, tc.wantSkipped[i], s.PatchIndex)
+				}
+			}
+			if !ok {
+				return
+			}
+			var files []string
+			for _, p := range got.Patches {
+				files = append(files, p.Regex.File)
+			}
+			if len(files) != len(tc.wantFiles) {
+				t.Fatalf("want patches %v, got %v", tc.wantFiles, files)
+			}
+			for i := range files {
+				if files[i] != tc.wantFiles[i] {
+					t.Errorf("want patches %v, got %v", tc.wantFiles, files)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterRepoByWhen_templateError(t *testing.T) {
+	pkgRepo := config.PackageRepo{
+		URL:  "https://github.com/RiskIdent/jelease",
+		When: mustTemplate(t, `{{ index .Version 100 }}`),
+	}
+	if _, _, _, err := filterRepoByWhen(pkgRepo, config.TemplateContext{}); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+func TestFilterBaseBranches(t *testing.T) {
+	pkgRepo := config.PackageRepo{
+		URL: "https://github.com/RiskIdent/jelease",
+		Branches: []config.PackageRepoBranch{
+			{Name: mustTemplate(t, `release/{{ regexReplaceAll "^v?([0-9]+)\\..*" "${1}" .Version }}.x`)},
+			{Name: mustTemplate(t, "main"), When: mustTemplate(t, `{{ not (regexMatch "-" .Version) }}`)},
+			{Name: mustTemplate(t, `{{ "main" }}`)},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		version     string
+		wantBases   []string
+		wantSkipped int
+	}{
+		{
+			name:      "release",
+			version:   "v2.1.0",
+			wantBases: []string{"release/2.x", "main"},
+		},
+		{
+			name:        "prerelease",
+			version:     "v2.1.0-rc.1",
+			wantBases:   []string{"release/2.x", "main"},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bases, skipped, err := filterBaseBranches(pkgRepo, config.TemplateContext{Version: tc.version})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(bases, tc.wantBases) {
+				t.Errorf("want bases %q, got %q", tc.wantBases, bases)
+			}
+			if len(skipped) != tc.wantSkipped {
+				t.Errorf("want %d skipped, got %d: %v", tc.wantSkipped, len(skipped), skipped)
+			}
+		})
+	}
+}
+
+func TestFilterBaseBranches_default(t *testing.T) {
+	pkgRepo := config.PackageRepo{URL: "https://github.com/RiskIdent/jelease"}
+	bases, _, err := filterBaseBranches(pkgRepo, config.TemplateContext{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(bases, []string{""}) {
+		t.Errorf("want only the default branch, got %q", bases)
+	}
+}
+
+func TestPrBranchesCollide(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		bases  []string
+		want   bool
+	}{
+		{
+			name:   "single base",
+			branch: "jelease/{{ .Package }}/{{ .Version }}",
+			bases:  []string{"main"},
+			want:   false,
+		},
+		{
+			name:   "same name for all bases",
+			branch: "jelease/{{ .Package }}/{{ .Version }}",
+			bases:  []string{"release/1.x", "release/2.x"},
+			want:   true,
+		},
+		{
+			name:   "name uses base",
+			branch: "jelease/{{ .Package }}/{{ .Version }}/{{ .BaseBranch }}",
+			bases:  []string{"release/1.x", "release/2.x"},
+			want:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.GitHub.PR.Branch = mustTemplate(t, tc.branch)
+			p := Patcher{cfg: cfg}
+			got, err := p.prBranchesCollide(tc.bases, config.TemplateContext{Package: "pkg", Version: "v1.0.0"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %t, got %t", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestUnfilteredPatchIndex(t *testing.T) {
+	// Patches #0 and #2 out of 5 were skipped, so the filtered
+	// list [1, 3, 4] is left.
+	whenSkipped := []Skipped{{PatchIndex: 0}, {PatchIndex: 2}}
+	for filtered, want := range []int{1, 3, 4} {
+		if got := unfilteredPatchIndex(filtered, whenSkipped); got != want {
+			t.Errorf("index %d: want %d, got %d", filtered, want, got)
+		}
+	}
+}
+
+func mustTemplate(t *testing.T, text string) *config.Template {
+	tmpl, err := config.NewTemplate(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
@@ -25,7 +25,6 @@ import (
 	"text/template"
 
 	"github.com/RiskIdent/jelease/pkg/config"
-	"github.com/RiskIdent/jelease/pkg/github"
 	"github.com/RiskIdent/jelease/pkg/patch"
 	"github.com/RiskIdent/jelease/templates/pages"
 	"github.com/gin-gonic/gin"
@@ -103,27 +102,25 @@ func (s HTTPServer) handlePostConfigTryPackage(c *gin.Context) {
 	cfgClone.DryRun = true
 	patcherClone := s.patcher.CloneWithConfig(&cfgClone)
 
-	prs, err := tryPackageConfig(model, patcherClone)
+	result, err := tryPackageConfig(model, patcherClone)
 	if err != nil {
 		log.Error().Err(err).Str("project", model.Package.Name).Msg("Failed creating patches.")
 	}
 
-	model.PullRequests = prs
+	model.PullRequests = result.PullRequests
+	model.Skipped = result.Skipped
+	model.UpToDate = result.UpToDate
 	model.Error = err
 	c.HTML(http.StatusOK, "", pages.ConfigTryPackage(model))
 }
 
-func tryPackageConfig(model pages.ConfigTryPackageModel, patcher patch.Patcher) ([]github.PullRequest, error) {
+func tryPackageConfig(model pages.ConfigTryPackageModel, patcher patch.Patcher) (patch.Result, error) {
 	tmplCtx, err := setTemplateContextPackageDescription(config.TemplateContext{
 		Package: model.Package.Name,
 		Version: model.Version,
 	}, model.Package.Description)
 	if err != nil {
-		return nil, err
+		return patch.Result{}, err
 	}
-	prs, err := patcher.CloneAndPublishAll(model.Package.Repos, tmplCtx)
-	if err != nil {
-		return nil, err
-	}
-	return prs, nil
+	return patcher.CloneAndPublishAll(model.Package, tmplCtx)
 }
@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/jira"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPRChecksPollInterval    = time.Minute
+	defaultPRChecksDeadline        = 2 * time.Hour
+	defaultPRChecksNoChecksTimeout = 10 * time.Minute
+)
+
+type TemplateContextPRChecks struct {
+	config.TemplateContext
+	PullRequest forge.PullRequest
+	Checks      forge.CommitChecks
+}
+
+// watchPullRequestChecks starts watching the CI checks of each pull request
+// in the background if enabled, and comments on the Jira issue once they
+// have passed or failed, or if no checks were reported. The watching stops
+// when the context is done, such as when the server shuts down.
+func watchPullRequestChecks(ctx context.Context, j jira.Client, gh forge.Client, issueRef jira.IssueRef, prs []forge.PullRequest, cfg *config.Config, tmplCtx config.TemplateContext) {
+	if !cfg.Jira.Issue.PRChecks.Enabled || cfg.DryRun {
+		return
+	}
+	for _, pr := range prs {
+		go watchPullRequestChecksUntilDeadline(ctx, j, gh, issueRef, pr, cfg.Jira.Issue, tmplCtx)
+	}
+}
+
+func watchPullRequestChecksUntilDeadline(ctx context.Context, j jira.Client, gh forge.Client, issueRef jira.IssueRef, pr forge.PullRequest, issueCfg config.JiraIssue, tmplCtx config.TemplateContext) {
+	interval := cmp.Or(issueCfg.PRChecks.PollInterval.Duration(), defaultPRChecksPollInterval)
+	deadline := cmp.Or(issueCfg.PRChecks.Deadline.Duration(), defaultPRChecksDeadline)
+	noChecksTimeout := cmp.Or(issueCfg.PRChecks.NoChecksTimeout.Duration(), defaultPRChecksNoChecksTimeout)
+	deadlineCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+
+	log.Debug().Str("url", pr.URL).Dur("deadline", deadline).
+		Msg("Watching CI checks of PR.")
+	checks, err := forge.WaitForChecks(deadlineCtx, gh, pr, interval, noChecksTimeout)
+	switch {
+	case errors.Is(err, forge.ErrNoChecks):
+		log.Info().Str("url", pr.URL).Dur("noChecksTimeout", noChecksTimeout).
+			Msg("Stopped watching CI checks of PR, as none were reported.")
+		createTemplatedComment(j, issueRef, issueCfg.Comments.PRChecksNone, TemplateContextPRChecks{
+			TemplateContext: tmplCtx,
+			PullRequest:     pr,
+			Checks:          checks,
+		})
+		return
+	case ctx.Err() != nil:
+		log.Info().Str("url", pr.URL).
+			Msg("Stopped watching CI checks of PR, as the server is shutting down.")
+		return
+	case err != nil:
+		log.Info().Err(err).Str("url", pr.URL).Dur("deadline", deadline).
+			Msg("Stopped watching CI checks of PR, as they didn't complete before the deadline.")
+		return
+	}
+	log.Info().Str("url", pr.URL).Str("state", string(checks.State)).
+		Int("failed", len(checks.Failed())).
+		Msg("CI checks of PR completed.")
+
+	tmpl := issueCfg.Comments.PRChecksPassed
+	if checks.State == forge.CheckStateFailure {
+		tmpl = issueCfg.Comments.PRChecksFailed
+	}
+	createTemplatedComment(j, issueRef, tmpl, TemplateContextPRChecks{
+		TemplateContext: tmplCtx,
+		PullRequest:     pr,
+		Checks:          checks,
+	})
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 Risk.Ident GmbH <contact@riskident.com>
+//
+// SPDX-License-Identifier: GPL-3.0-or-later
+//
+// This program is free software: you can redistribute it and/or modify it
+// under the terms of the GNU General Public License as published by the
+// Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful, but WITHOUT
+// ANY WARRANTY; without even the implied warranty of MERCHANTABILITY or
+// FITNESS FOR A PARTICULAR PURPOSE.  See the GNU General Public License for
+// more details.
+//
+// You should have received a copy of the GNU General Public License along
+// with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RiskIdent/jelease/pkg/config"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/jira"
+)
+
+type fakeJira struct {
+	jira.Client
+	comments []string
+}
+
+func (j *fakeJira) CreateIssueComment(_ jira.IssueRef, comment string) error {
+	j.comments = append(j.comments, comment)
+	return nil
+}
+
+type fakeForge struct {
+	forge.Client
+	checks forge.CommitChecks
+}
+
+func (c *fakeForge) GetCommitChecks(context.Context, forge.RepoRef, string) (forge.CommitChecks, error) {
+	return c.checks, nil
+}
+
+func TestWatchPullRequestChecksUntilDeadline(t *testing.T) {
+	issueCfg := config.JiraIssue{
+		PRChecks: config.JiraIssuePRChecks{
+			Enabled:         true,
+			PollInterval:    config.Duration(time.Millisecond),
+			Deadline:        config.Duration(50 * time.Millisecond),
+			NoChecksTimeout: config.Duration(10 * time.Millisecond),
+		},
+		Comments: config.JiraIssueComments{
+			PRChecksPassed: config.MustTemplate("Passed {{ .PullRequest.URL }}"),
+			PRChecksFailed: config.MustTemplate("Failed {{ .PullRequest.URL }}:{{ range .Checks.Failed }} [{{ .Name }}|{{ .URL }}]{{ end }}"),
+			PRChecksNone:   config.MustTemplate("None {{ .PullRequest.URL }}"),
+		},
+	}
+	pr := forge.PullRequest{URL: "https://github.com/RiskIdent/jelease/pull/2", HeadSHA: "abc123"}
+
+	tests := []struct {
+		name   string
+		checks forge.CommitChecks
+		want   []string
+	}{
+		{
+			name:   "passed",
+			checks: forge.CommitChecks{State: forge.CheckStateSuccess},
+			want:   []string{"Passed https://github.com/RiskIdent/jelease/pull/2"},
+		},
+		{
+			name: "failed",
+			checks: forge.CommitChecks{
+				State: forge.CheckStateFailure,
+				Checks: []forge.Check{
+					{Name: "build", URL: "https://example.com/build", State: forge.CheckStateSuccess},
+					{Name: "test", URL: "https://example.com/test", State: forge.CheckStateFailure},
+				},
+			},
+			want: []string{"Failed https://github.com/RiskIdent/jelease/pull/2: [test|https://example.com/test]"},
+		},
+		{
+			name: "deadline",
+			checks: forge.CommitChecks{
+				State:  forge.CheckStatePending,
+				Checks: []forge.Check{{Name: "build", State: forge.CheckStatePending}},
+			},
+		},
+		{
+			name:   "no checks",
+			checks: forge.CommitChecks{State: forge.CheckStatePending},
+			want:   []string{"None https://github.com/RiskIdent/jelease/pull/2"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			j := &fakeJira{}
+			fake := &fakeForge{checks: tc.checks}
+
+			watchPullRequestChecksUntilDeadline(context.Background(), j, fake, jira.IssueRef{Key: "OP-1234"}, pr, issueCfg, config.TemplateContext{})
+
+			if len(j.comments) != len(tc.want) {
+				t.Fatalf("want comments %q, got %q", tc.want, j.comments)
+			}
+			for i, want := range tc.want {
+				if j.comments[i] != want {
+					t.Errorf("comment #%d: want %q, got %q", i, want, j.comments[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWatchPullRequestChecksUntilDeadline_canceled(t *testing.T) {
+	issueCfg := config.JiraIssue{
+		PRChecks: config.JiraIssuePRChecks{
+			Enabled:      true,
+			PollInterval: config.Duration(time.Millisecond),
+		},
+		Comments: config.JiraIssueComments{
+			PRChecksNone: config.MustTemplate("None"),
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	j := &fakeJira{}
+	fake := &fakeForge{checks: forge.CommitChecks{State: forge.CheckStatePending}}
+
+	done := make(chan struct{})
+	go func() {
+		watchPullRequestChecksUntilDeadline(ctx, j, fake, jira.IssueRef{Key: "OP-1234"}, forge.PullRequest{}, issueCfg, config.TemplateContext{})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("want watching to stop when the context is canceled")
+	}
+	if len(j.comments) != 0 {
+		t.Errorf("want no comments, got %q", j.comments)
+	}
+}
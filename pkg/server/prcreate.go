@@ -108,16 +108,19 @@ func (s HTTPServer) handlePostPRCreate(c *gin.Context) {
 		c.HTML(http.StatusOK, "", pages.PackagesCreatePR(model))
 		return
 	}
-	prs, err := patcherClone.CloneAndPublishAll(model.Package.Repos, tmplCtx)
+	result, err := patcherClone.CloneAndPublishAll(model.Package, tmplCtx)
 	if err != nil {
 		log.Error().Err(err).Str("project", model.Package.Name).Msg("Failed creating patches.")
 	}
 
 	if model.JiraIssue != "" && !model.DryRun && err == nil {
-		createDynamicComment(s.jira, issueRef, prs, model.Package.Name, &s.cfg.Jira.Issue.Comments, tmplCtx)
+		createDynamicComment(s.jira, issueRef, result, model.Package.Name, &s.cfg.Jira.Issue.Comments, tmplCtx)
+		watchPullRequestChecks(s.ctx, s.jira, s.patcher.Forge(), issueRef, result.PullRequests, &cfgClone, tmplCtx)
 	}
 
-	model.PullRequests = prs
+	model.PullRequests = result.PullRequests
+	model.Skipped = result.Skipped
+	model.UpToDate = result.UpToDate
 	model.Error = err
 	c.HTML(http.StatusOK, "", pages.PackagesCreatePR(model))
 }
@@ -18,14 +18,17 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/RiskIdent/jelease/pkg/config"
-	"github.com/RiskIdent/jelease/pkg/github"
+	"github.com/RiskIdent/jelease/pkg/forge"
 	"github.com/RiskIdent/jelease/pkg/jira"
 	"github.com/RiskIdent/jelease/pkg/patch"
 	"github.com/RiskIdent/jelease/templates/pages"
@@ -34,14 +37,21 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// shutdownTimeout is how long to wait for ongoing requests when shutting down.
+const shutdownTimeout = 10 * time.Second
+
 type HTTPServer struct {
+	// ctx is done when the server shuts down, which also stops the
+	// background work started by the requests.
+	ctx     context.Context
 	engine  *gin.Engine
 	cfg     *config.Config
 	jira    jira.Client
 	patcher patch.Patcher
 }
 
-func New(cfg *config.Config, j jira.Client, patcher patch.Patcher, staticFiles fs.FS) *HTTPServer {
+// New creates a HTTP server, that shuts down when the context is done.
+func New(ctx context.Context, cfg *config.Config, j jira.Client, patcher patch.Patcher, staticFiles fs.FS) *HTTPServer {
 	gin.DefaultErrorWriter = ginLogger{defaultLevel: zerolog.ErrorLevel}
 	gin.DefaultWriter = ginLogger{defaultLevel: zerolog.InfoLevel}
 
@@ -56,6 +66,7 @@ func New(cfg *config.Config, j jira.Client, patcher patch.Patcher, staticFiles f
 	)
 
 	s := &HTTPServer{
+		ctx:     ctx,
 		engine:  r,
 		cfg:     cfg,
 		jira:    j,
@@ -138,9 +149,27 @@ func New(cfg *config.Config, j jira.Client, patcher patch.Patcher, staticFiles f
 	return s
 }
 
+// Serve listens for HTTP requests until the context passed to [New] is
+// done, and then gracefully shuts down the server.
 func (s HTTPServer) Serve() error {
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%v", s.cfg.HTTP.Port),
+		Handler: s.engine,
+	}
+	go func() {
+		<-s.ctx.Done()
+		log.Info().Msg("Shutting down server.")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to gracefully shut down server.")
+		}
+	}()
 	log.Info().Uint16("port", s.cfg.HTTP.Port).Msg("Starting server.")
-	return s.engine.Run(fmt.Sprintf(":%v", s.cfg.HTTP.Port))
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 // handlePostWebhook handles newreleases.io webhook post requests
@@ -158,13 +187,13 @@ func (s HTTPServer) handlePostWebhook(c *gin.Context) {
 		return
 	}
 
-	go tryApplyChanges(s.jira, s.patcher, release, issueRef.IssueRef, s.cfg)
+	go tryApplyChanges(s.ctx, s.jira, s.patcher, release, issueRef.IssueRef, s.cfg)
 
 	// NOTE: always return OK, otherwise newreleases.io will retry
 	c.Status(http.StatusOK)
 }
 
-func tryApplyChanges(j jira.Client, patcher patch.Patcher, release Release, issueRef jira.IssueRef, cfg *config.Config) {
+func tryApplyChanges(ctx context.Context, j jira.Client, patcher patch.Patcher, release Release, issueRef jira.IssueRef, cfg *config.Config) {
 	pkg, ok := cfg.TryFindPackage(release.Project)
 	if !ok {
 		log.Info().Str("project", release.Project).Msg("No package patching config was found. Skipping patching.")
@@ -208,7 +237,7 @@ func tryApplyChanges(j jira.Client, patcher patch.Patcher, release Release, issu
 		}
 	}
 
-	prs, err := patcher.CloneAndPublishAll(pkg.Repos, tmplCtx)
+	result, err := patcher.CloneAndPublishAll(pkg, tmplCtx)
 	if err != nil {
 		log.Error().Err(err).Str("project", release.Project).Msg("Failed creating patches.")
 		createTemplatedComment(j, issueRef, cfg.Jira.Issue.Comments.PRFailed, TemplateContextError{
@@ -217,30 +246,52 @@ func tryApplyChanges(j jira.Client, patcher patch.Patcher, release Release, issu
 		})
 		return
 	}
-	createDynamicComment(j, issueRef, prs, release.Project, &cfg.Jira.Issue.Comments, tmplCtx)
+	createDynamicComment(j, issueRef, result, release.Project, &cfg.Jira.Issue.Comments, tmplCtx)
+	watchPullRequestChecks(ctx, j, patcher.Forge(), issueRef, result.PullRequests, cfg, tmplCtx)
 }
 
 func createDynamicComment(
 	j jira.Client,
 	issueRef jira.IssueRef,
-	prs []github.PullRequest,
+	result patch.Result,
 	pkgName string,
 	commentTemplates *config.JiraIssueComments,
 	tmplCtx config.TemplateContext,
 ) {
-	if len(prs) == 0 {
-		log.Warn().Str("project", pkgName).Msg("Found package config, but no repositories were patched.")
-		createTemplatedComment(j, issueRef, commentTemplates.NoPatches, tmplCtx)
+	if len(result.PullRequests) == 0 && len(result.UpToDate) > 0 {
+		log.Info().
+			Str("project", pkgName).
+			Strs("repos", result.UpToDate).
+			Msg("Found package config, but all repositories were already up to date.")
+		createTemplatedComment(j, issueRef, commentTemplates.AlreadyUpToDate, TemplateContextPullRequests{
+			TemplateContext: tmplCtx,
+			Skipped:         result.Skipped,
+			UpToDate:        result.UpToDate,
+		})
+		return
+	}
+	if len(result.PullRequests) == 0 {
+		log.Warn().
+			Str("project", pkgName).
+			Int("skipped", len(result.Skipped)).
+			Msg("Found package config, but no repositories were patched.")
+		createTemplatedComment(j, issueRef, commentTemplates.NoPatches, TemplateContextPullRequests{
+			TemplateContext: tmplCtx,
+			Skipped:         result.Skipped,
+		})
 		return
 	}
 	log.Info().
 		Str("project", pkgName).
-		Int("count", len(prs)).
+		Int("count", len(result.PullRequests)).
+		Int("skipped", len(result.Skipped)).
 		Msg("Successfully created PRs for update.")
 
 	createTemplatedComment(j, issueRef, commentTemplates.PRCreated, TemplateContextPullRequests{
 		TemplateContext: tmplCtx,
-		PullRequests:    prs,
+		PullRequests:    result.PullRequests,
+		Skipped:         result.Skipped,
+		UpToDate:        result.UpToDate,
 	})
 }
 
@@ -263,7 +314,9 @@ type TemplateContextError struct {
 
 type TemplateContextPullRequests struct {
 	config.TemplateContext
-	PullRequests []github.PullRequest
+	PullRequests []forge.PullRequest
+	Skipped      []patch.Skipped
+	UpToDate     []string
 }
 
 type TemplateContextURL struct {
@@ -41,6 +41,7 @@ var camelCaseReplacer = strings.NewReplacer(
 	"JQ", "Jq",
 	"YAML", "Yaml",
 	"YQ", "Yq",
+	"HCL", "Hcl",
 	"GitHub", "Github",
 	"PR", "Pr",
 	"API", "Api",
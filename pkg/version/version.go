@@ -18,6 +18,7 @@
 package version
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"regexp"
@@ -100,3 +101,58 @@ func indexOrZero(slice []uint, index int) uint {
 	}
 	return slice[index]
 }
+
+// Compare returns -1 if v is lower than other, 0 if they are equal,
+// and +1 if v is higher than other.
+//
+// Missing segments are treated as zeros, so "1.2" equals "1.2.0".
+// The prefix and any build metadata (suffix after "+") are ignored.
+// A version with a pre-release suffix (such as "-rc.1") is lower than
+// the same version without it, as in semantic versioning.
+func (v Version) Compare(other Version) int {
+	numSegments := max(len(v.Segments), len(other.Segments))
+	for i := 0; i < numSegments; i++ {
+		if c := cmp.Compare(indexOrZero(v.Segments, i), indexOrZero(other.Segments, i)); c != 0 {
+			return c
+		}
+	}
+	return comparePrerelease(prerelease(v.Suffix), prerelease(other.Suffix))
+}
+
+func prerelease(suffix string) string {
+	suffix, _, _ = strings.Cut(suffix, "+")
+	return strings.TrimLeft(suffix, "-.")
+}
+
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < min(len(aIDs), len(bIDs)); i++ {
+		aNum, aErr := strconv.ParseUint(aIDs[i], 10, 0)
+		bNum, bErr := strconv.ParseUint(bIDs[i], 10, 0)
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(aNum, bNum)
+		case aErr == nil:
+			// Numeric identifiers are lower than alphanumeric ones
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(aIDs[i], bIDs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(aIDs), len(bIDs))
+}
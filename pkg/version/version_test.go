@@ -194,3 +194,92 @@ func TestBump(t *testing.T) {
 		})
 	}
 }
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{
+			name: "equal",
+			a:    "1.2.3",
+			b:    "1.2.3",
+			want: 0,
+		},
+		{
+			name: "lower patch",
+			a:    "1.8.5",
+			b:    "1.9.0",
+			want: -1,
+		},
+		{
+			name: "higher major",
+			a:    "2.0.0",
+			b:    "1.9.9",
+			want: 1,
+		},
+		{
+			name: "numeric not lexical",
+			a:    "1.10.0",
+			b:    "1.9.0",
+			want: 1,
+		},
+		{
+			name: "missing segments are zero",
+			a:    "1.2",
+			b:    "1.2.0",
+			want: 0,
+		},
+		{
+			name: "ignores prefix",
+			a:    "v1.2.3",
+			b:    "1.2.3",
+			want: 0,
+		},
+		{
+			name: "pre-release is lower",
+			a:    "1.2.3-rc.1",
+			b:    "1.2.3",
+			want: -1,
+		},
+		{
+			name: "pre-release numeric identifiers",
+			a:    "1.2.3-rc.10",
+			b:    "1.2.3-rc.9",
+			want: 1,
+		},
+		{
+			name: "pre-release more identifiers is higher",
+			a:    "1.2.3-alpha.1",
+			b:    "1.2.3-alpha",
+			want: 1,
+		},
+		{
+			name: "ignores build metadata",
+			a:    "1.2.3+abc",
+			b:    "1.2.3+def",
+			want: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			aVer, err := Parse(tc.a)
+			if err != nil {
+				t.Fatalf("parse %q: %s", tc.a, err)
+			}
+			bVer, err := Parse(tc.b)
+			if err != nil {
+				t.Fatalf("parse %q: %s", tc.b, err)
+			}
+			if got := aVer.Compare(bVer); got != tc.want {
+				t.Errorf("want %d, got %d", tc.want, got)
+			}
+			if got := bVer.Compare(aVer); got != -tc.want {
+				t.Errorf("reversed: want %d, got %d", -tc.want, got)
+			}
+		})
+	}
+}
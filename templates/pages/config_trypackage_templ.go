@@ -41,7 +41,8 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import (
 	"github.com/RiskIdent/jelease/pkg/config"
-	"github.com/RiskIdent/jelease/pkg/github"
+	"github.com/RiskIdent/jelease/pkg/forge"
+	"github.com/RiskIdent/jelease/pkg/patch"
 	"github.com/RiskIdent/jelease/templates/components"
 )
 
@@ -52,7 +53,9 @@ type ConfigTryPackageModel struct {
 	Version       string
 	IsPost        bool
 	Error         error
-	PullRequests  []github.PullRequest
+	PullRequests  []forge.PullRequest
+	Skipped       []patch.Skipped
+	UpToDate      []string
 }
 
 func ConfigTryPackage(model ConfigTryPackageModel) templ.Component {
@@ -135,7 +138,7 @@ func ConfigTryPackage(model ConfigTryPackageModel) templ.Component {
 			var templ_7745c5c3_Var4 string
 			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(model.Version)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/pages/config_trypackage.templ`, Line: 54, Col: 119}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/pages/config_trypackage.templ`, Line: 57, Col: 119}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 			if templ_7745c5c3_Err != nil {
@@ -170,7 +173,7 @@ func ConfigTryPackage(model ConfigTryPackageModel) templ.Component {
 			var templ_7745c5c3_Var7 string
 			templ_7745c5c3_Var7, templ_7745c5c3_Err = templ.JoinStringErrs(model.PackageConfig)
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/pages/config_trypackage.templ`, Line: 60, Col: 114}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/pages/config_trypackage.templ`, Line: 63, Col: 114}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var7))
 			if templ_7745c5c3_Err != nil {
@@ -185,6 +188,8 @@ func ConfigTryPackage(model ConfigTryPackageModel) templ.Component {
 				DryRun:       true,
 				Error:        model.Error,
 				PullRequests: model.PullRequests,
+				Skipped:      model.Skipped,
+				UpToDate:     model.UpToDate,
 			}).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err